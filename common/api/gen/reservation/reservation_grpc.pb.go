@@ -22,6 +22,8 @@ const (
 	Reservation_CreateReservation_FullMethodName = "/reservation.Reservation/CreateReservation"
 	Reservation_CancelReservation_FullMethodName = "/reservation.Reservation/CancelReservation"
 	Reservation_CloseReservation_FullMethodName  = "/reservation.Reservation/CloseReservation"
+	Reservation_GetReservation_FullMethodName    = "/reservation.Reservation/GetReservation"
+	Reservation_ListReservations_FullMethodName  = "/reservation.Reservation/ListReservations"
 )
 
 // ReservationClient is the client API for Reservation service.
@@ -31,6 +33,8 @@ type ReservationClient interface {
 	CreateReservation(ctx context.Context, in *CreateReservationRequest, opts ...grpc.CallOption) (*CreateReservationResponse, error)
 	CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*CancelReservationResponse, error)
 	CloseReservation(ctx context.Context, in *CloseReservationRequest, opts ...grpc.CallOption) (*CloseReservationResponse, error)
+	GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*ReservationInfo, error)
+	ListReservations(ctx context.Context, in *ListReservationsRequest, opts ...grpc.CallOption) (*ListReservationsResponse, error)
 }
 
 type reservationClient struct {
@@ -71,6 +75,26 @@ func (c *reservationClient) CloseReservation(ctx context.Context, in *CloseReser
 	return out, nil
 }
 
+func (c *reservationClient) GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*ReservationInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationInfo)
+	err := c.cc.Invoke(ctx, Reservation_GetReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationClient) ListReservations(ctx context.Context, in *ListReservationsRequest, opts ...grpc.CallOption) (*ListReservationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReservationsResponse)
+	err := c.cc.Invoke(ctx, Reservation_ListReservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReservationServer is the server API for Reservation service.
 // All implementations must embed UnimplementedReservationServer
 // for forward compatibility.
@@ -78,6 +102,8 @@ type ReservationServer interface {
 	CreateReservation(context.Context, *CreateReservationRequest) (*CreateReservationResponse, error)
 	CancelReservation(context.Context, *CancelReservationRequest) (*CancelReservationResponse, error)
 	CloseReservation(context.Context, *CloseReservationRequest) (*CloseReservationResponse, error)
+	GetReservation(context.Context, *GetReservationRequest) (*ReservationInfo, error)
+	ListReservations(context.Context, *ListReservationsRequest) (*ListReservationsResponse, error)
 	mustEmbedUnimplementedReservationServer()
 }
 
@@ -97,6 +123,12 @@ func (UnimplementedReservationServer) CancelReservation(context.Context, *Cancel
 func (UnimplementedReservationServer) CloseReservation(context.Context, *CloseReservationRequest) (*CloseReservationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CloseReservation not implemented")
 }
+func (UnimplementedReservationServer) GetReservation(context.Context, *GetReservationRequest) (*ReservationInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservation not implemented")
+}
+func (UnimplementedReservationServer) ListReservations(context.Context, *ListReservationsRequest) (*ListReservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReservations not implemented")
+}
 func (UnimplementedReservationServer) mustEmbedUnimplementedReservationServer() {}
 func (UnimplementedReservationServer) testEmbeddedByValue()                     {}
 
@@ -172,6 +204,42 @@ func _Reservation_CloseReservation_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Reservation_GetReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServer).GetReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reservation_GetReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServer).GetReservation(ctx, req.(*GetReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Reservation_ListReservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServer).ListReservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Reservation_ListReservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServer).ListReservations(ctx, req.(*ListReservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Reservation_ServiceDesc is the grpc.ServiceDesc for Reservation service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +259,14 @@ var Reservation_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CloseReservation",
 			Handler:    _Reservation_CloseReservation_Handler,
 		},
+		{
+			MethodName: "GetReservation",
+			Handler:    _Reservation_GetReservation_Handler,
+		},
+		{
+			MethodName: "ListReservations",
+			Handler:    _Reservation_ListReservations_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "reservation.proto",
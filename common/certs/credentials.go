@@ -0,0 +1,89 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ServerCredentials returns the transport credentials for a gRPC server:
+// plain TCP when TLS is disabled, TLS otherwise, requiring a client
+// certificate signed by cfg.CAFile when cfg.Mutual is set. The key pair and
+// client CAs are reloaded from disk until ctx is done.
+func ServerCredentials(ctx context.Context, log *slog.Logger, cfg config.TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls: cert_file and key_file are required")
+	}
+	if cfg.Mutual && cfg.CAFile == "" {
+		return nil, errors.New("tls: ca_file is required for mutual TLS")
+	}
+
+	r, err := newReloader(log, cfg.CertFile, cfg.KeyFile, cfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ReloadInterval > 0 {
+		go r.watch(ctx, cfg.ReloadInterval)
+	}
+
+	clientAuth := tls.NoClientCert
+	if cfg.CAFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+	if cfg.Mutual {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2"},
+				Certificates: []tls.Certificate{*r.certificate()},
+				ClientAuth:   clientAuth,
+				ClientCAs:    r.pool.Load(),
+			}, nil
+		},
+	}), nil
+}
+
+// ClientCredentials returns the transport credentials for dialing a gRPC
+// server. Without cfg.CAFile the system roots are trusted. The client key
+// pair is reloaded from disk until ctx is done; the CA bundle is read once.
+func ClientCredentials(ctx context.Context, log *slog.Logger, cfg config.TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("tls: cert_file and key_file must be set together")
+	}
+	if cfg.Mutual && cfg.CertFile == "" {
+		return nil, errors.New("tls: cert_file and key_file are required for mutual TLS")
+	}
+
+	r, err := newReloader(log, cfg.CertFile, cfg.KeyFile, cfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ReloadInterval > 0 && cfg.CertFile != "" {
+		go r.watch(ctx, cfg.ReloadInterval)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    r.pool.Load(),
+		ServerName: cfg.ServerName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate(), nil
+		},
+	}), nil
+}
@@ -0,0 +1,115 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// reloader keeps the key pair and CA bundle of a TLSConfig in memory and
+// swaps them when any of the files changes on disk, so rotated certificates
+// are picked up by new handshakes without a restart.
+type reloader struct {
+	log      *slog.Logger
+	certFile string
+	keyFile  string
+	caFile   string
+	cert     atomic.Pointer[tls.Certificate]
+	pool     atomic.Pointer[x509.CertPool]
+	modTime  time.Time
+}
+
+func newReloader(log *slog.Logger, certFile, keyFile, caFile string) (*reloader, error) {
+	r := &reloader{log: log, certFile: certFile, keyFile: keyFile, caFile: caFile}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.modTime = modTime
+	return r, nil
+}
+
+func (r *reloader) load() error {
+	if r.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load key pair: %w", err)
+		}
+		r.cert.Store(&cert)
+	}
+
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("CA bundle contains no certificates")
+		}
+		r.pool.Store(pool)
+	}
+	return nil
+}
+
+func (r *reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// watch polls the files every interval until ctx is done. A failed reload
+// keeps serving the previous certificates.
+func (r *reloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.latestModTime()
+			if err != nil {
+				r.log.Error("failed to stat certificates", "error", err)
+				continue
+			}
+			if !modTime.After(r.modTime) {
+				continue
+			}
+			if err := r.load(); err != nil {
+				r.log.Error("failed to reload certificates", "error", err)
+				continue
+			}
+			r.modTime = modTime
+			r.log.Info("certificates reloaded", "cert", r.certFile)
+		}
+	}
+}
+
+func (r *reloader) certificate() *tls.Certificate {
+	if cert := r.cert.Load(); cert != nil {
+		return cert
+	}
+	return &tls.Certificate{}
+}
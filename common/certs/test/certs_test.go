@@ -0,0 +1,158 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/certs"
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func NewTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func serverConfig(dir string) config.TLSConfig {
+	return config.TLSConfig{
+		Enabled:        true,
+		CertFile:       filepath.Join(dir, certs.ServerCertFile),
+		KeyFile:        filepath.Join(dir, certs.ServerKeyFile),
+		CAFile:         filepath.Join(dir, certs.CAFile),
+		Mutual:         true,
+		ReloadInterval: 10 * time.Millisecond,
+	}
+}
+
+func clientConfig(dir string) config.TLSConfig {
+	return config.TLSConfig{
+		Enabled:  true,
+		CertFile: filepath.Join(dir, certs.ClientCertFile),
+		KeyFile:  filepath.Join(dir, certs.ClientKeyFile),
+		CAFile:   filepath.Join(dir, certs.CAFile),
+		Mutual:   true,
+	}
+}
+
+func startServer(t *testing.T, cfg config.TLSConfig) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	creds, err := certs.ServerCredentials(ctx, NewTestLogger(), cfg)
+	assert.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer(grpc.Creds(creds))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func check(t *testing.T, addr string, cfg config.TLSConfig) error {
+	creds, err := certs.ClientCredentials(context.Background(), NewTestLogger(), cfg)
+	assert.NoError(t, err)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, certs.GenerateDev(dir, nil))
+	addr := startServer(t, serverConfig(dir))
+
+	t.Run("client certificate", func(t *testing.T) {
+		assert.NoError(t, check(t, addr, clientConfig(dir)))
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		cfg := clientConfig(dir)
+		cfg.CertFile, cfg.KeyFile, cfg.Mutual = "", "", false
+
+		assert.Error(t, check(t, addr, cfg))
+	})
+
+	t.Run("untrusted server", func(t *testing.T) {
+		other := t.TempDir()
+		assert.NoError(t, certs.GenerateDev(other, nil))
+		cfg := clientConfig(dir)
+		cfg.CAFile = filepath.Join(other, certs.CAFile)
+
+		assert.Error(t, check(t, addr, cfg))
+	})
+}
+
+func TestServerCredentials_Reload(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, certs.GenerateDev(dir, nil))
+	addr := startServer(t, serverConfig(dir))
+	before := servedSerial(t, addr, dir)
+
+	rotated := t.TempDir()
+	assert.NoError(t, certs.GenerateDev(rotated, nil))
+	for _, file := range []string{certs.CAFile, certs.ServerCertFile, certs.ServerKeyFile, certs.ClientCertFile, certs.ClientKeyFile} {
+		data, err := os.ReadFile(filepath.Join(rotated, file))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, file), data, 0o600))
+		future := time.Now().Add(time.Minute)
+		assert.NoError(t, os.Chtimes(filepath.Join(dir, file), future, future))
+	}
+
+	assert.Eventually(t, func() bool {
+		return check(t, addr, clientConfig(dir)) == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.NotEqual(t, before, servedSerial(t, addr, dir))
+}
+
+func TestCredentials_Validation(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := certs.ServerCredentials(ctx, NewTestLogger(), config.TLSConfig{Enabled: true})
+	assert.Error(t, err)
+
+	_, err = certs.ServerCredentials(ctx, NewTestLogger(), config.TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", Mutual: true})
+	assert.Error(t, err)
+
+	_, err = certs.ClientCredentials(ctx, NewTestLogger(), config.TLSConfig{Enabled: true, Mutual: true})
+	assert.Error(t, err)
+
+	creds, err := certs.ServerCredentials(ctx, NewTestLogger(), config.TLSConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+}
+
+func servedSerial(t *testing.T, addr, dir string) string {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, certs.ClientCertFile), filepath.Join(dir, certs.ClientKeyFile))
+	assert.NoError(t, err)
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{cert},
+		NextProtos:         []string{"h2"},
+	})
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.String()
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SergeyBogomolovv/restaurant/common/certs"
+)
+
+func main() {
+	dir := flag.String("dir", "./tmp/certs", "directory to write certificates to")
+	hosts := flag.String("hosts", "", "comma separated extra server hostnames or IPs")
+	flag.Parse()
+
+	var extra []string
+	if *hosts != "" {
+		extra = strings.Split(*hosts, ",")
+	}
+	if err := certs.GenerateDev(*dir, extra); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("dev certificates in", *dir)
+}
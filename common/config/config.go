@@ -1,61 +1,169 @@
 package config
 
-import (
-	"flag"
-	"os"
-	"time"
+import "time"
 
-	"github.com/ilyakaznacheev/cleanenv"
-)
+// Every field can be overridden by the environment variable named after its
+// path, e.g. SSO_MAILER_SMTP_ADDR or POSTGRES_MAX_OPEN_CONNS. The only
+// exception is the list of OIDC providers, which is read from the file.
 
-type Config struct {
-	Env         string              `yaml:"env" env-required:"true"`
-	PostgresURL string              `yaml:"postgres_url" env-required:"true"`
-	RedisURL    string              `yaml:"redis_url" env-required:"true"`
-	SSO         SSOService          `yaml:"sso" env-required:"true"`
-	Reservation ReseravationService `yaml:"reservation" env-required:"true"`
-	Jwt         JwtConfig           `yaml:"jwt" env-required:"true"`
+type SSOConfig struct {
+	Env      string         `yaml:"env" env:"ENV" env-default:"local"`
+	Postgres PostgresConfig `yaml:"postgres" env-prefix:"POSTGRES_"`
+	Redis    RedisConfig    `yaml:"redis" env-prefix:"REDIS_"`
+	Jwt      JwtConfig      `yaml:"jwt" env-prefix:"JWT_"`
+	Tracing  TracingConfig  `yaml:"tracing" env-prefix:"TRACING_"`
+	SSO      SSOService     `yaml:"sso" env-prefix:"SSO_"`
+}
+
+type ReservationConfig struct {
+	Env         string             `yaml:"env" env:"ENV" env-default:"local"`
+	Postgres    PostgresConfig     `yaml:"postgres" env-prefix:"POSTGRES_"`
+	Tracing     TracingConfig      `yaml:"tracing" env-prefix:"TRACING_"`
+	Reservation ReservationService `yaml:"reservation" env-prefix:"RESERVATION_"`
+}
+
+type GatewayConfig struct {
+	Env     string         `yaml:"env" env:"ENV" env-default:"local"`
+	Tracing TracingConfig  `yaml:"tracing" env-prefix:"TRACING_"`
+	Gateway GatewayService `yaml:"gateway" env-prefix:"GATEWAY_"`
+}
+
+type PostgresConfig struct {
+	URL              string        `yaml:"url" env:"URL" env-required:"true"`
+	MaxOpenConns     int           `yaml:"max_open_conns" env:"MAX_OPEN_CONNS" env-default:"20"`
+	MaxIdleConns     int           `yaml:"max_idle_conns" env:"MAX_IDLE_CONNS" env-default:"10"`
+	ConnMaxLifetime  time.Duration `yaml:"conn_max_lifetime" env:"CONN_MAX_LIFETIME" env-default:"30m"`
+	ConnMaxIdleTime  time.Duration `yaml:"conn_max_idle_time" env:"CONN_MAX_IDLE_TIME" env-default:"5m"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"STATEMENT_TIMEOUT" env-default:"1m"`
+	Retry            RetryConfig   `yaml:"retry" env-prefix:"RETRY_"`
+}
+
+type RedisConfig struct {
+	URL   string      `yaml:"url" env:"URL" env-required:"true"`
+	Retry RetryConfig `yaml:"retry" env-prefix:"RETRY_"`
+}
+
+// RetryConfig bounds how long a service waits for a dependency at startup:
+// up to Attempts tries, the pause between them doubling from Backoff to at
+// most MaxBackoff.
+type RetryConfig struct {
+	Attempts   int           `yaml:"attempts" env:"ATTEMPTS" env-default:"10"`
+	Backoff    time.Duration `yaml:"backoff" env:"BACKOFF" env-default:"500ms"`
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"MAX_BACKOFF" env-default:"10s"`
+}
+
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled" env:"ENABLED"`
+	Endpoint    string  `yaml:"endpoint" env:"ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"INSECURE" env-default:"true"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"`
 }
 
 type JwtConfig struct {
-	Secret     string        `yaml:"secret" env-required:"true"`
-	RefreshTTL time.Duration `yaml:"refresh_ttl" env-required:"true"`
-	AccessTTL  time.Duration `yaml:"access_ttl" env-required:"true"`
+	Secret     string        `yaml:"secret" env:"SECRET" env-required:"true"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env:"REFRESH_TTL" env-required:"true"`
+	AccessTTL  time.Duration `yaml:"access_ttl" env:"ACCESS_TTL" env-required:"true"`
+	MFATTL     time.Duration `yaml:"mfa_ttl" env:"MFA_TTL" env-default:"5m"`
 }
 
 type SSOService struct {
-	Port      int           `yaml:"port" env-required:"true"`
-	Timeout   time.Duration `yaml:"timeout" env-required:"true"`
-	SecretKey string        `yaml:"secret_key" env-required:"true"`
+	Port             int                      `yaml:"port" env:"PORT" env-required:"true"`
+	MetricsPort      int                      `yaml:"metrics_port" env:"METRICS_PORT"`
+	Timeout          time.Duration            `yaml:"timeout" env:"TIMEOUT" env-required:"true"`
+	Timeouts         map[string]time.Duration `yaml:"timeouts" env:"TIMEOUTS"`
+	InvitationTTL    time.Duration            `yaml:"invitation_ttl" env:"INVITATION_TTL" env-default:"72h"`
+	MaxInvitationTTL time.Duration            `yaml:"max_invitation_ttl" env:"MAX_INVITATION_TTL" env-default:"720h"`
+	MFAIssuer        string                   `yaml:"mfa_issuer" env:"MFA_ISSUER" env-default:"restaurant"`
+	VerifyEmailURL   string                   `yaml:"verify_email_url" env:"VERIFY_EMAIL_URL" env-required:"true"`
+	VerificationTTL  time.Duration            `yaml:"verification_ttl" env:"VERIFICATION_TTL" env-default:"24h"`
+	ResendCooldown   time.Duration            `yaml:"resend_cooldown" env:"RESEND_COOLDOWN" env-default:"1m"`
+	ResetPasswordURL string                   `yaml:"reset_password_url" env:"RESET_PASSWORD_URL" env-required:"true"`
+	ResetPasswordTTL time.Duration            `yaml:"reset_password_ttl" env:"RESET_PASSWORD_TTL" env-default:"1h"`
+	ResetCooldown    time.Duration            `yaml:"reset_cooldown" env:"RESET_COOLDOWN" env-default:"1m"`
+	DeleteAccountURL string                   `yaml:"delete_account_url" env:"DELETE_ACCOUNT_URL" env-required:"true"`
+	DeletionTTL      time.Duration            `yaml:"deletion_ttl" env:"DELETION_TTL" env-default:"1h"`
+	Mailer           MailerConfig             `yaml:"mailer" env-prefix:"MAILER_"`
+	LoginLimit       LoginLimitConfig         `yaml:"login_limit" env-prefix:"LOGIN_LIMIT_"`
+	Password         PasswordConfig           `yaml:"password" env-prefix:"PASSWORD_"`
+	OIDC             OIDCConfig               `yaml:"oidc" env-prefix:"OIDC_"`
+	TrustedProxies   []string                 `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	TLS              TLSConfig                `yaml:"tls" env-prefix:"TLS_"`
+}
+
+type LoginLimitConfig struct {
+	MaxAttempts   int           `yaml:"max_attempts" env:"MAX_ATTEMPTS" env-default:"5"`
+	MaxIPAttempts int           `yaml:"max_ip_attempts" env:"MAX_IP_ATTEMPTS" env-default:"20"`
+	Window        time.Duration `yaml:"window" env:"WINDOW" env-default:"15m"`
+	BaseLockout   time.Duration `yaml:"base_lockout" env:"BASE_LOCKOUT" env-default:"30s"`
+	MaxLockout    time.Duration `yaml:"max_lockout" env:"MAX_LOCKOUT" env-default:"1h"`
 }
 
-type ReseravationService struct {
-	Port    int           `yaml:"port" env-required:"true"`
-	Timeout time.Duration `yaml:"timeout" env-required:"true"`
+type PasswordConfig struct {
+	MinLength        int          `yaml:"min_length" env:"MIN_LENGTH" env-default:"8"`
+	BreachedListPath string       `yaml:"breached_list_path" env:"BREACHED_LIST_PATH"`
+	Algorithm        string       `yaml:"algorithm" env:"ALGORITHM" env-default:"argon2id"`
+	BcryptCost       int          `yaml:"bcrypt_cost" env:"BCRYPT_COST" env-default:"10"`
+	Argon2           Argon2Config `yaml:"argon2" env-prefix:"ARGON2_"`
 }
 
-func MustLoad() *Config {
-	path := fectConfigPath()
-	if path == "" {
-		panic("config path empty")
-	}
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		panic("config doesnot exists: " + path)
-	}
-	cfg := new(Config)
+type Argon2Config struct {
+	Memory      uint32 `yaml:"memory" env:"MEMORY" env-default:"65536"`
+	Iterations  uint32 `yaml:"iterations" env:"ITERATIONS" env-default:"3"`
+	Parallelism uint8  `yaml:"parallelism" env:"PARALLELISM" env-default:"2"`
+}
+
+type OIDCConfig struct {
+	StateTTL  time.Duration        `yaml:"state_ttl" env:"STATE_TTL" env-default:"10m"`
+	Providers []OIDCProviderConfig `yaml:"providers"`
+}
 
-	if err := cleanenv.ReadConfig(path, cfg); err != nil {
-		panic("failed to read config: " + err.Error())
-	}
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+type MailerConfig struct {
+	Driver   string `yaml:"driver" env:"DRIVER" env-default:"file"`
+	From     string `yaml:"from" env:"FROM" env-required:"true"`
+	SMTPAddr string `yaml:"smtp_addr" env:"SMTP_ADDR"`
+	Username string `yaml:"username" env:"USERNAME"`
+	Password string `yaml:"password" env:"PASSWORD"`
+	Dir      string `yaml:"dir" env:"DIR" env-default:"./tmp/mail"`
+}
+
+type ReservationService struct {
+	Port                 int                      `yaml:"port" env:"PORT" env-required:"true"`
+	MetricsPort          int                      `yaml:"metrics_port" env:"METRICS_PORT"`
+	Timeout              time.Duration            `yaml:"timeout" env:"TIMEOUT" env-required:"true"`
+	Timeouts             map[string]time.Duration `yaml:"timeouts" env:"TIMEOUTS"`
+	SweepInterval        time.Duration            `yaml:"sweep_interval" env:"SWEEP_INTERVAL" env-default:"1h"`
+	RequireVerifiedEmail bool                     `yaml:"require_verified_email" env:"REQUIRE_VERIFIED_EMAIL" env-default:"false"`
+	TLS                  TLSConfig                `yaml:"tls" env-prefix:"TLS_"`
+}
 
-	return cfg
+type GatewayService struct {
+	Port            int           `yaml:"port" env:"PORT" env-required:"true"`
+	Timeout         time.Duration `yaml:"timeout" env:"TIMEOUT" env-required:"true"`
+	SSOAddr         string        `yaml:"sso_addr" env:"SSO_ADDR" env-required:"true"`
+	ReservationAddr string        `yaml:"reservation_addr" env:"RESERVATION_ADDR" env-required:"true"`
+	TLS             TLSConfig     `yaml:"tls" env-prefix:"TLS_"`
 }
 
-func fectConfigPath() (res string) {
-	flag.StringVar(&res, "config", "", "path to config file")
-	flag.Parse()
-	if res == "" {
-		res = os.Getenv("CONFIG_PATH")
-	}
-	return
+// TLSConfig describes the server side of a gRPC listener or the client side
+// of a connection. For servers CAFile holds the CAs trusted for client
+// certificates and Mutual makes presenting one mandatory; for clients it
+// holds the CAs trusted for the server, and CertFile/KeyFile are only needed
+// when the server requires mutual TLS.
+type TLSConfig struct {
+	Enabled        bool          `yaml:"enabled" env:"ENABLED"`
+	CertFile       string        `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"KEY_FILE"`
+	CAFile         string        `yaml:"ca_file" env:"CA_FILE"`
+	Mutual         bool          `yaml:"mutual" env:"MUTUAL"`
+	ServerName     string        `yaml:"server_name" env:"SERVER_NAME"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL" env-default:"30s"`
 }
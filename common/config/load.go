@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+type Validator interface {
+	Validate() error
+}
+
+// Load fills cfg from the YAML file at path, then from the environment, and
+// validates the result. With an empty path the environment is the only
+// source.
+func Load(path string, cfg Validator) error {
+	if path == "" {
+		if err := cleanenv.ReadEnv(cfg); err != nil {
+			return fmt.Errorf("failed to read config from environment: %w", err)
+		}
+		return cfg.Validate()
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("config file %s does not exist", path)
+	}
+	if err := cleanenv.ReadConfig(path, cfg); err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	return cfg.Validate()
+}
+
+// MustLoad loads cfg from the file given by --config or $CONFIG_PATH and
+// exits with a readable report when it is missing or invalid.
+func MustLoad(cfg Validator) {
+	if err := Load(fetchConfigPath(), cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func fetchConfigPath() (res string) {
+	flag.StringVar(&res, "config", "", "path to config file")
+	flag.Parse()
+	if res == "" {
+		res = os.Getenv("CONFIG_PATH")
+	}
+	return
+}
@@ -0,0 +1,164 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const localConfigDir = "../../../config/local"
+
+func TestLoad_LocalConfigs(t *testing.T) {
+	sso := new(config.SSOConfig)
+	assert.NoError(t, config.Load(filepath.Join(localConfigDir, "sso.yaml"), sso))
+	assert.Equal(t, 10116, sso.SSO.Port)
+	assert.Equal(t, 15*time.Second, sso.SSO.Timeouts["RegisterCustomer"])
+
+	reservation := new(config.ReservationConfig)
+	assert.NoError(t, config.Load(filepath.Join(localConfigDir, "reservation.yaml"), reservation))
+	assert.Equal(t, time.Hour, reservation.Reservation.SweepInterval)
+	assert.Equal(t, 20, reservation.Postgres.MaxOpenConns)
+	assert.Equal(t, 30*time.Minute, reservation.Postgres.ConnMaxLifetime)
+	assert.Equal(t, 10, reservation.Postgres.Retry.Attempts)
+	assert.Equal(t, time.Minute, reservation.Postgres.StatementTimeout)
+
+	gateway := new(config.GatewayConfig)
+	assert.NoError(t, config.Load(filepath.Join(localConfigDir, "gateway.yaml"), gateway))
+	assert.Equal(t, "localhost:10116", gateway.Gateway.SSOAddr)
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	t.Setenv("ENV", "prod")
+	t.Setenv("POSTGRES_MAX_OPEN_CONNS", "5")
+	t.Setenv("POSTGRES_MAX_IDLE_CONNS", "2")
+	t.Setenv("RESERVATION_PORT", "20120")
+	t.Setenv("RESERVATION_SWEEP_INTERVAL", "10m")
+	t.Setenv("RESERVATION_TLS_ENABLED", "false")
+	t.Setenv("RESERVATION_TIMEOUTS", "CreateReservation:2s")
+
+	cfg := new(config.ReservationConfig)
+	assert.NoError(t, config.Load(filepath.Join(localConfigDir, "reservation.yaml"), cfg))
+
+	assert.Equal(t, "prod", cfg.Env)
+	assert.Equal(t, 5, cfg.Postgres.MaxOpenConns)
+	assert.Equal(t, 2, cfg.Postgres.MaxIdleConns)
+	assert.Equal(t, 20120, cfg.Reservation.Port)
+	assert.Equal(t, 10*time.Minute, cfg.Reservation.SweepInterval)
+	assert.False(t, cfg.Reservation.TLS.Enabled)
+	assert.Equal(t, map[string]time.Duration{"CreateReservation": 2 * time.Second}, cfg.Reservation.Timeouts)
+}
+
+func TestLoad_EnvOnly(t *testing.T) {
+	t.Setenv("GATEWAY_PORT", "8081")
+	t.Setenv("GATEWAY_TIMEOUT", "3s")
+	t.Setenv("GATEWAY_SSO_ADDR", "sso:10116")
+	t.Setenv("GATEWAY_RESERVATION_ADDR", "reservation:10120")
+
+	cfg := new(config.GatewayConfig)
+	assert.NoError(t, config.Load("", cfg))
+
+	assert.Equal(t, "local", cfg.Env)
+	assert.Equal(t, 8081, cfg.Gateway.Port)
+	assert.Equal(t, "sso:10116", cfg.Gateway.SSOAddr)
+	assert.Equal(t, 30*time.Second, cfg.Gateway.TLS.ReloadInterval)
+	assert.True(t, cfg.Tracing.Insecure)
+}
+
+func TestLoad_StatementTimeoutShorterThanOverride(t *testing.T) {
+	t.Setenv("POSTGRES_STATEMENT_TIMEOUT", "20s")
+
+	err := config.Load(filepath.Join(localConfigDir, "sso.yaml"), new(config.SSOConfig))
+
+	assert.ErrorContains(t, err, "postgres.statement_timeout (20s) must not be shorter than sso.timeouts.ExportMyData (30s)")
+}
+
+func TestLoad_KeyValueDSN(t *testing.T) {
+	t.Setenv("POSTGRES_URL", "host=localhost dbname=restaurant sslmode=disable")
+
+	cfg := new(config.ReservationConfig)
+	assert.NoError(t, config.Load(filepath.Join(localConfigDir, "reservation.yaml"), cfg))
+
+	assert.Equal(t, "host=localhost dbname=restaurant sslmode=disable", cfg.Postgres.URL)
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	err := config.Load(filepath.Join(t.TempDir(), "missing.yaml"), new(config.GatewayConfig))
+
+	assert.ErrorContains(t, err, "does not exist")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+env: 'staging'
+postgres:
+  url: 'mysql://localhost/db'
+  max_open_conns: 5
+  max_idle_conns: 10
+  statement_timeout: 2s
+redis:
+  url: 'redis://localhost:6379/0'
+  retry:
+    backoff: 2s
+    max_backoff: 1s
+jwt:
+  secret: 'secret'
+  access_ttl: 1h
+  refresh_ttl: 30m
+sso:
+  port: 70000
+  metrics_port: 9116
+  timeout: 5s
+  timeouts:
+    Login: 0s
+  max_invitation_ttl: 24h
+  verify_email_url: 'localhost:3000/verify-email'
+  reset_password_url: 'http://localhost:3000/reset-password'
+  delete_account_url: 'http://localhost:3000/delete-account'
+  mailer:
+    driver: 'smtp'
+    from: 'no-reply@restaurant.local'
+  login_limit:
+    base_lockout: 1h
+    max_lockout: 1m
+  password:
+    bcrypt_cost: 40
+    argon2:
+      memory: 4
+      parallelism: 0
+  trusted_proxies: ['10.0.0.0/8', 'gateway']
+  tls:
+    enabled: true
+    mutual: true
+`), 0o600))
+
+	err := config.Load(path, new(config.SSOConfig))
+
+	assert.Error(t, err)
+	for _, problem := range []string{
+		`env must be one of local, dev, prod, got "staging"`,
+		"postgres.url must be an absolute postgres/postgresql URL",
+		"postgres.max_idle_conns must be between 0 and max_open_conns (5), got 10",
+		"postgres.statement_timeout (2s) must not be shorter than sso.timeout (5s)",
+		"jwt.refresh_ttl (30m0s) must be longer than access_ttl (1h0m0s)",
+		"sso.port must be between 1 and 65535, got 70000",
+		"sso.timeouts.Login must be positive",
+		"sso.max_invitation_ttl (24h0m0s) must not be shorter than invitation_ttl (72h0m0s)",
+		"sso.verify_email_url must be an absolute http/https URL",
+		"sso.mailer.smtp_addr is required for the smtp driver",
+		"sso.login_limit.max_lockout (1m0s) must not be shorter than base_lockout (1h0m0s)",
+		"sso.password.bcrypt_cost must be between 4 and 31, got 40",
+		"sso.password.argon2.memory must be at least 8 KiB per thread (16), got 4",
+		`sso.trusted_proxies[1] must be an IP address or CIDR range, got "gateway"`,
+		"sso.tls.cert_file and key_file are required",
+		"sso.tls.ca_file is required for mutual TLS",
+		"redis.retry.max_backoff (1s) must not be shorter than backoff (2s)",
+	} {
+		assert.ErrorContains(t, err, problem)
+	}
+	assert.NotContains(t, err.Error(), "reset_password_url")
+}
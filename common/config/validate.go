@@ -0,0 +1,307 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	envs           = []string{"local", "dev", "prod"}
+	mailerDrivers  = []string{"file", "smtp", "memory"}
+	hashAlgorithms = []string{"argon2id", "bcrypt"}
+)
+
+// Bounds of golang.org/x/crypto/bcrypt, which this package does not import.
+const (
+	minBcryptCost = 4
+	maxBcryptCost = 31
+)
+
+// problems collects every violation so that a broken config is reported in
+// one go rather than one field per restart.
+type problems []string
+
+func (p *problems) add(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return errors.New("invalid config:\n  - " + strings.Join(p, "\n  - "))
+}
+
+func (c *SSOConfig) Validate() error {
+	var p problems
+	p.env(c.Env)
+	p.postgres(c.Postgres)
+	p.url("redis.url", c.Redis.URL, "redis", "rediss")
+	p.retry("redis.retry", c.Redis.Retry)
+	p.jwt(c.Jwt)
+	p.tracing(c.Tracing)
+
+	s := c.SSO
+	p.ports("sso", s.Port, s.MetricsPort)
+	p.timeouts("sso", s.Timeout, s.Timeouts)
+	p.statementTimeout(c.Postgres.StatementTimeout, "sso", s.Timeout, s.Timeouts)
+	p.positive("sso.invitation_ttl", s.InvitationTTL)
+	if s.MaxInvitationTTL < s.InvitationTTL {
+		p.add("sso.max_invitation_ttl (%s) must not be shorter than invitation_ttl (%s)", s.MaxInvitationTTL, s.InvitationTTL)
+	}
+	p.positive("sso.verification_ttl", s.VerificationTTL)
+	p.positive("sso.resend_cooldown", s.ResendCooldown)
+	p.positive("sso.reset_password_ttl", s.ResetPasswordTTL)
+	p.positive("sso.reset_cooldown", s.ResetCooldown)
+	p.positive("sso.deletion_ttl", s.DeletionTTL)
+	p.url("sso.verify_email_url", s.VerifyEmailURL, "http", "https")
+	p.url("sso.reset_password_url", s.ResetPasswordURL, "http", "https")
+	p.url("sso.delete_account_url", s.DeleteAccountURL, "http", "https")
+	if s.MFAIssuer == "" {
+		p.add("sso.mfa_issuer is required")
+	}
+
+	p.oneOf("sso.mailer.driver", s.Mailer.Driver, mailerDrivers)
+	if s.Mailer.Driver == "smtp" && s.Mailer.SMTPAddr == "" {
+		p.add("sso.mailer.smtp_addr is required for the smtp driver")
+	}
+
+	l := s.LoginLimit
+	if l.MaxAttempts <= 0 {
+		p.add("sso.login_limit.max_attempts must be positive, got %d", l.MaxAttempts)
+	}
+	if l.MaxIPAttempts < l.MaxAttempts {
+		p.add("sso.login_limit.max_ip_attempts (%d) must not be less than max_attempts (%d)", l.MaxIPAttempts, l.MaxAttempts)
+	}
+	p.positive("sso.login_limit.window", l.Window)
+	p.positive("sso.login_limit.base_lockout", l.BaseLockout)
+	if l.MaxLockout < l.BaseLockout {
+		p.add("sso.login_limit.max_lockout (%s) must not be shorter than base_lockout (%s)", l.MaxLockout, l.BaseLockout)
+	}
+
+	if s.Password.MinLength <= 0 {
+		p.add("sso.password.min_length must be positive, got %d", s.Password.MinLength)
+	}
+	p.oneOf("sso.password.algorithm", s.Password.Algorithm, hashAlgorithms)
+	if s.Password.BcryptCost < minBcryptCost || s.Password.BcryptCost > maxBcryptCost {
+		p.add("sso.password.bcrypt_cost must be between %d and %d, got %d", minBcryptCost, maxBcryptCost, s.Password.BcryptCost)
+	}
+	a := s.Password.Argon2
+	if a.Iterations == 0 {
+		p.add("sso.password.argon2.iterations must be positive")
+	}
+	if a.Parallelism == 0 {
+		p.add("sso.password.argon2.parallelism must be positive")
+	}
+	if a.Memory < 8*uint32(a.Parallelism) {
+		p.add("sso.password.argon2.memory must be at least 8 KiB per thread (%d), got %d", 8*uint32(a.Parallelism), a.Memory)
+	}
+
+	p.positive("sso.oidc.state_ttl", s.OIDC.StateTTL)
+	seen := make(map[string]bool, len(s.OIDC.Providers))
+	for i, provider := range s.OIDC.Providers {
+		field := fmt.Sprintf("sso.oidc.providers[%d]", i)
+		switch {
+		case provider.Name == "":
+			p.add("%s.name is required", field)
+		case seen[provider.Name]:
+			p.add("%s.name %q is used twice", field, provider.Name)
+		}
+		seen[provider.Name] = true
+		if provider.ClientID == "" {
+			p.add("%s.client_id is required", field)
+		}
+		p.url(field+".issuer", provider.Issuer, "http", "https")
+		p.url(field+".redirect_url", provider.RedirectURL, "http", "https")
+	}
+
+	for i, proxy := range s.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				p.add("sso.trusted_proxies[%d] must be an IP address or CIDR range, got %q", i, proxy)
+			}
+		}
+	}
+	p.serverTLS("sso.tls", s.TLS)
+	return p.err()
+}
+
+func (c *ReservationConfig) Validate() error {
+	var p problems
+	p.env(c.Env)
+	p.postgres(c.Postgres)
+	p.tracing(c.Tracing)
+
+	r := c.Reservation
+	p.ports("reservation", r.Port, r.MetricsPort)
+	p.timeouts("reservation", r.Timeout, r.Timeouts)
+	p.statementTimeout(c.Postgres.StatementTimeout, "reservation", r.Timeout, r.Timeouts)
+	p.positive("reservation.sweep_interval", r.SweepInterval)
+	p.serverTLS("reservation.tls", r.TLS)
+	return p.err()
+}
+
+func (c *GatewayConfig) Validate() error {
+	var p problems
+	p.env(c.Env)
+	p.tracing(c.Tracing)
+
+	g := c.Gateway
+	p.ports("gateway", g.Port, 0)
+	p.positive("gateway.timeout", g.Timeout)
+	p.hostPort("gateway.sso_addr", g.SSOAddr)
+	p.hostPort("gateway.reservation_addr", g.ReservationAddr)
+
+	if g.TLS.Enabled {
+		if (g.TLS.CertFile == "") != (g.TLS.KeyFile == "") {
+			p.add("gateway.tls.cert_file and key_file must be set together")
+		}
+		if g.TLS.Mutual && g.TLS.CertFile == "" {
+			p.add("gateway.tls.cert_file and key_file are required for mutual TLS")
+		}
+		p.nonNegative("gateway.tls.reload_interval", g.TLS.ReloadInterval)
+	}
+	return p.err()
+}
+
+func (p *problems) env(env string) {
+	p.oneOf("env", env, envs)
+}
+
+func (p *problems) oneOf(field, value string, allowed []string) {
+	if !slices.Contains(allowed, value) {
+		p.add("%s must be one of %s, got %q", field, strings.Join(allowed, ", "), value)
+	}
+}
+
+func (p *problems) ports(service string, port, metricsPort int) {
+	if port < 1 || port > 65535 {
+		p.add("%s.port must be between 1 and 65535, got %d", service, port)
+	}
+	if metricsPort < 0 || metricsPort > 65535 {
+		p.add("%s.metrics_port must be between 0 (disabled) and 65535, got %d", service, metricsPort)
+	}
+	if metricsPort != 0 && metricsPort == port {
+		p.add("%s.metrics_port must differ from port %d", service, port)
+	}
+}
+
+func (p *problems) positive(field string, d time.Duration) {
+	if d <= 0 {
+		p.add("%s must be positive, got %s", field, d)
+	}
+}
+
+func (p *problems) nonNegative(field string, d time.Duration) {
+	if d < 0 {
+		p.add("%s must not be negative, got %s", field, d)
+	}
+}
+
+func (p *problems) timeouts(service string, timeout time.Duration, overrides map[string]time.Duration) {
+	p.positive(service+".timeout", timeout)
+	for _, method := range slices.Sorted(maps.Keys(overrides)) {
+		p.positive(fmt.Sprintf("%s.timeouts.%s", service, method), overrides[method])
+	}
+}
+
+// statementTimeout keeps the server-side limit from cutting off a query that
+// an RPC is still allowed to wait for.
+func (p *problems) statementTimeout(statementTimeout time.Duration, service string, timeout time.Duration, overrides map[string]time.Duration) {
+	field, longest := service+".timeout", timeout
+	for _, method := range slices.Sorted(maps.Keys(overrides)) {
+		if overrides[method] > longest {
+			field, longest = fmt.Sprintf("%s.timeouts.%s", service, method), overrides[method]
+		}
+	}
+	if statementTimeout < longest {
+		p.add("postgres.statement_timeout (%s) must not be shorter than %s (%s)", statementTimeout, field, longest)
+	}
+}
+
+func (p *problems) url(field, raw string, schemes ...string) {
+	if raw == "" {
+		p.add("%s is required", field)
+		return
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		p.add("%s is not a valid URL: %v", field, err)
+		return
+	}
+	if !slices.Contains(schemes, u.Scheme) || u.Host == "" {
+		p.add("%s must be an absolute %s URL, got %q", field, strings.Join(schemes, "/"), raw)
+	}
+}
+
+func (p *problems) hostPort(field, addr string) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		p.add("%s must be host:port, got %q", field, addr)
+	}
+}
+
+func (p *problems) postgres(cfg PostgresConfig) {
+	// lib/pq also accepts "host=... dbname=..." connection strings.
+	if strings.Contains(cfg.URL, "://") || !strings.Contains(cfg.URL, "=") {
+		p.url("postgres.url", cfg.URL, "postgres", "postgresql")
+	}
+	if cfg.MaxOpenConns <= 0 {
+		p.add("postgres.max_open_conns must be positive, got %d", cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns < 0 || cfg.MaxIdleConns > cfg.MaxOpenConns {
+		p.add("postgres.max_idle_conns must be between 0 and max_open_conns (%d), got %d", cfg.MaxOpenConns, cfg.MaxIdleConns)
+	}
+	p.nonNegative("postgres.conn_max_lifetime", cfg.ConnMaxLifetime)
+	p.nonNegative("postgres.conn_max_idle_time", cfg.ConnMaxIdleTime)
+	p.retry("postgres.retry", cfg.Retry)
+}
+
+func (p *problems) retry(field string, cfg RetryConfig) {
+	if cfg.Attempts < 1 {
+		p.add("%s.attempts must be at least 1, got %d", field, cfg.Attempts)
+	}
+	p.positive(field+".backoff", cfg.Backoff)
+	if cfg.MaxBackoff < cfg.Backoff {
+		p.add("%s.max_backoff (%s) must not be shorter than backoff (%s)", field, cfg.MaxBackoff, cfg.Backoff)
+	}
+}
+
+func (p *problems) jwt(cfg JwtConfig) {
+	if cfg.Secret == "" {
+		p.add("jwt.secret is required")
+	}
+	p.positive("jwt.access_ttl", cfg.AccessTTL)
+	p.positive("jwt.mfa_ttl", cfg.MFATTL)
+	if cfg.RefreshTTL <= cfg.AccessTTL {
+		p.add("jwt.refresh_ttl (%s) must be longer than access_ttl (%s)", cfg.RefreshTTL, cfg.AccessTTL)
+	}
+}
+
+func (p *problems) tracing(cfg TracingConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	p.hostPort("tracing.endpoint", cfg.Endpoint)
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		p.add("tracing.sample_ratio must be between 0 and 1, got %g", cfg.SampleRatio)
+	}
+}
+
+func (p *problems) serverTLS(field string, cfg TLSConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		p.add("%s.cert_file and key_file are required", field)
+	}
+	if cfg.Mutual && cfg.CAFile == "" {
+		p.add("%s.ca_file is required for mutual TLS", field)
+	}
+	p.nonNegative(field+".reload_interval", cfg.ReloadInterval)
+}
@@ -0,0 +1,6 @@
+package constants
+
+const (
+	AuditActionDataExported   = "data_exported"
+	AuditActionAccountDeleted = "account_deleted"
+)
@@ -0,0 +1,11 @@
+package constants
+
+const (
+	PermissionReservationsCreate = "reservations:create"
+	PermissionReservationsClose  = "reservations:close"
+	PermissionTablesManage       = "tables:manage"
+	PermissionAnalyticsRead      = "analytics:read"
+	PermissionStaffManage        = "staff:manage"
+	PermissionCustomersManage    = "customers:manage"
+	PermissionPermissionsManage  = "permissions:manage"
+)
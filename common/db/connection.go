@@ -1,19 +1,95 @@
 package db
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/retry"
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-func MustConnect(url string) *sqlx.DB {
-	db, err := sqlx.Connect("postgres", url)
+const pingTimeout = 5 * time.Second
+
+// Connect opens the pool and waits for Postgres to answer, see Open and Wait.
+func Connect(ctx context.Context, log *slog.Logger, cfg config.PostgresConfig) (*sqlx.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := Wait(ctx, log, db, cfg.Retry); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Open configures the pool without connecting, so a service can come up and
+// report itself unhealthy while Postgres is still starting.
+// cfg.StatementTimeout is applied server-side to every statement, so queries
+// issued without a deadline (background jobs) cannot run forever either.
+func Open(cfg config.PostgresConfig) (*sqlx.DB, error) {
+	dsn, err := WithStatementTimeout(cfg.URL, cfg.StatementTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres url: %w", err)
+	}
+
+	conn, err := otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	db := sqlx.NewDb(conn, "postgres")
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
 
-	if err = db.Ping(); err != nil {
-		panic(err)
+// Wait blocks until Postgres answers, retrying as configured.
+func Wait(ctx context.Context, log *slog.Logger, db *sqlx.DB, cfg config.RetryConfig) error {
+	err := retry.Do(ctx, log, "postgres", cfg, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+		defer cancel()
+		return db.PingContext(ctx)
+	})
+	if err != nil {
+		return err
 	}
 
-	return db
+	log.Info("postgres is ready")
+	return nil
+}
+
+// WithStatementTimeout adds statement_timeout to either form of DSN lib/pq
+// accepts: a postgres:// URL or a key=value connection string. Zero leaves
+// the server default.
+func WithStatementTimeout(dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+	millis := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		// A later key overrides an earlier one, so this wins over a value in dsn.
+		return strings.TrimSpace(dsn) + " statement_timeout=" + millis, nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("statement_timeout", millis)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
 }
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS mfa_secrets
+(
+  entity_id UUID PRIMARY KEY,
+  role VARCHAR(255) NOT NULL,
+  secret VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  confirmed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes
+(
+  code_id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  entity_id UUID NOT NULL REFERENCES mfa_secrets(entity_id) ON DELETE CASCADE,
+  code_hash BYTEA NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1 @@
+ALTER TABLE customers DROP COLUMN IF EXISTS email_verified_at;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS invitations
+(
+  invitation_id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  code_hash BYTEA UNIQUE NOT NULL,
+  role VARCHAR(255) NOT NULL,
+  first_name VARCHAR(255),
+  last_name VARCHAR(255),
+  note TEXT,
+  created_by UUID REFERENCES admins(admin_id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE
+);
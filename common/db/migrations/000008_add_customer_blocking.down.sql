@@ -0,0 +1,2 @@
+ALTER TABLE customers DROP COLUMN IF EXISTS blocked_reason;
+ALTER TABLE customers DROP COLUMN IF EXISTS blocked_at;
\ No newline at end of file
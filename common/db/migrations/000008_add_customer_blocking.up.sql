@@ -0,0 +1,2 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS blocked_reason TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE customers DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
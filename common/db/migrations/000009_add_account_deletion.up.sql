@@ -0,0 +1,12 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS audit_log
+(
+  audit_id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  entity_id UUID NOT NULL,
+  role VARCHAR(255) NOT NULL,
+  action VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_id_idx ON audit_log (entity_id);
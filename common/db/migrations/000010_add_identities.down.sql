@@ -0,0 +1,18 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS password BYTEA;
+ALTER TABLE waiters ADD COLUMN IF NOT EXISTS password BYTEA, ADD COLUMN IF NOT EXISTS login VARCHAR(255);
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS password BYTEA, ADD COLUMN IF NOT EXISTS login VARCHAR(255);
+
+UPDATE customers c SET password = i.password FROM identities i WHERE i.identity_id = c.identity_id;
+UPDATE waiters w SET password = i.password, login = i.login FROM identities i WHERE i.identity_id = w.identity_id;
+UPDATE admins a SET password = i.password, login = i.login FROM identities i WHERE i.identity_id = a.identity_id;
+
+ALTER TABLE customers ALTER COLUMN password SET NOT NULL;
+ALTER TABLE waiters ALTER COLUMN password SET NOT NULL, ALTER COLUMN login SET NOT NULL, ADD CONSTRAINT waiters_login_key UNIQUE (login);
+ALTER TABLE admins ALTER COLUMN password SET NOT NULL, ALTER COLUMN login SET NOT NULL, ADD CONSTRAINT admins_login_key UNIQUE (login);
+
+ALTER TABLE customers DROP COLUMN IF EXISTS identity_id;
+ALTER TABLE waiters DROP COLUMN IF EXISTS identity_id;
+ALTER TABLE admins DROP COLUMN IF EXISTS identity_id;
+
+DROP TABLE IF EXISTS identity_roles;
+DROP TABLE IF EXISTS identities;
@@ -0,0 +1,63 @@
+-- Accounts of different roles sharing a login cannot be merged, since their
+-- passwords differ. Refuse to migrate until they are renamed by hand instead
+-- of renaming them silently and locking their owners out.
+DO $$
+DECLARE
+  clashes TEXT;
+BEGIN
+  SELECT string_agg(login, ', ' ORDER BY login) INTO clashes FROM (
+    SELECT login FROM (
+      SELECT login FROM admins
+      UNION ALL SELECT login FROM waiters
+      UNION ALL SELECT email FROM customers
+    ) logins
+    GROUP BY login HAVING COUNT(*) > 1
+  ) clashing;
+
+  IF clashes IS NOT NULL THEN
+    RAISE EXCEPTION 'logins shared by accounts of different roles must be renamed before migrating: %', clashes;
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS identities
+(
+  identity_id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+  login VARCHAR(255) UNIQUE NOT NULL,
+  password BYTEA NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS identity_roles
+(
+  identity_id UUID NOT NULL REFERENCES identities(identity_id) ON DELETE CASCADE,
+  role VARCHAR(255) NOT NULL,
+  entity_id UUID NOT NULL,
+  granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (identity_id, role),
+  UNIQUE (role, entity_id)
+);
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS identity_id UUID REFERENCES identities(identity_id);
+ALTER TABLE waiters ADD COLUMN IF NOT EXISTS identity_id UUID REFERENCES identities(identity_id);
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS identity_id UUID REFERENCES identities(identity_id);
+
+-- Existing accounts keep their profile id as identity id.
+INSERT INTO identities (identity_id, login, password) SELECT admin_id, login, password FROM admins;
+INSERT INTO identities (identity_id, login, password) SELECT waiter_id, login, password FROM waiters;
+INSERT INTO identities (identity_id, login, password) SELECT customer_id, email, password FROM customers;
+
+UPDATE admins SET identity_id = admin_id;
+UPDATE waiters SET identity_id = waiter_id;
+UPDATE customers SET identity_id = customer_id;
+
+INSERT INTO identity_roles (identity_id, role, entity_id) SELECT admin_id, 'admin', admin_id FROM admins;
+INSERT INTO identity_roles (identity_id, role, entity_id) SELECT waiter_id, 'waiter', waiter_id FROM waiters;
+INSERT INTO identity_roles (identity_id, role, entity_id) SELECT customer_id, 'customer', customer_id FROM customers WHERE deleted_at IS NULL;
+
+ALTER TABLE customers ALTER COLUMN identity_id SET NOT NULL, ADD CONSTRAINT customers_identity_id_key UNIQUE (identity_id);
+ALTER TABLE waiters ALTER COLUMN identity_id SET NOT NULL, ADD CONSTRAINT waiters_identity_id_key UNIQUE (identity_id);
+ALTER TABLE admins ALTER COLUMN identity_id SET NOT NULL, ADD CONSTRAINT admins_identity_id_key UNIQUE (identity_id);
+
+ALTER TABLE customers DROP COLUMN IF EXISTS password;
+ALTER TABLE waiters DROP COLUMN IF EXISTS password, DROP COLUMN IF EXISTS login;
+ALTER TABLE admins DROP COLUMN IF EXISTS password, DROP COLUMN IF EXISTS login;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS permissions
+(
+  permission VARCHAR(255) PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+  role VARCHAR(255) NOT NULL,
+  permission VARCHAR(255) NOT NULL REFERENCES permissions(permission) ON DELETE CASCADE,
+  granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (role, permission)
+);
+
+INSERT INTO permissions (permission, description) VALUES
+  ('reservations:create', 'Create reservations'),
+  ('reservations:close', 'Close and cancel reservations'),
+  ('tables:manage', 'Create, update and remove tables'),
+  ('analytics:read', 'Read restaurant analytics'),
+  ('staff:manage', 'Invite, fire and unlock staff accounts'),
+  ('customers:manage', 'Search, inspect and block customers'),
+  ('permissions:manage', 'Change role permissions')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+  ('customer', 'reservations:create'),
+  ('waiter', 'reservations:create'),
+  ('waiter', 'reservations:close'),
+  ('admin', 'reservations:create'),
+  ('admin', 'reservations:close'),
+  ('admin', 'tables:manage'),
+  ('admin', 'analytics:read'),
+  ('admin', 'staff:manage'),
+  ('admin', 'customers:manage'),
+  ('admin', 'permissions:manage')
+ON CONFLICT DO NOTHING;
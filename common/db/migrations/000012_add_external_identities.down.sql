@@ -0,0 +1,4 @@
+UPDATE customers SET birth_date = DATE '1900-01-01' WHERE birth_date IS NULL;
+ALTER TABLE customers ALTER COLUMN birth_date SET NOT NULL;
+
+DROP TABLE IF EXISTS external_identities;
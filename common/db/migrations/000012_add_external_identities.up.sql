@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS external_identities
+(
+  provider VARCHAR(255) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  identity_id UUID NOT NULL REFERENCES identities(identity_id) ON DELETE CASCADE,
+  email VARCHAR(255),
+  linked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS external_identities_identity_id_idx ON external_identities (identity_id);
+
+-- Identity providers rarely share the birth date, so it becomes optional.
+ALTER TABLE customers ALTER COLUMN birth_date DROP NOT NULL;
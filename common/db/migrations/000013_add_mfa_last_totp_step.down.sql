@@ -0,0 +1 @@
+ALTER TABLE mfa_secrets DROP COLUMN IF EXISTS last_totp_step;
@@ -0,0 +1,2 @@
+-- The time step of the last accepted code, so a code cannot be used twice.
+ALTER TABLE mfa_secrets ADD COLUMN IF NOT EXISTS last_totp_step BIGINT;
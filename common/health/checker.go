@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type Check func(ctx context.Context) error
+
+func DBCheck(db *sqlx.DB) Check {
+	return db.PingContext
+}
+
+func RedisCheck(rdb *redis.Client) Check {
+	return func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}
+}
+
+// Checker keeps the grpc.health.v1 status of a server in line with the
+// availability of its dependencies.
+type Checker struct {
+	log      *slog.Logger
+	server   *grpchealth.Server
+	services []string
+	checks   map[string]Check
+	interval time.Duration
+}
+
+// NewChecker reports the overall ("") status and the status of every listed
+// service, all backed by the same set of dependency checks. Everything is
+// NOT_SERVING until Run completes the first round of checks, so a server can
+// be registered and serve health before its dependencies are reachable.
+func NewChecker(log *slog.Logger, interval time.Duration, checks map[string]Check, services ...string) *Checker {
+	checker := &Checker{
+		log:      log,
+		server:   grpchealth.NewServer(),
+		services: append([]string{""}, services...),
+		checks:   checks,
+		interval: interval,
+	}
+	checker.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return checker
+}
+
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Run checks the dependencies every interval until ctx is done. Start it once
+// the service stopped waiting for them at startup, until then it stays
+// NOT_SERVING.
+func (c *Checker) Run(ctx context.Context) {
+	const op = "health.Run"
+	log := c.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	serving := c.check(ctx)
+	log.Info("initial health status", "status", serving.String())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if status := c.check(ctx); status != serving {
+				log.Info("health status changed", "status", status.String())
+				serving = status
+			}
+		}
+	}
+}
+
+// Shutdown reports NOT_SERVING for every service and ignores later checks, so
+// clients drain before the server stops.
+func (c *Checker) Shutdown() {
+	c.server.Shutdown()
+}
+
+func (c *Checker) check(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.interval)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	for name, check := range c.checks {
+		if err := check(ctx); err != nil {
+			c.log.Warn("dependency check failed", "dependency", name, "error", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	c.setStatus(status)
+	return status
+}
+
+func (c *Checker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
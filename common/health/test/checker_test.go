@@ -0,0 +1,72 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/health"
+	"github.com/stretchr/testify/assert"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	testService  = "test.Service"
+	testInterval = 10 * time.Millisecond
+)
+
+func NewTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func status(t *testing.T, checker *health.Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	res, err := checker.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	assert.NoError(t, err)
+	return res.Status
+}
+
+func eventuallyStatus(t *testing.T, checker *health.Checker, expected healthpb.HealthCheckResponse_ServingStatus) {
+	assert.Eventually(t, func() bool {
+		return status(t, checker, "") == expected && status(t, checker, testService) == expected
+	}, time.Second, testInterval)
+}
+
+func TestChecker(t *testing.T) {
+	var redisDown atomic.Bool
+	checks := map[string]health.Check{
+		"postgres": func(ctx context.Context) error { return nil },
+		"redis": func(ctx context.Context) error {
+			if redisDown.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+	checker := health.NewChecker(NewTestLogger(), testInterval, checks, testService)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, checker, testService))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go checker.Run(ctx)
+
+	eventuallyStatus(t, checker, healthpb.HealthCheckResponse_SERVING)
+
+	redisDown.Store(true)
+	eventuallyStatus(t, checker, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	redisDown.Store(false)
+	eventuallyStatus(t, checker, healthpb.HealthCheckResponse_SERVING)
+
+	checker.Shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, checker, testService))
+
+	time.Sleep(3 * testInterval)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(t, checker, ""))
+}
@@ -0,0 +1,130 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const RequestIDKey = "x-request-id"
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+type callerKey struct{}
+
+// SetCaller records the entity a handler authenticated the call as, so that
+// the request log names it. Metadata sent by the client is never trusted for
+// this, since anyone can put an id there.
+func SetCaller(ctx context.Context, entityID string) {
+	if caller, ok := ctx.Value(callerKey{}).(*string); ok {
+		*caller = entityID
+	}
+}
+
+// Logging writes one record per handled call. The request id is taken from
+// the incoming metadata, or generated when the caller did not send one, and
+// is echoed back in the response header. The caller is logged as the entity
+// passed to SetCaller and the subject of the verified client certificate.
+func Logging(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		requestID := incoming(ctx, RequestIDKey)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+		var callerID string
+		resp, err := handler(context.WithValue(ctx, callerKey{}, &callerID), req)
+
+		code := status.Code(err)
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("code", code.String()),
+			slog.String("request_id", requestID),
+			slog.String("peer", peerAddr(ctx)),
+		}
+		if callerID != "" {
+			attrs = append(attrs, slog.String("caller_id", callerID))
+		}
+		if subject := peerSubject(ctx); subject != "" {
+			attrs = append(attrs, slog.String("peer_subject", subject))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+		log.LogAttrs(ctx, logLevel(info.FullMethod, code), "handled request", attrs...)
+
+		return resp, err
+	}
+}
+
+// Recovery turns a panic in a handler into codes.Internal instead of letting
+// it take the whole process down.
+func Recovery(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.ErrorContext(ctx, "recovered from panic", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+				resp, err = nil, status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func logLevel(method string, code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		if strings.HasPrefix(method, healthServicePrefix) {
+			return slog.LevelDebug
+		}
+		return slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded, codes.Unimplemented:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
+
+func incoming(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// peerSubject returns the common name of the client certificate the peer
+// authenticated with, or "" without mutual TLS.
+func peerSubject(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
@@ -0,0 +1,185 @@
+package interceptors_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/interceptors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/reservation.Reservation/GetReservation"}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logging := interceptors.Logging(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	t.Run("success", func(t *testing.T) {
+		buf.Reset()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(interceptors.RequestIDKey, "req-1"))
+
+		resp, err := logging(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			interceptors.SetCaller(ctx, "user-1")
+			return "ok", nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+		assert.Contains(t, buf.String(), "level=INFO")
+		assert.Contains(t, buf.String(), "method=/reservation.Reservation/GetReservation")
+		assert.Contains(t, buf.String(), "code=OK")
+		assert.Contains(t, buf.String(), "request_id=req-1")
+		assert.Contains(t, buf.String(), "caller_id=user-1")
+		assert.Contains(t, buf.String(), "duration=")
+	})
+
+	t.Run("ignores caller metadata", func(t *testing.T) {
+		buf.Reset()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-caller-id", "forged"))
+
+		_, err := logging(ctx, nil, info, func(context.Context, any) (any, error) { return "ok", nil })
+
+		assert.NoError(t, err)
+		assert.NotContains(t, buf.String(), "forged")
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		buf.Reset()
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: "gateway"}}
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr:     &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4000},
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+		})
+
+		_, err := logging(ctx, nil, info, func(context.Context, any) (any, error) { return "ok", nil })
+
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "peer_subject=gateway")
+		assert.Contains(t, buf.String(), "peer=10.0.0.1:4000")
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		buf.Reset()
+
+		_, err := logging(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.NotFound, "reservation not found")
+		})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Contains(t, buf.String(), "level=WARN")
+		assert.Contains(t, buf.String(), "code=NotFound")
+		assert.Contains(t, buf.String(), `error="reservation not found"`)
+		assert.Regexp(t, `request_id=[0-9a-f-]{36}`, buf.String())
+		assert.NotContains(t, buf.String(), "caller_id")
+		assert.NotContains(t, buf.String(), "peer_subject")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		buf.Reset()
+
+		_, err := logging(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, errors.New("boom")
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, buf.String(), "level=ERROR")
+		assert.Contains(t, buf.String(), "code=Unknown")
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	recovery := interceptors.Recovery(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	t.Run("panic", func(t *testing.T) {
+		resp, err := recovery(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			panic("boom")
+		})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Contains(t, buf.String(), "recovered from panic")
+		assert.Contains(t, buf.String(), "panic=boom")
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		resp, err := recovery(context.Background(), nil, info, func(context.Context, any) (any, error) { return "ok", nil })
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	timeout := interceptors.Timeout(50*time.Millisecond, map[string]time.Duration{"Slow": time.Second})
+
+	t.Run("applies default deadline", func(t *testing.T) {
+		var remaining time.Duration
+		_, err := timeout(context.Background(), nil, info, func(ctx context.Context, _ any) (any, error) {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			remaining = time.Until(deadline)
+			return nil, nil
+		})
+
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, remaining, 50*time.Millisecond)
+	})
+
+	t.Run("method override", func(t *testing.T) {
+		var remaining time.Duration
+		_, err := timeout(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/sso.SSO/Slow"}, func(ctx context.Context, _ any) (any, error) {
+			deadline, _ := ctx.Deadline()
+			remaining = time.Until(deadline)
+			return nil, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Greater(t, remaining, 50*time.Millisecond)
+	})
+
+	t.Run("keeps shorter client deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		clientDeadline, _ := ctx.Deadline()
+
+		_, err := timeout(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			deadline, _ := ctx.Deadline()
+			assert.Equal(t, clientDeadline, deadline)
+			return nil, nil
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		_, err := timeout(context.Background(), nil, info, func(ctx context.Context, _ any) (any, error) {
+			<-ctx.Done()
+			return nil, status.Error(codes.Internal, "failed to get reservation")
+		})
+
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+
+	t.Run("error before deadline", func(t *testing.T) {
+		_, err := timeout(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.NotFound, "reservation not found")
+		})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
@@ -0,0 +1,41 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Timeout bounds every call by timeout, or by the override keyed by the RPC
+// method name (e.g. "Login"), unless the client already asked for a shorter
+// deadline. A call that fails after its deadline has passed is reported as
+// codes.DeadlineExceeded whatever error the handler mapped it to.
+func Timeout(timeout time.Duration, overrides map[string]time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		d := timeout
+		if override, ok := overrides[methodName(info.FullMethod)]; ok {
+			d = override
+		}
+		if d <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "deadline exceeded")
+		}
+		return resp, err
+	}
+}
+
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const readHeaderTimeout = 5 * time.Second
+
+var grpcHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_server_handling_seconds",
+	Help:    "Latency of unary gRPC calls handled by the server.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"grpc_service", "grpc_method", "grpc_code"})
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		service, method := splitMethod(info.FullMethod)
+		grpcHandlingSeconds.WithLabelValues(service, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+func splitMethod(fullMethod string) (string, string) {
+	service, method, found := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !found {
+		return "unknown", "unknown"
+	}
+	return service, method
+}
+
+// RegisterDB exposes the connection pool statistics of db.
+func RegisterDB(db *sqlx.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, "postgres"))
+}
+
+type Server struct {
+	server *http.Server
+	log    *slog.Logger
+}
+
+// NewServer returns the metrics listener, or nil when port is zero so that
+// metrics can be switched off per service.
+func NewServer(log *slog.Logger, port int) *Server {
+	if port == 0 {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	return &Server{
+		server: &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux, ReadHeaderTimeout: readHeaderTimeout},
+		log:    log,
+	}
+}
+
+// Run serves metrics until Shutdown, after which it returns nil.
+func (s *Server) Run() error {
+	if s == nil {
+		return nil
+	}
+	const op = "metrics.Run"
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("metrics server started", "addr", s.server.Addr)
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	const op = "metrics.Shutdown"
+	log := s.log.With(slog.String("op", op))
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Error("failed to shutdown metrics server", "error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func handledCount(t *testing.T, service, method, code string) uint64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "grpc_server_handling_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["grpc_service"] == service && labels["grpc_method"] == method && labels["grpc_code"] == code {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}
+
+	resp, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.Equal(t, uint64(1), handledCount(t, "test.Service", "Call", codes.OK.String()))
+	assert.Equal(t, uint64(1), handledCount(t, "test.Service", "Call", codes.NotFound.String()))
+}
+
+func TestNewServerDisabled(t *testing.T) {
+	server := metrics.NewServer(NewTestLogger(), 0)
+
+	assert.Nil(t, server)
+	assert.NoError(t, server.Run())
+	server.Shutdown(context.Background())
+}
+
+func TestServerRunListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	server := metrics.NewServer(NewTestLogger(), listener.Addr().(*net.TCPAddr).Port)
+
+	assert.ErrorContains(t, server.Run(), "failed to serve metrics")
+}
@@ -2,24 +2,51 @@ package redis
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/retry"
 	"github.com/redis/go-redis/v9"
 )
 
-func MustConnect(url string) *redis.Client {
-	opts, err := redis.ParseURL(url)
+const pingTimeout = 5 * time.Second
+
+// Connect creates the client and waits for Redis to answer, see Open and Wait.
+func Connect(ctx context.Context, log *slog.Logger, cfg config.RedisConfig) (*redis.Client, error) {
+	client, err := Open(cfg)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if err := Wait(ctx, log, client, cfg.Retry); err != nil {
+		client.Close()
+		return nil, err
 	}
-	client := redis.NewClient(opts)
+	return client, nil
+}
 
-	ping, err := client.Ping(context.Background()).Result()
+// Open creates the client without connecting, so a service can come up and
+// report itself unhealthy while Redis is still starting.
+func Open(cfg config.RedisConfig) (*redis.Client, error) {
+	opts, err := redis.ParseURL(cfg.URL)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("invalid redis url: %w", err)
 	}
-	if ping != "PONG" {
-		panic("failed to connect to redis")
+	return redis.NewClient(opts), nil
+}
+
+// Wait blocks until Redis answers, retrying as configured.
+func Wait(ctx context.Context, log *slog.Logger, client *redis.Client, cfg config.RetryConfig) error {
+	err := retry.Do(ctx, log, "redis", cfg, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+		defer cancel()
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		return err
 	}
 
-	return client
+	log.Info("redis is ready")
+	return nil
 }
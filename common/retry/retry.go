@@ -0,0 +1,37 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+)
+
+// Do calls fn until it succeeds, cfg.Attempts calls have failed or ctx is
+// done. The pause between calls starts at cfg.Backoff and doubles up to
+// cfg.MaxBackoff. Every failed attempt is logged so that a service waiting
+// for its dependencies says what it is waiting for.
+func Do(ctx context.Context, log *slog.Logger, name string, cfg config.RetryConfig, fn func(ctx context.Context) error) error {
+	backoff := cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.Attempts {
+			return fmt.Errorf("%s unavailable after %d attempts: %w", name, attempt, err)
+		}
+
+		log.Warn("dependency not ready", "dependency", name, "attempt", attempt, "retry_in", backoff, "error", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up waiting for %s: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, cfg.MaxBackoff)
+	}
+}
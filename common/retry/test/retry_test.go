@@ -0,0 +1,82 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+var cfg = config.RetryConfig{Attempts: 4, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+var errDown = errors.New("connection refused")
+
+func TestDo(t *testing.T) {
+	t.Run("succeeds after failures", func(t *testing.T) {
+		calls := 0
+		err := retry.Do(context.Background(), NewTestLogger(), "postgres", cfg, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errDown
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after attempts", func(t *testing.T) {
+		calls := 0
+		err := retry.Do(context.Background(), NewTestLogger(), "postgres", cfg, func(context.Context) error {
+			calls++
+			return errDown
+		})
+
+		assert.ErrorIs(t, err, errDown)
+		assert.ErrorContains(t, err, "postgres unavailable after 4 attempts")
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := config.RetryConfig{Attempts: 10, Backoff: time.Hour, MaxBackoff: time.Hour}
+		calls := 0
+
+		err := retry.Do(ctx, NewTestLogger(), "redis", slow, func(context.Context) error {
+			calls++
+			cancel()
+			return errDown
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("backoff doubles up to the cap", func(t *testing.T) {
+		var pauses []time.Duration
+		last := time.Now()
+		capped := config.RetryConfig{Attempts: 4, Backoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+
+		_ = retry.Do(context.Background(), NewTestLogger(), "redis", capped, func(context.Context) error {
+			pauses = append(pauses, time.Since(last))
+			last = time.Now()
+			return errDown
+		})
+
+		assert.Len(t, pauses, 4)
+		assert.GreaterOrEqual(t, pauses[1], 10*time.Millisecond)
+		assert.GreaterOrEqual(t, pauses[2], 20*time.Millisecond)
+		assert.GreaterOrEqual(t, pauses[3], 20*time.Millisecond)
+	})
+}
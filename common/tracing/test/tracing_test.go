@@ -0,0 +1,111 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+
+	pb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), "test", config.TracingConfig{Enabled: false})
+
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(tracing.NewLogHandler(slog.NewTextHandler(&buf, nil))).With(slog.String("op", "test"))
+
+	t.Run("with span", func(t *testing.T) {
+		buf.Reset()
+		ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "op")
+		defer span.End()
+
+		log.InfoContext(ctx, "hello")
+
+		assert.Contains(t, buf.String(), "trace_id="+span.SpanContext().TraceID().String())
+		assert.Contains(t, buf.String(), "span_id="+span.SpanContext().SpanID().String())
+		assert.Contains(t, buf.String(), "op=test")
+	})
+
+	t.Run("without span", func(t *testing.T) {
+		buf.Reset()
+
+		log.InfoContext(context.Background(), "hello")
+
+		assert.NotContains(t, buf.String(), "trace_id")
+	})
+}
+
+type reservationServer struct {
+	pb.UnimplementedReservationServer
+	spanContext trace.SpanContext
+}
+
+func (s *reservationServer) GetReservation(ctx context.Context, _ *pb.GetReservationRequest) (*pb.ReservationInfo, error) {
+	s.spanContext = trace.SpanContextFromContext(ctx)
+	return &pb.ReservationInfo{}, nil
+}
+
+func TestGRPCPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	_, err := tracing.Setup(context.Background(), "test", config.TracingConfig{Enabled: false})
+	assert.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	service := new(reservationServer)
+	server := grpc.NewServer(tracing.ServerOption())
+	pb.RegisterReservationServer(server, service)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		tracing.DialOption(),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx, root := provider.Tracer("test").Start(context.Background(), "request")
+	_, err = pb.NewReservationClient(conn).GetReservation(ctx, &pb.GetReservationRequest{ReservationId: "1"})
+	root.End()
+
+	assert.NoError(t, err)
+	assert.Equal(t, root.SpanContext().TraceID(), service.spanContext.TraceID())
+
+	var client, srv sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case trace.SpanKindClient:
+			client = span
+		case trace.SpanKindServer:
+			srv = span
+		}
+	}
+	if assert.NotNil(t, client) && assert.NotNil(t, srv) {
+		assert.Equal(t, "reservation.Reservation/GetReservation", srv.Name())
+		assert.Equal(t, client.SpanContext().SpanID(), srv.Parent().SpanID())
+		assert.Equal(t, root.SpanContext().SpanID(), client.Parent().SpanID())
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"net/http"
 )
 
-func ParseJSON(r *http.Request, payload any) error {
-	return json.NewDecoder(r.Body).Decode(payload)
+// maxJSONBody bounds request bodies, which are small JSON documents, so a
+// client cannot make the server buffer an endless one.
+const maxJSONBody = 1 << 20
+
+func ParseJSON(w http.ResponseWriter, r *http.Request, payload any) error {
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBody)).Decode(payload)
 }
 
 func WriteJSON(w http.ResponseWriter, status int, payload any) error {
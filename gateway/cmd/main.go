@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/app"
+)
+
+const (
+	envLocal = "local"
+	envDev   = "dev"
+	envProd  = "prod"
+)
+
+func main() {
+	cfg := new(config.GatewayConfig)
+	config.MustLoad(cfg)
+
+	logger := setupLogger(cfg.Env)
+	logger = logger.With(slog.String("env", cfg.Env))
+
+	if err := run(cfg, logger); err != nil {
+		logger.Error("gateway stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run returns instead of exiting, so the deferred cleanup always happens.
+func run(cfg *config.GatewayConfig, logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "gateway", cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	app, err := app.New(logger, cfg.Gateway)
+	if err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.Run(cfg.Gateway.Port)
+	}()
+	defer app.Shutdown()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serveErr:
+		return fmt.Errorf("HTTP server failed: %w", err)
+	}
+}
+
+func setupLogger(env string) (logger *slog.Logger) {
+	switch env {
+	case envLocal:
+		logger = slog.New(tracing.NewLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	case envDev:
+		logger = slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	case envProd:
+		logger = slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	reservationpb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	ssopb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/certs"
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/handler"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+type App struct {
+	server     *http.Server
+	conns      []*grpc.ClientConn
+	log        *slog.Logger
+	stopReload context.CancelFunc
+}
+
+func New(log *slog.Logger, cfg config.GatewayService) (*App, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	creds, err := certs.ClientCredentials(ctx, log, cfg.TLS)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
+	}
+
+	ssoConn, err := grpc.NewClient(cfg.SSOAddr, grpc.WithTransportCredentials(creds), tracing.DialOption())
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create sso client: %w", err)
+	}
+	reservationConn, err := grpc.NewClient(cfg.ReservationAddr, grpc.WithTransportCredentials(creds), tracing.DialOption())
+	if err != nil {
+		ssoConn.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to create reservation client: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	handler.RegisterHTTPHandler(mux, log, ssopb.NewSSOClient(ssoConn), reservationpb.NewReservationClient(reservationConn), cfg.Timeout)
+
+	h := handler.WithRequestID(handler.WithLogging(log, handler.WithRecovery(log, mux)))
+	server := &http.Server{Handler: otelhttp.NewHandler(h, "gateway"), ReadHeaderTimeout: readHeaderTimeout}
+
+	return &App{server: server, conns: []*grpc.ClientConn{ssoConn, reservationConn}, log: log, stopReload: cancel}, nil
+}
+
+// Run serves until Shutdown, after which it returns nil.
+func (a *App) Run(port int) error {
+	const op = "gateway.Run"
+	log := a.log.With(slog.String("op", op))
+
+	a.server.Addr = fmt.Sprintf(":%d", port)
+	log.Info("HTTP server started", "addr", a.server.Addr)
+
+	if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (a *App) Shutdown() {
+	const op = "gateway.Shutdown"
+	log := a.log.With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := a.server.Shutdown(ctx); err != nil {
+		log.Error("failed to shutdown HTTP server", "error", err)
+	}
+	for _, conn := range a.conns {
+		conn.Close()
+	}
+	a.stopReload()
+	log.Info("HTTP server stopped")
+}
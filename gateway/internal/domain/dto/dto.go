@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+type RegisterRequest struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Name      string `json:"name"`
+	Birthdate string `json:"birthdate"`
+}
+
+type RegisterResponse struct {
+	CustomerID string `json:"customerId"`
+}
+
+type LoginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type LoginResponse struct {
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	MFARequired  bool   `json:"mfaRequired,omitempty"`
+	MFAToken     string `json:"mfaToken,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshResponse struct {
+	AccessToken string `json:"accessToken"`
+}
+
+type CreateReservationRequest struct {
+	CustomerID string    `json:"customerId"`
+	TableID    string    `json:"tableId"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+}
+
+type CreateReservationResponse struct {
+	ReservationID string `json:"reservationId"`
+}
+
+type Reservation struct {
+	ReservationID string    `json:"reservationId"`
+	CustomerID    string    `json:"customerId"`
+	TableID       string    `json:"tableId"`
+	StartTime     time.Time `json:"startTime"`
+	EndTime       time.Time `json:"endTime"`
+	Status        string    `json:"status"`
+}
+
+type StatusResponse struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	ssopb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/interceptors"
+	"github.com/SergeyBogomolovv/restaurant/common/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+type principal struct {
+	EntityID    string
+	Role        string
+	Permissions []string
+}
+
+type principalKey struct{}
+
+const requestIDHeader = "X-Request-ID"
+
+func principalFromContext(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
+
+func (p *principal) can(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// authenticated introspects the bearer token once at the edge and rejects the
+// request unless every listed permission is granted.
+func (h *httpHandler) authenticated(next http.HandlerFunc, permissions ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			utils.WriteError(w, http.StatusUnauthorized, errors.New("missing authorization"))
+			return
+		}
+
+		ctx, cancel := h.withDeadline(r)
+		defer cancel()
+		res, err := h.sso.Introspect(ctx, &ssopb.IntrospectRequest{AccessToken: token})
+		if err != nil {
+			h.writeGRPCError(w, r, err)
+			return
+		}
+
+		switch {
+		case res.Blocked:
+			utils.WriteError(w, http.StatusForbidden, errors.New("customer is blocked"))
+			return
+		case res.Fired:
+			utils.WriteError(w, http.StatusForbidden, errors.New("waiter is fired"))
+			return
+		case !res.Active:
+			utils.WriteError(w, http.StatusUnauthorized, errors.New("invalid accessToken"))
+			return
+		}
+
+		p := &principal{EntityID: res.EntityId, Role: res.Role, Permissions: res.Permissions}
+		for _, permission := range permissions {
+			if !p.can(permission) {
+				utils.WriteError(w, http.StatusForbidden, errors.New("permission denied"))
+				return
+			}
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	}
+}
+
+func forwardClientIP(ctx context.Context, r *http.Request) context.Context {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", host)
+}
+
+// WithRequestID makes sure every request carries an X-Request-ID, so that all
+// downstream calls made for it share one id, and echoes it to the client.
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+			r.Header.Set(requestIDHeader, requestID)
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func forwardRequestID(ctx context.Context, r *http.Request) context.Context {
+	if requestID := r.Header.Get(requestIDHeader); requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, interceptors.RequestIDKey, requestID)
+	}
+	return ctx
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	reservationpb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	ssopb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"google.golang.org/grpc"
+)
+
+type SSOClient interface {
+	RegisterCustomer(ctx context.Context, in *ssopb.RegisterCustomerRequest, opts ...grpc.CallOption) (*ssopb.RegisterResponse, error)
+	Login(ctx context.Context, in *ssopb.LoginRequest, opts ...grpc.CallOption) (*ssopb.LoginResponse, error)
+	Refresh(ctx context.Context, in *ssopb.RefreshRequest, opts ...grpc.CallOption) (*ssopb.RefreshResponse, error)
+	Logout(ctx context.Context, in *ssopb.LogoutRequest, opts ...grpc.CallOption) (*ssopb.LogoutResponse, error)
+	Introspect(ctx context.Context, in *ssopb.IntrospectRequest, opts ...grpc.CallOption) (*ssopb.IntrospectResponse, error)
+}
+
+type ReservationClient interface {
+	CreateReservation(ctx context.Context, in *reservationpb.CreateReservationRequest, opts ...grpc.CallOption) (*reservationpb.CreateReservationResponse, error)
+	CancelReservation(ctx context.Context, in *reservationpb.CancelReservationRequest, opts ...grpc.CallOption) (*reservationpb.CancelReservationResponse, error)
+	CloseReservation(ctx context.Context, in *reservationpb.CloseReservationRequest, opts ...grpc.CallOption) (*reservationpb.CloseReservationResponse, error)
+	GetReservation(ctx context.Context, in *reservationpb.GetReservationRequest, opts ...grpc.CallOption) (*reservationpb.ReservationInfo, error)
+	ListReservations(ctx context.Context, in *reservationpb.ListReservationsRequest, opts ...grpc.CallOption) (*reservationpb.ListReservationsResponse, error)
+}
+
+type httpHandler struct {
+	log          *slog.Logger
+	sso          SSOClient
+	reservations ReservationClient
+	timeout      time.Duration
+}
+
+// Mux is the part of http.ServeMux routes are registered with.
+type Mux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+func RegisterHTTPHandler(mux Mux, log *slog.Logger, sso SSOClient, reservations ReservationClient, timeout time.Duration) {
+	h := &httpHandler{
+		log:          log,
+		sso:          sso,
+		reservations: reservations,
+		timeout:      timeout,
+	}
+
+	mux.HandleFunc("GET /api/v1/openapi.yaml", h.OpenAPI)
+
+	mux.HandleFunc("POST /api/v1/auth/register", h.Register)
+	mux.HandleFunc("POST /api/v1/auth/login", h.Login)
+	mux.HandleFunc("POST /api/v1/auth/refresh", h.Refresh)
+	mux.HandleFunc("POST /api/v1/auth/logout", h.Logout)
+
+	mux.HandleFunc("GET /api/v1/reservations", h.authenticated(h.ListReservations))
+	mux.HandleFunc("POST /api/v1/reservations", h.authenticated(h.CreateReservation, constants.PermissionReservationsCreate))
+	mux.HandleFunc("GET /api/v1/reservations/{id}", h.authenticated(h.GetReservation))
+	mux.HandleFunc("DELETE /api/v1/reservations/{id}", h.authenticated(h.CancelReservation))
+	mux.HandleFunc("POST /api/v1/reservations/{id}/close", h.authenticated(h.CloseReservation, constants.PermissionReservationsClose))
+}
+
+// withDeadline bounds a forwarded call by the gateway timeout and passes the
+// caller's address on so downstream rate limits see the real client, along
+// with the id downstream request logs are keyed by.
+func (h *httpHandler) withDeadline(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx := forwardClientIP(r.Context(), r)
+	ctx = forwardRequestID(ctx, r)
+	return context.WithTimeout(ctx, h.timeout)
+}
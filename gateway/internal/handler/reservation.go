@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	reservationpb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/common/utils"
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/domain/dto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errPermissionDenied = errors.New("permission denied")
+	errInvalidID        = errors.New("id must be a uuid")
+)
+
+func (h *httpHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	p := principalFromContext(r.Context())
+	payload := new(dto.CreateReservationRequest)
+	if err := utils.ParseJSON(w, r, payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("invalid json body"))
+		return
+	}
+
+	customerID := payload.CustomerID
+	if p.Role == constants.RoleCustomer {
+		customerID = p.EntityID
+	}
+	if customerID == "" {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("customerId is required"))
+		return
+	}
+	if !isUUID(customerID) || !isUUID(payload.TableID) {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("customerId and tableId must be uuids"))
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	res, err := h.reservations.CreateReservation(ctx, &reservationpb.CreateReservationRequest{
+		CustomerId: customerID,
+		TableId:    payload.TableID,
+		StartTime:  payload.StartTime.Unix(),
+		EndTime:    payload.EndTime.Unix(),
+	})
+	if err != nil {
+		// The only failed precondition is an unverified email, which the
+		// customer has to fix rather than the request.
+		if st, ok := status.FromError(err); ok && st.Code() == codes.FailedPrecondition {
+			utils.WriteError(w, http.StatusForbidden, errors.New(st.Message()))
+			return
+		}
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, dto.CreateReservationResponse{ReservationID: res.ReservationId})
+}
+
+func (h *httpHandler) ListReservations(w http.ResponseWriter, r *http.Request) {
+	p := principalFromContext(r.Context())
+
+	customerID := r.URL.Query().Get("customerId")
+	if customerID == "" && p.Role == constants.RoleCustomer {
+		customerID = p.EntityID
+	}
+	if customerID == "" {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("customerId is required"))
+		return
+	}
+	if !isUUID(customerID) {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("customerId must be a uuid"))
+		return
+	}
+	if !canManageReservation(p, customerID) {
+		utils.WriteError(w, http.StatusForbidden, errPermissionDenied)
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	res, err := h.reservations.ListReservations(ctx, &reservationpb.ListReservationsRequest{CustomerId: customerID})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	reservations := make([]dto.Reservation, 0, len(res.Reservations))
+	for _, reservation := range res.Reservations {
+		reservations = append(reservations, reservationResponse(reservation))
+	}
+	utils.WriteJSON(w, http.StatusOK, reservations)
+}
+
+func (h *httpHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	p := principalFromContext(r.Context())
+	reservationID := r.PathValue("id")
+	if !isUUID(reservationID) {
+		utils.WriteError(w, http.StatusBadRequest, errInvalidID)
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	res, err := h.reservations.GetReservation(ctx, &reservationpb.GetReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+	if !canManageReservation(p, res.CustomerId) {
+		utils.WriteError(w, http.StatusForbidden, errPermissionDenied)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, reservationResponse(res))
+}
+
+func (h *httpHandler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	p := principalFromContext(r.Context())
+	reservationID := r.PathValue("id")
+	if !isUUID(reservationID) {
+		utils.WriteError(w, http.StatusBadRequest, errInvalidID)
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	reservation, err := h.reservations.GetReservation(ctx, &reservationpb.GetReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+	if !canManageReservation(p, reservation.CustomerId) {
+		utils.WriteError(w, http.StatusForbidden, errPermissionDenied)
+		return
+	}
+
+	res, err := h.reservations.CancelReservation(ctx, &reservationpb.CancelReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.StatusResponse{Status: res.Status})
+}
+
+func (h *httpHandler) CloseReservation(w http.ResponseWriter, r *http.Request) {
+	reservationID := r.PathValue("id")
+	if !isUUID(reservationID) {
+		utils.WriteError(w, http.StatusBadRequest, errInvalidID)
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	res, err := h.reservations.CloseReservation(ctx, &reservationpb.CloseReservationRequest{ReservationId: reservationID})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.StatusResponse{Status: res.Status})
+}
+
+// canManageReservation lets customers act on their own reservations and staff
+// holding reservations:close act on anyone's.
+func canManageReservation(p *principal, customerID string) bool {
+	if p.Role == constants.RoleCustomer && p.EntityID == customerID {
+		return true
+	}
+	return p.can(constants.PermissionReservationsClose)
+}
+
+// isUUID accepts only the canonical form the OpenAPI document promises, not
+// the braced or urn forms uuid.Parse also understands.
+func isUUID(id string) bool {
+	return len(id) == 36 && uuid.Validate(id) == nil
+}
+
+func reservationResponse(reservation *reservationpb.ReservationInfo) dto.Reservation {
+	return dto.Reservation{
+		ReservationID: reservation.ReservationId,
+		CustomerID:    reservation.CustomerId,
+		TableID:       reservation.TableId,
+		StartTime:     time.Unix(reservation.StartTime, 0).UTC(),
+		EndTime:       time.Unix(reservation.EndTime, 0).UTC(),
+		Status:        reservation.Status,
+	}
+}
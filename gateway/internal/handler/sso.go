@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	ssopb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/common/utils"
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/domain/dto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func (h *httpHandler) Register(w http.ResponseWriter, r *http.Request) {
+	payload := new(dto.RegisterRequest)
+	if err := utils.ParseJSON(w, r, payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("invalid json body"))
+		return
+	}
+	birthdate, err := time.Parse(time.DateOnly, payload.Birthdate)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("invalid birthdate, expected YYYY-MM-DD"))
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	var header metadata.MD
+	res, err := h.sso.RegisterCustomer(ctx, &ssopb.RegisterCustomerRequest{
+		Email:     payload.Email,
+		Password:  payload.Password,
+		Name:      payload.Name,
+		Birthdate: birthdate.Unix(),
+	}, grpc.Header(&header))
+	if err != nil {
+		forwardRetryAfter(w, header)
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, dto.RegisterResponse{CustomerID: res.EntityId})
+}
+
+func (h *httpHandler) Login(w http.ResponseWriter, r *http.Request) {
+	payload := new(dto.LoginRequest)
+	if err := utils.ParseJSON(w, r, payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("invalid json body"))
+		return
+	}
+	if payload.Role == "" {
+		payload.Role = constants.RoleCustomer
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	var header metadata.MD
+	res, err := h.sso.Login(ctx, &ssopb.LoginRequest{
+		Login:    payload.Login,
+		Password: payload.Password,
+		Role:     payload.Role,
+	}, grpc.Header(&header))
+	if err != nil {
+		forwardRetryAfter(w, header)
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.LoginResponse{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+		MFARequired:  res.MfaRequired,
+		MFAToken:     res.MfaToken,
+	})
+}
+
+func (h *httpHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	payload := new(dto.RefreshRequest)
+	if err := utils.ParseJSON(w, r, payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("invalid json body"))
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	res, err := h.sso.Refresh(ctx, &ssopb.RefreshRequest{RefreshToken: payload.RefreshToken})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.RefreshResponse{AccessToken: res.AccessToken})
+}
+
+func (h *httpHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	payload := new(dto.RefreshRequest)
+	if err := utils.ParseJSON(w, r, payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, errors.New("invalid json body"))
+		return
+	}
+
+	ctx, cancel := h.withDeadline(r)
+	defer cancel()
+	res, err := h.sso.Logout(ctx, &ssopb.LogoutRequest{RefreshToken: payload.RefreshToken})
+	if err != nil {
+		h.writeGRPCError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.StatusResponse{Status: res.Status})
+}
+
+// forwardRetryAfter passes on when a locked out client may try again.
+func forwardRetryAfter(w http.ResponseWriter, header metadata.MD) {
+	if retryAfter := header.Get("retry-after"); len(retryAfter) > 0 {
+		w.Header().Set("Retry-After", retryAfter[0])
+	}
+}
@@ -0,0 +1,388 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	reservationpb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	ssopb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testTimeout = time.Second
+
+func newTestServer() (*http.ServeMux, *mockSSOClient, *mockReservationClient) {
+	sso := new(mockSSOClient)
+	reservations := new(mockReservationClient)
+	mux := http.NewServeMux()
+	handler.RegisterHTTPHandler(mux, NewTestLogger(), sso, reservations, testTimeout)
+	return mux, sso, reservations
+}
+
+func serve(t *testing.T, mux *http.ServeMux, method, path, token, body string) *httptest.ResponseRecorder {
+	c := loadContract(t, mux)
+	assert.NoError(t, c.validateRequest(method, path, body), "request violates the openapi document")
+	return send(t, c, mux, method, path, token, body)
+}
+
+// serveInvalid sends a request the openapi document must reject as well.
+func serveInvalid(t *testing.T, mux *http.ServeMux, method, path, token, body string) *httptest.ResponseRecorder {
+	c := loadContract(t, mux)
+	assert.Error(t, c.validateRequest(method, path, body), "request unexpectedly satisfies the openapi document")
+	return send(t, c, mux, method, path, token, body)
+}
+
+func send(t *testing.T, c *contract, mux *http.ServeMux, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.NoError(t, c.validateResponse(method, path, rec), "response violates the openapi document")
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	var body map[string]any
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body
+}
+
+// withDeadline matches contexts carrying the gateway timeout.
+func withDeadline(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) <= testTimeout
+}
+
+func TestHTTPHandler_Register(t *testing.T) {
+	mux, sso, _ := newTestServer()
+
+	t.Run("success", func(t *testing.T) {
+		sso.On("RegisterCustomer", mock.MatchedBy(withDeadline), &ssopb.RegisterCustomerRequest{
+			Email:     "test@example.com",
+			Password:  "password",
+			Name:      "Test",
+			Birthdate: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC).Unix(),
+		}).Return(&ssopb.RegisterResponse{EntityId: "123"}, nil)
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/register", "", `{"email":"test@example.com","password":"password","name":"Test","birthdate":"2000-01-02"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "123", decode(t, rec)["customerId"])
+	})
+
+	t.Run("invalid birthdate", func(t *testing.T) {
+		rec := serveInvalid(t, mux, http.MethodPost, "/api/v1/auth/register", "", `{"email":"test@example.com","password":"password","name":"Test","birthdate":"02.01.2000"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		sso.On("RegisterCustomer", mock.Anything, mock.MatchedBy(func(req *ssopb.RegisterCustomerRequest) bool {
+			return req.Email == "taken@example.com"
+		})).Return(nil, status.Error(codes.AlreadyExists, "customer already exists"))
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/register", "", `{"email":"taken@example.com","password":"password","name":"Test","birthdate":"2000-01-02"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "customer already exists", decode(t, rec)["error"])
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		sso.On("RegisterCustomer", mock.Anything, mock.MatchedBy(func(req *ssopb.RegisterCustomerRequest) bool {
+			return req.Email == "locked@example.com"
+		})).Return(nil, status.Error(codes.ResourceExhausted, "too many login attempts, try again later"), metadata.Pairs("retry-after", "30"))
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/register", "", `{"email":"locked@example.com","password":"password","name":"Test","birthdate":"2000-01-02"}`)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		body := `{"email":"test@example.com","password":"password","name":"` + strings.Repeat("a", 2<<20) + `","birthdate":"2000-01-02"}`
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/register", "", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		sso.AssertNotCalled(t, "RegisterCustomer", mock.Anything, mock.MatchedBy(func(req *ssopb.RegisterCustomerRequest) bool {
+			return req.Email == "test@example.com" && len(req.Name) > 1<<20
+		}))
+	})
+}
+
+func TestHTTPHandler_Login(t *testing.T) {
+	mux, sso, _ := newTestServer()
+
+	t.Run("defaults to customer role", func(t *testing.T) {
+		sso.On("Login", mock.MatchedBy(withDeadline), &ssopb.LoginRequest{Login: "test@example.com", Password: "password", Role: constants.RoleCustomer}).
+			Return(&ssopb.LoginResponse{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/login", "", `{"login":"test@example.com","password":"password"}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := decode(t, rec)
+		assert.Equal(t, "access", body["accessToken"])
+		assert.Equal(t, "refresh", body["refreshToken"])
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		sso.On("Login", mock.Anything, &ssopb.LoginRequest{Login: "waiter", Password: "wrong", Role: constants.RoleWaiter}).
+			Return(nil, status.Error(codes.Unauthenticated, "invalid credentials"))
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/login", "", `{"login":"waiter","password":"wrong","role":"waiter"}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		sso.On("Login", mock.Anything, &ssopb.LoginRequest{Login: "locked", Password: "password", Role: constants.RoleCustomer}).
+			Return(nil, status.Error(codes.ResourceExhausted, "too many login attempts, try again later"), metadata.Pairs("retry-after", "30"))
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/auth/login", "", `{"login":"locked","password":"password"}`)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		rec := serveInvalid(t, mux, http.MethodPost, "/api/v1/auth/login", "", `{`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHTTPHandler_RefreshLogout(t *testing.T) {
+	mux, sso, _ := newTestServer()
+
+	sso.On("Refresh", mock.MatchedBy(withDeadline), &ssopb.RefreshRequest{RefreshToken: "refresh"}).Return(&ssopb.RefreshResponse{AccessToken: "access"}, nil)
+	sso.On("Refresh", mock.Anything, &ssopb.RefreshRequest{RefreshToken: "revoked"}).Return(nil, status.Error(codes.Unauthenticated, "invalid refreshToken"))
+	sso.On("Logout", mock.MatchedBy(withDeadline), &ssopb.LogoutRequest{RefreshToken: "refresh"}).Return(&ssopb.LogoutResponse{Status: "success"}, nil)
+
+	rec := serve(t, mux, http.MethodPost, "/api/v1/auth/refresh", "", `{"refreshToken":"refresh"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "access", decode(t, rec)["accessToken"])
+
+	rec = serve(t, mux, http.MethodPost, "/api/v1/auth/refresh", "", `{"refreshToken":"revoked"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(t, mux, http.MethodPost, "/api/v1/auth/logout", "", `{"refreshToken":"refresh"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "success", decode(t, rec)["status"])
+}
+
+func TestHTTPHandler_Authentication(t *testing.T) {
+	mux, sso, reservations := newTestServer()
+
+	t.Run("missing token", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations", "", "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		sso.AssertNotCalled(t, "Introspect", mock.Anything, mock.Anything)
+	})
+
+	t.Run("inactive token", func(t *testing.T) {
+		sso.On("Introspect", mock.Anything, &ssopb.IntrospectRequest{AccessToken: "expired"}).Return(&ssopb.IntrospectResponse{Active: false}, nil)
+
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations", "expired", "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("blocked customer", func(t *testing.T) {
+		sso.On("Introspect", mock.Anything, &ssopb.IntrospectRequest{AccessToken: "blocked"}).Return(&ssopb.IntrospectResponse{Active: false, Blocked: true}, nil)
+
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations", "blocked", "")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("missing permission", func(t *testing.T) {
+		sso.On("Introspect", mock.Anything, &ssopb.IntrospectRequest{AccessToken: "customer"}).
+			Return(&ssopb.IntrospectResponse{Active: true, EntityId: "6f1d3a52-8a1e-4c4f-9b1e-0d7c1c2b9a01", Role: constants.RoleCustomer}, nil)
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/reservations/2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06/close", "customer", "")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		reservations.AssertNotCalled(t, "CloseReservation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sso unavailable", func(t *testing.T) {
+		sso.On("Introspect", mock.Anything, &ssopb.IntrospectRequest{AccessToken: "any"}).Return(nil, status.Error(codes.Unavailable, "connection refused"))
+
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations", "any", "")
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestHTTPHandler_Reservations(t *testing.T) {
+	mux, sso, reservations := newTestServer()
+
+	sso.On("Introspect", mock.Anything, &ssopb.IntrospectRequest{AccessToken: "customer"}).Return(&ssopb.IntrospectResponse{
+		Active:      true,
+		EntityId:    "6f1d3a52-8a1e-4c4f-9b1e-0d7c1c2b9a01",
+		Role:        constants.RoleCustomer,
+		Permissions: []string{constants.PermissionReservationsCreate},
+	}, nil)
+	sso.On("Introspect", mock.Anything, &ssopb.IntrospectRequest{AccessToken: "waiter"}).Return(&ssopb.IntrospectResponse{
+		Active:      true,
+		EntityId:    "c3d4e5f6-a7b8-4c9d-8e0f-1a2b3c4d5e03",
+		Role:        constants.RoleWaiter,
+		Permissions: []string{constants.PermissionReservationsCreate, constants.PermissionReservationsClose},
+	}, nil)
+
+	start := time.Date(2030, 1, 1, 18, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	own := &reservationpb.ReservationInfo{ReservationId: "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06", CustomerId: "6f1d3a52-8a1e-4c4f-9b1e-0d7c1c2b9a01", TableId: "0a1b2c3d-4e5f-4a6b-9c7d-8e9f0a1b2c04", StartTime: start.Unix(), EndTime: end.Unix(), Status: constants.ReservationStatusActive}
+	foreign := &reservationpb.ReservationInfo{ReservationId: "3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07", CustomerId: "9b2e4c63-1f7a-4d2b-8c3e-5a6b7c8d9e02", TableId: "0a1b2c3d-4e5f-4a6b-9c7d-8e9f0a1b2c04", StartTime: start.Unix(), EndTime: end.Unix(), Status: constants.ReservationStatusActive}
+	reservations.On("GetReservation", mock.MatchedBy(withDeadline), &reservationpb.GetReservationRequest{ReservationId: "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06"}).Return(own, nil)
+	reservations.On("GetReservation", mock.Anything, &reservationpb.GetReservationRequest{ReservationId: "3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07"}).Return(foreign, nil)
+	reservations.On("GetReservation", mock.Anything, &reservationpb.GetReservationRequest{ReservationId: "4e5f6a7b-8c9d-4e0f-9a1b-2c3d4e5f6a08"}).Return(nil, status.Error(codes.NotFound, "reservation not found"))
+
+	t.Run("create for self", func(t *testing.T) {
+		reservations.On("CreateReservation", mock.MatchedBy(withDeadline), &reservationpb.CreateReservationRequest{
+			CustomerId: "6f1d3a52-8a1e-4c4f-9b1e-0d7c1c2b9a01",
+			TableId:    "0a1b2c3d-4e5f-4a6b-9c7d-8e9f0a1b2c04",
+			StartTime:  start.Unix(),
+			EndTime:    end.Unix(),
+		}).Return(&reservationpb.CreateReservationResponse{ReservationId: "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06"}, nil)
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/reservations", "customer", `{"customerId":"9b2e4c63-1f7a-4d2b-8c3e-5a6b7c8d9e02","tableId":"0a1b2c3d-4e5f-4a6b-9c7d-8e9f0a1b2c04","startTime":"2030-01-01T18:00:00Z","endTime":"2030-01-01T20:00:00Z"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06", decode(t, rec)["reservationId"])
+	})
+
+	t.Run("staff create requires customer", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodPost, "/api/v1/reservations", "waiter", `{"tableId":"0a1b2c3d-4e5f-4a6b-9c7d-8e9f0a1b2c04","startTime":"2030-01-01T18:00:00Z","endTime":"2030-01-01T20:00:00Z"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("table already reserved", func(t *testing.T) {
+		reservations.On("CreateReservation", mock.Anything, mock.MatchedBy(func(req *reservationpb.CreateReservationRequest) bool {
+			return req.TableId == "1b2c3d4e-5f6a-4b7c-8d9e-0f1a2b3c4d05"
+		})).Return(nil, status.Error(codes.AlreadyExists, "table already reserved"))
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/reservations", "customer", `{"tableId":"1b2c3d4e-5f6a-4b7c-8d9e-0f1a2b3c4d05","startTime":"2030-01-01T18:00:00Z","endTime":"2030-01-01T20:00:00Z"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		reservations.On("CreateReservation", mock.Anything, mock.MatchedBy(func(req *reservationpb.CreateReservationRequest) bool {
+			return req.TableId == "5f6a7b8c-9d0e-4f1a-8b2c-3d4e5f6a7b09"
+		})).Return(nil, status.Error(codes.FailedPrecondition, "customer email is not verified"))
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/reservations", "customer", `{"tableId":"5f6a7b8c-9d0e-4f1a-8b2c-3d4e5f6a7b09","startTime":"2030-01-01T18:00:00Z","endTime":"2030-01-01T20:00:00Z"}`)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, "customer email is not verified", decode(t, rec)["error"])
+	})
+
+	t.Run("list own", func(t *testing.T) {
+		reservations.On("ListReservations", mock.MatchedBy(withDeadline), &reservationpb.ListReservationsRequest{CustomerId: "6f1d3a52-8a1e-4c4f-9b1e-0d7c1c2b9a01"}).
+			Return(&reservationpb.ListReservationsResponse{Reservations: []*reservationpb.ReservationInfo{own}}, nil)
+
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations", "customer", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body []map[string]any
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		assert.Len(t, body, 1)
+		assert.Equal(t, "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06", body[0]["reservationId"])
+		assert.Equal(t, "2030-01-01T18:00:00Z", body[0]["startTime"])
+	})
+
+	t.Run("list other customer", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations?customerId=9b2e4c63-1f7a-4d2b-8c3e-5a6b7c8d9e02", "customer", "")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("get own", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations/2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06", "customer", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "0a1b2c3d-4e5f-4a6b-9c7d-8e9f0a1b2c04", decode(t, rec)["tableId"])
+	})
+
+	t.Run("get foreign", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations/3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07", "customer", "")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodGet, "/api/v1/reservations/4e5f6a7b-8c9d-4e0f-9a1b-2c3d4e5f6a08", "customer", "")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("malformed id", func(t *testing.T) {
+		for _, req := range []struct{ method, path string }{
+			{http.MethodGet, "/api/v1/reservations/res-1"},
+			{http.MethodDelete, "/api/v1/reservations/res-1"},
+			{http.MethodGet, "/api/v1/reservations?customerId=cust-1"},
+		} {
+			rec := serveInvalid(t, mux, req.method, req.path, "waiter", "")
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, "%s %s", req.method, req.path)
+		}
+		reservations.AssertNotCalled(t, "GetReservation", mock.Anything, &reservationpb.GetReservationRequest{ReservationId: "res-1"})
+	})
+
+	t.Run("create with malformed table", func(t *testing.T) {
+		rec := serveInvalid(t, mux, http.MethodPost, "/api/v1/reservations", "customer", `{"tableId":"table-1","startTime":"2030-01-01T18:00:00Z","endTime":"2030-01-01T20:00:00Z"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("cancel own", func(t *testing.T) {
+		reservations.On("CancelReservation", mock.MatchedBy(withDeadline), &reservationpb.CancelReservationRequest{ReservationId: "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06"}).
+			Return(&reservationpb.CancelReservationResponse{Status: "cancelled"}, nil)
+
+		rec := serve(t, mux, http.MethodDelete, "/api/v1/reservations/2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06", "customer", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "cancelled", decode(t, rec)["status"])
+	})
+
+	t.Run("cancel foreign", func(t *testing.T) {
+		rec := serve(t, mux, http.MethodDelete, "/api/v1/reservations/3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07", "customer", "")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		reservations.AssertNotCalled(t, "CancelReservation", mock.Anything, &reservationpb.CancelReservationRequest{ReservationId: "3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07"})
+	})
+
+	t.Run("staff cancels any", func(t *testing.T) {
+		reservations.On("CancelReservation", mock.Anything, &reservationpb.CancelReservationRequest{ReservationId: "3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07"}).
+			Return(&reservationpb.CancelReservationResponse{Status: "cancelled"}, nil)
+
+		rec := serve(t, mux, http.MethodDelete, "/api/v1/reservations/3d4e5f6a-7b8c-4d9e-8f0a-1b2c3d4e5f07", "waiter", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		reservations.On("CloseReservation", mock.MatchedBy(withDeadline), &reservationpb.CloseReservationRequest{ReservationId: "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06"}).
+			Return(&reservationpb.CloseReservationResponse{Status: "closed"}, nil)
+
+		rec := serve(t, mux, http.MethodPost, "/api/v1/reservations/2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e06/close", "waiter", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "closed", decode(t, rec)["status"])
+	})
+}
@@ -0,0 +1,46 @@
+package handler_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	h := handler.WithRequestID(handler.WithLogging(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reservations/missing", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "method=GET")
+	assert.Contains(t, buf.String(), "path=/api/v1/reservations/missing")
+	assert.Contains(t, buf.String(), "status=404")
+	assert.Contains(t, buf.String(), "request_id=req-1")
+}
+
+func TestWithRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	h := handler.WithLogging(log, handler.WithRecovery(log, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reservations", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "internal error", decode(t, rec)["error"])
+	assert.Contains(t, buf.String(), "recovered from panic")
+	assert.Contains(t, buf.String(), "status=500")
+}
@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	reservationpb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	ssopb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func NewTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type mockSSOClient struct {
+	mock.Mock
+}
+
+// sendHeader fills the grpc.Header call option with the metadata returned
+// third, the way a server sending it would.
+func sendHeader(args mock.Arguments, opts []grpc.CallOption) {
+	if len(args) < 3 {
+		return
+	}
+	for _, opt := range opts {
+		if header, ok := opt.(grpc.HeaderCallOption); ok {
+			*header.HeaderAddr = args.Get(2).(metadata.MD)
+		}
+	}
+}
+
+func (m *mockSSOClient) RegisterCustomer(ctx context.Context, in *ssopb.RegisterCustomerRequest, opts ...grpc.CallOption) (*ssopb.RegisterResponse, error) {
+	args := m.Called(ctx, in)
+	sendHeader(args, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssopb.RegisterResponse), args.Error(1)
+}
+
+func (m *mockSSOClient) Login(ctx context.Context, in *ssopb.LoginRequest, opts ...grpc.CallOption) (*ssopb.LoginResponse, error) {
+	args := m.Called(ctx, in)
+	sendHeader(args, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssopb.LoginResponse), args.Error(1)
+}
+
+func (m *mockSSOClient) Refresh(ctx context.Context, in *ssopb.RefreshRequest, opts ...grpc.CallOption) (*ssopb.RefreshResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssopb.RefreshResponse), args.Error(1)
+}
+
+func (m *mockSSOClient) Logout(ctx context.Context, in *ssopb.LogoutRequest, opts ...grpc.CallOption) (*ssopb.LogoutResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssopb.LogoutResponse), args.Error(1)
+}
+
+func (m *mockSSOClient) Introspect(ctx context.Context, in *ssopb.IntrospectRequest, opts ...grpc.CallOption) (*ssopb.IntrospectResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssopb.IntrospectResponse), args.Error(1)
+}
+
+type mockReservationClient struct {
+	mock.Mock
+}
+
+func (m *mockReservationClient) CreateReservation(ctx context.Context, in *reservationpb.CreateReservationRequest, opts ...grpc.CallOption) (*reservationpb.CreateReservationResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reservationpb.CreateReservationResponse), args.Error(1)
+}
+
+func (m *mockReservationClient) CancelReservation(ctx context.Context, in *reservationpb.CancelReservationRequest, opts ...grpc.CallOption) (*reservationpb.CancelReservationResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reservationpb.CancelReservationResponse), args.Error(1)
+}
+
+func (m *mockReservationClient) CloseReservation(ctx context.Context, in *reservationpb.CloseReservationRequest, opts ...grpc.CallOption) (*reservationpb.CloseReservationResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reservationpb.CloseReservationResponse), args.Error(1)
+}
+
+func (m *mockReservationClient) GetReservation(ctx context.Context, in *reservationpb.GetReservationRequest, opts ...grpc.CallOption) (*reservationpb.ReservationInfo, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reservationpb.ReservationInfo), args.Error(1)
+}
+
+func (m *mockReservationClient) ListReservations(ctx context.Context, in *reservationpb.ListReservationsRequest, opts ...grpc.CallOption) (*reservationpb.ListReservationsResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reservationpb.ListReservationsResponse), args.Error(1)
+}
@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/gateway/internal/handler"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForUUIDOfRFC4122))
+	openapi3.DefineStringFormatValidator("email", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForEmail))
+}
+
+// contract checks traffic against the OpenAPI document served by the gateway.
+type contract struct {
+	doc    *openapi3.T
+	router routers.Router
+}
+
+var (
+	specOnce sync.Once
+	spec     *contract
+)
+
+func loadContract(t *testing.T, mux *http.ServeMux) *contract {
+	specOnce.Do(func() {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.yaml", nil))
+		doc, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+		if err != nil {
+			t.Fatalf("failed to parse openapi document: %v", err)
+		}
+		if err := doc.Validate(context.Background()); err != nil {
+			t.Fatalf("invalid openapi document: %v", err)
+		}
+		router, err := gorillamux.NewRouter(doc)
+		if err != nil {
+			t.Fatalf("failed to route openapi document: %v", err)
+		}
+		spec = &contract{doc: doc, router: router}
+	})
+	if spec == nil {
+		t.FailNow()
+	}
+	return spec
+}
+
+func (c *contract) requestInput(method, target, body string) (*openapi3filter.RequestValidationInput, error) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	route, params, err := c.router.FindRoute(req)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+			IncludeResponseStatus: true,
+		},
+	}, nil
+}
+
+func (c *contract) validateRequest(method, target, body string) error {
+	input, err := c.requestInput(method, target, body)
+	if err != nil {
+		return err
+	}
+	return openapi3filter.ValidateRequest(context.Background(), input)
+}
+
+func (c *contract) validateResponse(method, target string, rec *httptest.ResponseRecorder) error {
+	input, err := c.requestInput(method, target, "")
+	if err != nil {
+		return err
+	}
+	return openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(strings.NewReader(rec.Body.String())),
+		Options:                input.Options,
+	})
+}
+
+// recordingMux keeps the patterns the handler registers.
+type recordingMux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (m *recordingMux) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.HandleFunc(pattern, h)
+}
+
+func TestOpenAPI_DocumentedOperationsAreRouted(t *testing.T) {
+	mux, _, _ := newTestServer()
+	c := loadContract(t, mux)
+
+	paths := c.doc.Paths.Map()
+	assert.NotEmpty(t, paths)
+	for template, item := range paths {
+		path := regexp.MustCompile(`\{[^}]+\}`).ReplaceAllString(template, "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed")
+		for method := range item.Operations() {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+			assert.NotContains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, rec.Code, "%s %s is documented but not routed", method, template)
+			assert.NoError(t, c.validateResponse(method, path, rec))
+		}
+	}
+}
+
+func TestOpenAPI_RoutedOperationsAreDocumented(t *testing.T) {
+	mux := &recordingMux{ServeMux: http.NewServeMux()}
+	handler.RegisterHTTPHandler(mux, NewTestLogger(), new(mockSSOClient), new(mockReservationClient), testTimeout)
+	c := loadContract(t, mux.ServeMux)
+
+	assert.NotEmpty(t, mux.patterns)
+	for _, pattern := range mux.patterns {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !assert.True(t, ok, "%q does not restrict the method", pattern) {
+			continue
+		}
+		item := c.doc.Paths.Value(path)
+		if assert.NotNil(t, item, "%s is routed but not documented", path) {
+			assert.NotNil(t, item.GetOperation(method), "%s is routed but not documented", pattern)
+		}
+	}
+}
+
+func TestOpenAPI_ServedDocument(t *testing.T) {
+	mux, _, _ := newTestServer()
+
+	rec := serve(t, mux, http.MethodGet, "/api/v1/openapi.yaml", "", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "openapi: 3.0.3")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/SergeyBogomolovv/restaurant/common/config"
 	"github.com/SergeyBogomolovv/restaurant/common/db"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/app"
 )
 
@@ -19,31 +21,69 @@ const (
 )
 
 func main() {
-	cfg := config.MustLoad()
-	db := db.MustConnect(cfg.PostgresURL)
-	defer db.Close()
+	cfg := new(config.ReservationConfig)
+	config.MustLoad(cfg)
 
 	logger := setupLogger(cfg.Env)
 	logger = logger.With(slog.String("env", cfg.Env))
 
-	app := app.New(logger, db)
-	go app.Run(cfg.Reservation.Port)
+	if err := run(cfg, logger); err != nil {
+		logger.Error("reservation stopped", "error", err)
+		os.Exit(1)
+	}
+}
 
+// run returns instead of exiting, so the deferred cleanup always happens.
+func run(cfg *config.ReservationConfig, logger *slog.Logger) error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
-	<-ctx.Done()
-	app.Shutdown()
+	shutdownTracing, err := tracing.Setup(context.Background(), "reservation", cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	pg, err := db.Open(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	app, err := app.New(logger, pg, cfg.Reservation)
+	if err != nil {
+		return err
+	}
+
+	// Serving before Postgres answers lets health checks see NOT_SERVING
+	// instead of a refused connection while it is retried.
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.Run(cfg.Reservation.Port)
+	}()
+	defer app.Shutdown()
+
+	if err := db.Wait(ctx, logger, pg, cfg.Postgres.Retry); err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	app.StartHealthChecks(ctx)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serveErr:
+		return fmt.Errorf("gRPC server failed: %w", err)
+	}
 }
 
 func setupLogger(env string) (logger *slog.Logger) {
 	switch env {
 	case envLocal:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		logger = slog.New(tracing.NewLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	case envDev:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		logger = slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	case envProd:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		logger = slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
 	}
 	return
 }
@@ -7,52 +7,110 @@ import (
 	"net"
 	"time"
 
+	pb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	"github.com/SergeyBogomolovv/restaurant/common/certs"
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/health"
+	"github.com/SergeyBogomolovv/restaurant/common/interceptors"
+	"github.com/SergeyBogomolovv/restaurant/common/metrics"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/handler"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/repo"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/usecase"
 	"github.com/jmoiron/sqlx"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+	shutdownTimeout     = 10 * time.Second
 )
 
 type App struct {
 	server     *grpc.Server
+	health     *health.Checker
+	metrics    *metrics.Server
 	log        *slog.Logger
 	stopTicker context.CancelFunc
 }
 
-func New(log *slog.Logger, db *sqlx.DB) *App {
-	server := grpc.NewServer()
-
-	repo := repo.NewReservationRepo(db)
+func New(log *slog.Logger, db *sqlx.DB, cfg config.ReservationService) (*App, error) {
+	metrics.RegisterDB(db)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	usecase := usecase.NewReservationUsecase(log, repo, ctx, time.Hour)
+	creds, err := certs.ServerCredentials(ctx, log, cfg.TLS)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
+	}
+
+	server := grpc.NewServer(grpc.Creds(creds), tracing.ServerOption(), grpc.ChainUnaryInterceptor(
+		interceptors.Logging(log),
+		metrics.UnaryServerInterceptor(),
+		interceptors.Timeout(cfg.Timeout, cfg.Timeouts),
+		interceptors.Recovery(log),
+	))
+
+	repo := repo.NewReservationRepo(db)
+	usecase := usecase.NewReservationUsecase(log, repo, ctx, cfg.SweepInterval, cfg.RequireVerifiedEmail)
 
 	handler.RegisterGRPCHandler(server, usecase)
 
-	return &App{server: server, log: log, stopTicker: cancel}
+	checker := health.NewChecker(log, healthCheckInterval, map[string]health.Check{"postgres": health.DBCheck(db)}, pb.Reservation_ServiceDesc.ServiceName)
+	healthpb.RegisterHealthServer(server, checker.Server())
+
+	return &App{
+		server:     server,
+		health:     checker,
+		metrics:    metrics.NewServer(log, cfg.MetricsPort),
+		log:        log,
+		stopTicker: cancel,
+	}, nil
 }
 
-func (a *App) Run(port int) {
+// StartHealthChecks reports the service as serving once Postgres answers.
+// Call it after waiting for it, until then it is NOT_SERVING.
+func (a *App) StartHealthChecks(ctx context.Context) {
+	go a.health.Run(ctx)
+}
+
+func (a *App) Run(port int) error {
 	const op = "reservation.Run"
 	log := a.log.With(slog.String("op", op))
 
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to listen: %w", err)
 	}
 
+	metricsErr := make(chan error, 1)
+	go func() {
+		if err := a.metrics.Run(); err != nil {
+			metricsErr <- err
+			a.server.Stop()
+		}
+	}()
 	log.Info("gRPC server started", "addr", listener.Addr().String())
 
-	if err := a.server.Serve(listener); err != nil {
-		panic(err)
+	serveErr := a.server.Serve(listener)
+	select {
+	case err := <-metricsErr:
+		return err
+	default:
+		return serveErr
 	}
 }
 
 func (a *App) Shutdown() {
 	const op = "reservation.Shutdown"
 	log := a.log.With(slog.String("op", op))
+	a.health.Shutdown()
 	a.server.GracefulStop()
 	a.stopTicker()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	a.metrics.Shutdown(ctx)
 	log.Info("gRPC server stopped")
 }
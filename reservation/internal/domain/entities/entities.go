@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationEntity struct {
+	ReservationID uuid.UUID `db:"reservation_id"`
+	CustomerID    uuid.UUID `db:"customer_id"`
+	TableID       uuid.UUID `db:"table_id"`
+	StartTime     time.Time `db:"start_time"`
+	EndTime       time.Time `db:"end_time"`
+	Status        string    `db:"status"`
+}
@@ -6,4 +6,5 @@ var (
 	ErrReservationNotFound  = errors.New("reservation not found")
 	ErrTableAlreadyReserved = errors.New("table already reserved")
 	ErrTableNotFound        = errors.New("table not found")
+	ErrCustomerNotVerified  = errors.New("customer email not verified")
 )
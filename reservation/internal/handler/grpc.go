@@ -7,6 +7,7 @@ import (
 
 	pb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/errors"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ type ReservationUsecase interface {
 	CreateReservation(ctx context.Context, dto *dto.CreateReservationDTO) (uuid.UUID, error)
 	CancelReservation(ctx context.Context, reservationId uuid.UUID) error
 	CloseReservation(ctx context.Context, reservationId uuid.UUID) error
+	GetReservation(ctx context.Context, reservationId uuid.UUID) (*entities.ReservationEntity, error)
+	ListCustomerReservations(ctx context.Context, customerId uuid.UUID) ([]*entities.ReservationEntity, error)
 }
 
 type reservationHandler struct {
@@ -66,6 +69,8 @@ func (h *reservationHandler) CreateReservation(ctx context.Context, req *pb.Crea
 			return nil, status.Error(codes.AlreadyExists, "table already reserved")
 		case errors.Is(err, errs.ErrTableNotFound):
 			return nil, status.Error(codes.NotFound, "table not found")
+		case errors.Is(err, errs.ErrCustomerNotVerified):
+			return nil, status.Error(codes.FailedPrecondition, "customer email is not verified")
 		default:
 			return nil, status.Error(codes.Internal, "failed to create reservation")
 		}
@@ -109,3 +114,51 @@ func (h *reservationHandler) CloseReservation(ctx context.Context, req *pb.Close
 
 	return &pb.CloseReservationResponse{Status: "closed"}, nil
 }
+
+func (h *reservationHandler) GetReservation(ctx context.Context, req *pb.GetReservationRequest) (*pb.ReservationInfo, error) {
+	reservationId, err := uuid.Parse(req.ReservationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reservationId")
+	}
+
+	reservation, err := h.reservationUsecase.GetReservation(ctx, reservationId)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrReservationNotFound):
+			return nil, status.Error(codes.NotFound, "reservation not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to get reservation")
+		}
+	}
+
+	return reservationResponse(reservation), nil
+}
+
+func (h *reservationHandler) ListReservations(ctx context.Context, req *pb.ListReservationsRequest) (*pb.ListReservationsResponse, error) {
+	customerId, err := uuid.Parse(req.CustomerId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid customerId")
+	}
+
+	reservations, err := h.reservationUsecase.ListCustomerReservations(ctx, customerId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list reservations")
+	}
+
+	res := &pb.ListReservationsResponse{Reservations: make([]*pb.ReservationInfo, 0, len(reservations))}
+	for _, reservation := range reservations {
+		res.Reservations = append(res.Reservations, reservationResponse(reservation))
+	}
+	return res, nil
+}
+
+func reservationResponse(reservation *entities.ReservationEntity) *pb.ReservationInfo {
+	return &pb.ReservationInfo{
+		ReservationId: reservation.ReservationID.String(),
+		CustomerId:    reservation.CustomerID.String(),
+		TableId:       reservation.TableID.String(),
+		StartTime:     reservation.StartTime.Unix(),
+		EndTime:       reservation.EndTime.Unix(),
+		Status:        reservation.Status,
+	}
+}
@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/SergeyBogomolovv/restaurant/common/api/gen/reservation"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/handler"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockReservationUsecase struct {
+	mock.Mock
+}
+
+func (m *mockReservationUsecase) CreateReservation(ctx context.Context, dto *dto.CreateReservationDTO) (uuid.UUID, error) {
+	args := m.Called(ctx, dto)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *mockReservationUsecase) CancelReservation(ctx context.Context, reservationId uuid.UUID) error {
+	return m.Called(ctx, reservationId).Error(0)
+}
+
+func (m *mockReservationUsecase) CloseReservation(ctx context.Context, reservationId uuid.UUID) error {
+	return m.Called(ctx, reservationId).Error(0)
+}
+
+func (m *mockReservationUsecase) GetReservation(ctx context.Context, reservationId uuid.UUID) (*entities.ReservationEntity, error) {
+	args := m.Called(ctx, reservationId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ReservationEntity), args.Error(1)
+}
+
+func (m *mockReservationUsecase) ListCustomerReservations(ctx context.Context, customerId uuid.UUID) ([]*entities.ReservationEntity, error) {
+	args := m.Called(ctx, customerId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.ReservationEntity), args.Error(1)
+}
+
+func newTestClient(t *testing.T, usecase handler.ReservationUsecase) pb.ReservationClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	handler.RegisterGRPCHandler(server, usecase)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewReservationClient(conn)
+}
+
+func TestReservationHandler_CreateReservation(t *testing.T) {
+	ctx := context.Background()
+	usecase := new(mockReservationUsecase)
+	client := newTestClient(t, usecase)
+
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	req := &pb.CreateReservationRequest{
+		CustomerId: uuid.NewString(),
+		TableId:    uuid.NewString(),
+		StartTime:  start.Unix(),
+		EndTime:    start.Add(2 * time.Hour).Unix(),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Cleanup(func() {
+			usecase.ExpectedCalls = nil
+			usecase.Calls = nil
+		})
+		reservationID := uuid.New()
+		usecase.On("CreateReservation", mock.Anything, mock.Anything).Return(reservationID, nil)
+
+		res, err := client.CreateReservation(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, reservationID.String(), res.ReservationId)
+	})
+
+	t.Run("customer not verified", func(t *testing.T) {
+		t.Cleanup(func() {
+			usecase.ExpectedCalls = nil
+			usecase.Calls = nil
+		})
+		usecase.On("CreateReservation", mock.Anything, mock.Anything).Return(uuid.Nil, errs.ErrCustomerNotVerified)
+
+		res, err := client.CreateReservation(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, "customer email is not verified", status.Convert(err).Message())
+	})
+}
@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const ActionCreated = "created"
+
+var (
+	Reservations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "reservation_reservations_total",
+		Help: "Reservations created, cancelled and closed on request.",
+	}, []string{"action"})
+
+	EndedReservationsClosed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reservation_ended_closed_total",
+		Help: "Reservations closed by the ticker after their end time.",
+	})
+)
@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/errors"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -31,6 +32,17 @@ func (r *reservationRepo) GetTableExists(ctx context.Context, tableID uuid.UUID)
 	return isExists, nil
 }
 
+func (r *reservationRepo) GetCustomerVerified(ctx context.Context, customerID uuid.UUID) (bool, error) {
+	var isVerified bool
+	if err := r.db.GetContext(ctx, &isVerified, "SELECT email_verified_at IS NOT NULL FROM customers WHERE customer_id = $1", customerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return isVerified, nil
+}
+
 func (r *reservationRepo) CreateReservation(ctx context.Context, dto *dto.CreateReservationDTO) (uuid.UUID, error) {
 	var id uuid.UUID
 	query := `INSERT INTO reservations (customer_id, table_id, start_time, end_time) VALUES ($1, $2, $3, $4) RETURNING reservation_id`
@@ -45,6 +57,27 @@ func (r *reservationRepo) CreateReservation(ctx context.Context, dto *dto.Create
 	return id, nil
 }
 
+func (r *reservationRepo) GetReservation(ctx context.Context, reservationID uuid.UUID) (*entities.ReservationEntity, error) {
+	reservation := new(entities.ReservationEntity)
+	query := `SELECT reservation_id, customer_id, table_id, start_time, end_time, status FROM reservations WHERE reservation_id = $1`
+	if err := r.db.GetContext(ctx, reservation, query, reservationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrReservationNotFound
+		}
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepo) ListCustomerReservations(ctx context.Context, customerID uuid.UUID) ([]*entities.ReservationEntity, error) {
+	reservations := make([]*entities.ReservationEntity, 0)
+	query := `SELECT reservation_id, customer_id, table_id, start_time, end_time, status FROM reservations WHERE customer_id = $1 ORDER BY start_time DESC`
+	if err := r.db.SelectContext(ctx, &reservations, query, customerID); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
 func (r *reservationRepo) SetReservationStatus(ctx context.Context, reservationID uuid.UUID, status string) error {
 	query := `UPDATE reservations SET status = $1 WHERE reservation_id = $2`
 	res, err := r.db.ExecContext(ctx, query, status, reservationID)
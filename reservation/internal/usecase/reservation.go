@@ -8,24 +8,30 @@ import (
 
 	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/metrics"
 	"github.com/google/uuid"
 )
 
 type Repo interface {
 	CreateReservation(ctx context.Context, dto *dto.CreateReservationDTO) (uuid.UUID, error)
+	GetReservation(ctx context.Context, reservationID uuid.UUID) (*entities.ReservationEntity, error)
+	ListCustomerReservations(ctx context.Context, customerID uuid.UUID) ([]*entities.ReservationEntity, error)
 	SetReservationStatus(ctx context.Context, reservationID uuid.UUID, status string) error
 	CloseEndedReservations(ctx context.Context) (int64, error)
 	GetTableExists(ctx context.Context, tableID uuid.UUID) (bool, error)
+	GetCustomerVerified(ctx context.Context, customerID uuid.UUID) (bool, error)
 }
 
 type reservationUsecase struct {
-	log  *slog.Logger
-	repo Repo
+	log                  *slog.Logger
+	repo                 Repo
+	requireVerifiedEmail bool
 }
 
-func NewReservationUsecase(log *slog.Logger, repo Repo, ctx context.Context, tickerDuration time.Duration) *reservationUsecase {
-	usecase := &reservationUsecase{log: log, repo: repo}
+func NewReservationUsecase(log *slog.Logger, repo Repo, ctx context.Context, tickerDuration time.Duration, requireVerifiedEmail bool) *reservationUsecase {
+	usecase := &reservationUsecase{log: log, repo: repo, requireVerifiedEmail: requireVerifiedEmail}
 	go usecase.CheckEndedReservations(ctx, tickerDuration)
 	return usecase
 }
@@ -34,36 +40,77 @@ func (u *reservationUsecase) CreateReservation(ctx context.Context, dto *dto.Cre
 	const op = "reservation.Create"
 	log := u.log.With(slog.String("op", op))
 
-	log.Info("creating reservation")
+	log.InfoContext(ctx, "creating reservation")
+
+	if u.requireVerifiedEmail {
+		isVerified, err := u.repo.GetCustomerVerified(ctx, dto.CustomerID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to check customer verified", "error", err)
+			return uuid.Nil, err
+		}
+		if !isVerified {
+			log.InfoContext(ctx, "customer email not verified")
+			return uuid.Nil, errs.ErrCustomerNotVerified
+		}
+	}
 
 	tableExists, err := u.repo.GetTableExists(ctx, dto.TableID)
 	if err != nil {
-		log.Error("failed to check table exists", "error", err)
+		log.ErrorContext(ctx, "failed to check table exists", "error", err)
 		return uuid.Nil, err
 	}
 	if !tableExists {
-		log.Info("table not found")
+		log.InfoContext(ctx, "table not found")
 		return uuid.Nil, errs.ErrTableNotFound
 	}
 
 	id, err := u.repo.CreateReservation(ctx, dto)
 	if err != nil {
 		if errors.Is(err, errs.ErrTableAlreadyReserved) {
-			log.Info("table already reserved")
+			log.InfoContext(ctx, "table already reserved")
 			return uuid.Nil, errs.ErrTableAlreadyReserved
 		}
-		log.Error("failed to create reservation", "error", err)
+		log.ErrorContext(ctx, "failed to create reservation", "error", err)
 		return uuid.Nil, err
 	}
 
+	metrics.Reservations.WithLabelValues(metrics.ActionCreated).Inc()
 	return id, nil
 }
 
+func (u *reservationUsecase) GetReservation(ctx context.Context, reservationId uuid.UUID) (*entities.ReservationEntity, error) {
+	const op = "reservation.Get"
+	log := u.log.With(slog.String("op", op), slog.String("reservationId", reservationId.String()))
+
+	reservation, err := u.repo.GetReservation(ctx, reservationId)
+	if err != nil {
+		if errors.Is(err, errs.ErrReservationNotFound) {
+			log.InfoContext(ctx, "reservation not found")
+			return nil, errs.ErrReservationNotFound
+		}
+		log.ErrorContext(ctx, "failed to get reservation", "error", err)
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (u *reservationUsecase) ListCustomerReservations(ctx context.Context, customerId uuid.UUID) ([]*entities.ReservationEntity, error) {
+	const op = "reservation.ListCustomer"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", customerId.String()))
+
+	reservations, err := u.repo.ListCustomerReservations(ctx, customerId)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to list reservations", "error", err)
+		return nil, err
+	}
+	return reservations, nil
+}
+
 func (u *reservationUsecase) CheckEndedReservations(ctx context.Context, duration time.Duration) {
 	const op = "reservation.CheckEndedReservations"
 	log := u.log.With(slog.String("op", op))
 
-	log.Info("reservations checker started")
+	log.InfoContext(ctx, "reservations checker started")
 
 	for {
 		now := time.Now()
@@ -74,15 +121,16 @@ func (u *reservationUsecase) CheckEndedReservations(ctx context.Context, duratio
 		select {
 		case <-ctx.Done():
 			timer.Stop()
-			log.Info("check closed reservations stopped")
+			log.InfoContext(ctx, "check closed reservations stopped")
 			return
 		case <-timer.C:
 			count, err := u.repo.CloseEndedReservations(ctx)
 			if err != nil {
-				log.Error("failed to check closed reservations", "error", err)
+				log.ErrorContext(ctx, "failed to check closed reservations", "error", err)
 			}
 			if count > 0 {
-				log.Info("closed reservations", "count", count)
+				log.InfoContext(ctx, "closed reservations", "count", count)
+				metrics.EndedReservationsClosed.Add(float64(count))
 			}
 		}
 	}
@@ -92,19 +140,21 @@ func (u *reservationUsecase) CancelReservation(ctx context.Context, reservationI
 	const op = "reservation.Cancel"
 	log := u.log.With(slog.String("op", op))
 
-	log.Info("cancelling reservation")
+	log.InfoContext(ctx, "cancelling reservation")
 
 	//TODO: check is admin or current user
 
 	if err := u.repo.SetReservationStatus(ctx, reservationId, constants.ReservationStatusCancelled); err != nil {
 		if errors.Is(err, errs.ErrReservationNotFound) {
-			log.Info("reservation not found")
+			log.InfoContext(ctx, "reservation not found")
 			return errs.ErrReservationNotFound
 		}
-		log.Error("failed to cancel reservation", "error", err)
+		log.ErrorContext(ctx, "failed to cancel reservation", "error", err)
 		return err
 	}
 
+	metrics.Reservations.WithLabelValues(constants.ReservationStatusCancelled).Inc()
+
 	// TODO: send rmq
 
 	return nil
@@ -114,19 +164,21 @@ func (u *reservationUsecase) CloseReservation(ctx context.Context, reservationId
 	const op = "reservation.Close"
 	log := u.log.With(slog.String("op", op))
 
-	log.Info("closing reservation")
+	log.InfoContext(ctx, "closing reservation")
 
 	//TODO: check is admin or waiter
 
 	if err := u.repo.SetReservationStatus(ctx, reservationId, constants.ReservationStatusClosed); err != nil {
 		if errors.Is(err, errs.ErrReservationNotFound) {
-			log.Info("reservation not found")
+			log.InfoContext(ctx, "reservation not found")
 			return errs.ErrReservationNotFound
 		}
-		log.Error("failed to close reservation", "error", err)
+		log.ErrorContext(ctx, "failed to close reservation", "error", err)
 		return err
 	}
 
+	metrics.Reservations.WithLabelValues(constants.ReservationStatusClosed).Inc()
+
 	// TODO: send rmq
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/entities"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +24,22 @@ func (m *mockReservationRepo) CreateReservation(ctx context.Context, dto *dto.Cr
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
+func (m *mockReservationRepo) GetReservation(ctx context.Context, reservationID uuid.UUID) (*entities.ReservationEntity, error) {
+	args := m.Called(ctx, reservationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ReservationEntity), args.Error(1)
+}
+
+func (m *mockReservationRepo) ListCustomerReservations(ctx context.Context, customerID uuid.UUID) ([]*entities.ReservationEntity, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.ReservationEntity), args.Error(1)
+}
+
 func (m *mockReservationRepo) SetReservationStatus(ctx context.Context, reservationID uuid.UUID, status string) error {
 	args := m.Called(ctx, reservationID, status)
 	return args.Error(0)
@@ -37,3 +54,8 @@ func (m *mockReservationRepo) GetTableExists(ctx context.Context, tableID uuid.U
 	args := m.Called(ctx, tableID)
 	return args.Get(0).(bool), args.Error(1)
 }
+
+func (m *mockReservationRepo) GetCustomerVerified(ctx context.Context, customerID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, customerID)
+	return args.Get(0).(bool), args.Error(1)
+}
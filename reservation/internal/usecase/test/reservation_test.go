@@ -7,20 +7,27 @@ import (
 
 	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/reservation/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/reservation/internal/metrics"
 	"github.com/SergeyBogomolovv/restaurant/reservation/internal/usecase"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func reservationCount(action string) float64 {
+	return testutil.ToFloat64(metrics.Reservations.WithLabelValues(action))
+}
+
 func TestReservationUsecase_CreateReservation(t *testing.T) {
 	ctx := context.Background()
 	logger := NewTestLogger()
 	mockRepo := new(mockReservationRepo)
 	mockRepo.On("CloseEndedReservations", ctx).Return(int64(0), nil)
 
-	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour)
+	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour, false)
 
 	t.Run("success", func(t *testing.T) {
 		tableId := uuid.New()
@@ -33,11 +40,13 @@ func TestReservationUsecase_CreateReservation(t *testing.T) {
 		mockRepo.On("GetTableExists", ctx, tableId).Return(true, nil)
 		resultId := uuid.New()
 		mockRepo.On("CreateReservation", ctx, dto).Return(resultId, nil)
+		before := reservationCount(metrics.ActionCreated)
 
 		reservationID, err := usecase.CreateReservation(ctx, dto)
 
 		assert.NoError(t, err)
 		assert.Equal(t, reservationID, resultId)
+		assert.Equal(t, before+1, reservationCount(metrics.ActionCreated))
 	})
 
 	t.Run("table not found", func(t *testing.T) {
@@ -67,11 +76,59 @@ func TestReservationUsecase_CreateReservation(t *testing.T) {
 		}
 		mockRepo.On("GetTableExists", ctx, tableId).Return(true, nil)
 		mockRepo.On("CreateReservation", ctx, dto).Return(uuid.Nil, errs.ErrTableAlreadyReserved)
+		before := reservationCount(metrics.ActionCreated)
 
 		id, err := usecase.CreateReservation(ctx, dto)
 
 		assert.Equal(t, id, uuid.Nil)
 		assert.ErrorIs(t, err, errs.ErrTableAlreadyReserved)
+		assert.Equal(t, before, reservationCount(metrics.ActionCreated))
+	})
+}
+
+func TestReservationUsecase_CreateReservationRequireVerified(t *testing.T) {
+	ctx := context.Background()
+	logger := NewTestLogger()
+	mockRepo := new(mockReservationRepo)
+	mockRepo.On("CloseEndedReservations", ctx).Return(int64(0), nil)
+
+	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour, true)
+
+	t.Run("verified", func(t *testing.T) {
+		customerId := uuid.New()
+		tableId := uuid.New()
+		dto := &dto.CreateReservationDTO{
+			CustomerID: customerId,
+			TableID:    tableId,
+			StartTime:  time.Unix(1730455200, 0),
+			EndTime:    time.Unix(1730458800, 0),
+		}
+		mockRepo.On("GetCustomerVerified", ctx, customerId).Return(true, nil)
+		mockRepo.On("GetTableExists", ctx, tableId).Return(true, nil)
+		resultId := uuid.New()
+		mockRepo.On("CreateReservation", ctx, dto).Return(resultId, nil)
+
+		reservationID, err := usecase.CreateReservation(ctx, dto)
+
+		assert.NoError(t, err)
+		assert.Equal(t, reservationID, resultId)
+	})
+
+	t.Run("not verified", func(t *testing.T) {
+		customerId := uuid.New()
+		dto := &dto.CreateReservationDTO{
+			CustomerID: customerId,
+			TableID:    uuid.New(),
+			StartTime:  time.Unix(1730455200, 0),
+			EndTime:    time.Unix(1730458800, 0),
+		}
+		mockRepo.On("GetCustomerVerified", ctx, customerId).Return(false, nil)
+
+		id, err := usecase.CreateReservation(ctx, dto)
+
+		assert.Equal(t, id, uuid.Nil)
+		assert.ErrorIs(t, err, errs.ErrCustomerNotVerified)
+		mockRepo.AssertNotCalled(t, "CreateReservation", ctx, dto)
 	})
 }
 
@@ -81,15 +138,17 @@ func TestReservationUsecase_CancelReservation(t *testing.T) {
 	mockRepo := new(mockReservationRepo)
 	mockRepo.On("CloseEndedReservations", ctx).Return(int64(0), nil)
 
-	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour)
+	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour, false)
 
 	t.Run("succes", func(t *testing.T) {
 		id := uuid.New()
 		mockRepo.On("SetReservationStatus", ctx, id, constants.ReservationStatusCancelled).Return(nil)
+		before := reservationCount(constants.ReservationStatusCancelled)
 
 		err := usecase.CancelReservation(ctx, id)
 
 		assert.NoError(t, err)
+		assert.Equal(t, before+1, reservationCount(constants.ReservationStatusCancelled))
 	})
 
 	t.Run("reservation not found", func(t *testing.T) {
@@ -108,13 +167,15 @@ func TestReservationUsecase_CloseReservation(t *testing.T) {
 	mockRepo := new(mockReservationRepo)
 	mockRepo.On("CloseEndedReservations", ctx).Return(int64(0), nil)
 
-	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour)
+	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour, false)
 
 	t.Run("success", func(t *testing.T) {
 		id := uuid.New()
 		mockRepo.On("SetReservationStatus", ctx, id, constants.ReservationStatusClosed).Return(nil)
+		before := reservationCount(constants.ReservationStatusClosed)
 		err := usecase.CloseReservation(ctx, id)
 		assert.NoError(t, err)
+		assert.Equal(t, before+1, reservationCount(constants.ReservationStatusClosed))
 	})
 
 	t.Run("reservation not found", func(t *testing.T) {
@@ -125,13 +186,64 @@ func TestReservationUsecase_CloseReservation(t *testing.T) {
 	})
 }
 
+func TestReservationUsecase_GetReservation(t *testing.T) {
+	ctx := context.Background()
+	logger := NewTestLogger()
+	mockRepo := new(mockReservationRepo)
+	mockRepo.On("CloseEndedReservations", ctx).Return(int64(0), nil)
+
+	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour, false)
+
+	t.Run("success", func(t *testing.T) {
+		id := uuid.New()
+		reservation := &entities.ReservationEntity{ReservationID: id, CustomerID: uuid.New(), Status: constants.ReservationStatusActive}
+		mockRepo.On("GetReservation", ctx, id).Return(reservation, nil)
+
+		result, err := usecase.GetReservation(ctx, id)
+
+		assert.NoError(t, err)
+		assert.Equal(t, reservation, result)
+	})
+
+	t.Run("reservation not found", func(t *testing.T) {
+		id := uuid.New()
+		mockRepo.On("GetReservation", ctx, id).Return(nil, errs.ErrReservationNotFound)
+
+		result, err := usecase.GetReservation(ctx, id)
+
+		assert.ErrorIs(t, err, errs.ErrReservationNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestReservationUsecase_ListCustomerReservations(t *testing.T) {
+	ctx := context.Background()
+	logger := NewTestLogger()
+	mockRepo := new(mockReservationRepo)
+	mockRepo.On("CloseEndedReservations", ctx).Return(int64(0), nil)
+
+	usecase := usecase.NewReservationUsecase(logger, mockRepo, ctx, time.Hour, false)
+
+	customerId := uuid.New()
+	reservations := []*entities.ReservationEntity{
+		{ReservationID: uuid.New(), CustomerID: customerId, Status: constants.ReservationStatusActive},
+		{ReservationID: uuid.New(), CustomerID: customerId, Status: constants.ReservationStatusClosed},
+	}
+	mockRepo.On("ListCustomerReservations", ctx, customerId).Return(reservations, nil)
+
+	result, err := usecase.ListCustomerReservations(ctx, customerId)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reservations, result)
+}
+
 func TestReservationUsecase_CheckEndedReservations(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	logger := NewTestLogger()
 	mockRepo := new(mockReservationRepo)
 	tickerDuration := 50 * time.Millisecond
-	usecase.NewReservationUsecase(logger, mockRepo, ctx, tickerDuration)
+	usecase.NewReservationUsecase(logger, mockRepo, ctx, tickerDuration, false)
 
 	mockRepo.On("CloseEndedReservations", mock.Anything).Return(int64(2), nil)
 	done := make(chan struct{})
@@ -142,4 +254,5 @@ func TestReservationUsecase_CheckEndedReservations(t *testing.T) {
 	}()
 	<-done
 	mockRepo.AssertCalled(t, "CloseEndedReservations", mock.Anything)
+	assert.GreaterOrEqual(t, testutil.ToFloat64(metrics.EndedReservationsClosed), float64(2))
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/db"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/passwords"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/repo"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run returns the exit code instead of exiting, so the deferred cleanup
+// always happens.
+func run() int {
+	login := flag.String("login", "", "login of the first admin")
+	password := flag.String("password", os.Getenv("ADMIN_PASSWORD"), "password of the first admin (defaults to $ADMIN_PASSWORD)")
+	note := flag.String("note", "", "optional note for the first admin")
+
+	cfg := new(config.SSOConfig)
+	config.MustLoad(cfg)
+	if *login == "" || *password == "" {
+		fmt.Fprintln(os.Stderr, "login and password are required")
+		flag.Usage()
+		return 2
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	pg, err := db.Connect(context.Background(), logger, cfg.Postgres)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer pg.Close()
+
+	hasher, err := passwords.NewHasher(cfg.SSO.Password)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	policy, err := passwords.NewPolicy(cfg.SSO.Password)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	register := usecase.NewRegisterUsecase(logger, repo.NewIdentityRepo(pg), nil, nil, repo.NewAdminRepo(pg), nil, nil, nil, hasher, policy)
+
+	id, err := register.BootstrapAdmin(context.Background(), &dto.BootstrapAdminDTO{
+		Login:    *login,
+		Password: *password,
+		Note:     *note,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrAlreadyBootstrapped) {
+			fmt.Fprintln(os.Stderr, "an admin already exists, use invitations to add more")
+			return 1
+		}
+		if errors.Is(err, errs.ErrWeakPassword) {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "failed to bootstrap admin:", err)
+		return 1
+	}
+
+	fmt.Println(id.String())
+	return 0
+}
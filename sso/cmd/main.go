@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -10,6 +11,7 @@ import (
 	"github.com/SergeyBogomolovv/restaurant/common/config"
 	"github.com/SergeyBogomolovv/restaurant/common/db"
 	"github.com/SergeyBogomolovv/restaurant/common/redis"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/app"
 )
 
@@ -20,33 +22,77 @@ const (
 )
 
 func main() {
-	cfg := config.MustLoad()
-	db := db.MustConnect(cfg.PostgresURL)
-	redis := redis.MustConnect(cfg.RedisURL)
-	defer redis.Close()
-	defer db.Close()
+	cfg := new(config.SSOConfig)
+	config.MustLoad(cfg)
 
 	logger := setupLogger(cfg.Env)
 	logger = logger.With(slog.String("env", cfg.Env))
 
-	app := app.New(logger, db, redis, cfg.Jwt, cfg.SSO.SecretKey)
-	go app.Run(cfg.SSO.Port)
+	if err := run(cfg, logger); err != nil {
+		logger.Error("sso stopped", "error", err)
+		os.Exit(1)
+	}
+}
 
+// run returns instead of exiting, so the deferred cleanup always happens.
+func run(cfg *config.SSOConfig, logger *slog.Logger) error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
-	<-ctx.Done()
-	app.Shutdown()
+	shutdownTracing, err := tracing.Setup(context.Background(), "sso", cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	pg, err := db.Open(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+	rdb, err := redis.Open(cfg.Redis)
+	if err != nil {
+		return err
+	}
+	defer rdb.Close()
+
+	app, err := app.New(logger, pg, rdb, cfg.Jwt, cfg.SSO)
+	if err != nil {
+		return err
+	}
+
+	// Serving before the dependencies answer lets health checks see NOT_SERVING
+	// instead of a refused connection while they are retried.
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.Run(cfg.SSO.Port)
+	}()
+	defer app.Shutdown()
+
+	if err := db.Wait(ctx, logger, pg, cfg.Postgres.Retry); err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	if err := redis.Wait(ctx, logger, rdb, cfg.Redis.Retry); err != nil {
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	app.StartHealthChecks(ctx)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serveErr:
+		return fmt.Errorf("gRPC server failed: %w", err)
+	}
 }
 
 func setupLogger(env string) (logger *slog.Logger) {
 	switch env {
 	case envLocal:
-		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		logger = slog.New(tracing.NewLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	case envDev:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		logger = slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	case envProd:
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		logger = slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
 	}
 	return
 }
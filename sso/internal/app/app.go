@@ -1,58 +1,181 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
+	"time"
 
+	pb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/certs"
 	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/health"
+	"github.com/SergeyBogomolovv/restaurant/common/interceptors"
+	"github.com/SergeyBogomolovv/restaurant/common/metrics"
+	"github.com/SergeyBogomolovv/restaurant/common/tracing"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/handler"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/mailer"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/oidc"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/passwords"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/repo"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
 	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	oidcHTTPTimeout     = 10 * time.Second
+	healthCheckInterval = 5 * time.Second
+	shutdownTimeout     = 10 * time.Second
 )
 
 type App struct {
-	server *grpc.Server
-	log    *slog.Logger
+	server     *grpc.Server
+	health     *health.Checker
+	metrics    *metrics.Server
+	log        *slog.Logger
+	stopReload context.CancelFunc
+	// stopEmails waits for the emails sent in the background, which use the
+	// connections closed after Shutdown.
+	stopEmails func()
 }
 
-func New(log *slog.Logger, db *sqlx.DB, rdb *redis.Client, jwtConfig config.JwtConfig, secretKey string) *App {
+func New(log *slog.Logger, db *sqlx.DB, rdb *redis.Client, jwtConfig config.JwtConfig, ssoConfig config.SSOService) (*App, error) {
+	metrics.RegisterDB(db)
+	metrics.InstrumentRedis(rdb)
+	tracing.InstrumentRedis(rdb)
+
+	identityRepo := repo.NewIdentityRepo(db)
+	permissionRepo := repo.NewPermissionRepo(db)
 	customerRepo := repo.NewCustomerRepo(db)
 	adminRepo := repo.NewAdminRepo(db)
 	waiterRepo := repo.NewWaiterRepo(db)
+	mfaRepo := repo.NewMFARepo(db)
+	invitationRepo := repo.NewInvitationRepo(db)
+	reservationRepo := repo.NewReservationRepo(db)
+	auditRepo := repo.NewAuditRepo(db)
 
 	tokensRepo := repo.NewTokensRepo(rdb, jwtConfig)
-	authUsecase := usecase.NewAuthUsecase(log, customerRepo, waiterRepo, adminRepo, tokensRepo)
-	registerUsecase := usecase.NewRegisterUsecase(log, customerRepo, waiterRepo, adminRepo, secretKey)
+	verificationRepo := repo.NewVerificationRepo(rdb, ssoConfig.VerificationTTL, ssoConfig.ResendCooldown)
+	resetRepo := repo.NewResetRepo(rdb, ssoConfig.ResetPasswordTTL, ssoConfig.ResetCooldown)
+	deletionRepo := repo.NewDeletionRepo(rdb, ssoConfig.DeletionTTL)
+	attemptsRepo := repo.NewAttemptsRepo(rdb, ssoConfig.LoginLimit)
+	oidcStateRepo := repo.NewOIDCStateRepo(rdb, ssoConfig.OIDC.StateTTL)
+
+	mailer, err := mailer.New(ssoConfig.Mailer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mailer: %w", err)
+	}
 
-	server := grpc.NewServer()
-	handler.RegisterGRPCHandler(server, authUsecase, registerUsecase)
+	hasher, err := passwords.NewHasher(ssoConfig.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create password hasher: %w", err)
+	}
+	policy, err := passwords.NewPolicy(ssoConfig.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create password policy: %w", err)
+	}
+	trustedProxies, err := handler.ParseTrustedProxies(ssoConfig.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse trusted proxies: %w", err)
+	}
 
-	return &App{server: server, log: log}
+	// Last to fail, so nothing started below has to be stopped on an error.
+	ctx, cancel := context.WithCancel(context.Background())
+	creds, err := certs.ServerCredentials(ctx, log, ssoConfig.TLS)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
+	}
+
+	authUsecase := usecase.NewAuthUsecase(log, identityRepo, customerRepo, waiterRepo, tokensRepo, permissionRepo, mfaRepo, attemptsRepo, hasher)
+	verificationUsecase := usecase.NewVerificationUsecase(log, customerRepo, verificationRepo, mailer, ssoConfig.VerifyEmailURL)
+	registerUsecase := usecase.NewRegisterUsecase(log, identityRepo, customerRepo, waiterRepo, adminRepo, verificationUsecase, invitationRepo, attemptsRepo, hasher, policy)
+	mfaUsecase := usecase.NewMFAUsecase(log, mfaRepo, tokensRepo, permissionRepo, identityRepo, attemptsRepo, authUsecase, ssoConfig.MFAIssuer)
+	passwordUsecase := usecase.NewPasswordUsecase(log, identityRepo, customerRepo, resetRepo, tokensRepo, mailer, hasher, policy, ssoConfig.ResetPasswordURL)
+	profileUsecase := usecase.NewProfileUsecase(log, customerRepo, waiterRepo, adminRepo, verificationUsecase)
+	invitationUsecase := usecase.NewInvitationUsecase(log, invitationRepo, ssoConfig.InvitationTTL, ssoConfig.MaxInvitationTTL)
+	staffUsecase := usecase.NewStaffUsecase(log, waiterRepo, adminRepo, tokensRepo)
+	customersUsecase := usecase.NewCustomersUsecase(log, customerRepo, tokensRepo)
+	accountUsecase := usecase.NewAccountUsecase(log, identityRepo, customerRepo, reservationRepo, auditRepo, tokensRepo, deletionRepo, mailer, hasher, ssoConfig.DeleteAccountURL)
+	permissionUsecase := usecase.NewPermissionUsecase(log, permissionRepo)
+
+	httpClient := &http.Client{Timeout: oidcHTTPTimeout}
+	providers := make(map[string]usecase.OIDCProvider, len(ssoConfig.OIDC.Providers))
+	for _, provider := range ssoConfig.OIDC.Providers {
+		providers[provider.Name] = oidc.NewProvider(log, provider, httpClient)
+	}
+	oidcUsecase := usecase.NewOIDCUsecase(log, providers, oidcStateRepo, identityRepo, customerRepo, authUsecase)
+
+	server := grpc.NewServer(grpc.Creds(creds), tracing.ServerOption(), grpc.ChainUnaryInterceptor(
+		interceptors.Logging(log),
+		metrics.UnaryServerInterceptor(),
+		interceptors.Timeout(ssoConfig.Timeout, ssoConfig.Timeouts),
+		interceptors.Recovery(log),
+	))
+	handler.RegisterGRPCHandler(server, authUsecase, registerUsecase, mfaUsecase, verificationUsecase, passwordUsecase, profileUsecase, invitationUsecase, staffUsecase, customersUsecase, accountUsecase, permissionUsecase, oidcUsecase, trustedProxies)
+
+	checks := map[string]health.Check{"postgres": health.DBCheck(db), "redis": health.RedisCheck(rdb)}
+	checker := health.NewChecker(log, healthCheckInterval, checks, pb.SSO_ServiceDesc.ServiceName)
+	healthpb.RegisterHealthServer(server, checker.Server())
+
+	return &App{
+		server:     server,
+		health:     checker,
+		metrics:    metrics.NewServer(log, ssoConfig.MetricsPort),
+		log:        log,
+		stopReload: cancel,
+		stopEmails: passwordUsecase.Close,
+	}, nil
 }
 
-func (a *App) Run(port int) {
+// StartHealthChecks reports the service as serving once Postgres and Redis
+// answer. Call it after waiting for them, until then it is NOT_SERVING.
+func (a *App) StartHealthChecks(ctx context.Context) {
+	go a.health.Run(ctx)
+}
+
+func (a *App) Run(port int) error {
 	const op = "sso.Run"
 	log := a.log.With(slog.String("op", op))
 
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to listen: %w", err)
 	}
 
+	metricsErr := make(chan error, 1)
+	go func() {
+		if err := a.metrics.Run(); err != nil {
+			metricsErr <- err
+			a.server.Stop()
+		}
+	}()
 	log.Info("gRPC server started", "addr", listener.Addr().String())
 
-	if err := a.server.Serve(listener); err != nil {
-		panic(err)
+	serveErr := a.server.Serve(listener)
+	select {
+	case err := <-metricsErr:
+		return err
+	default:
+		return serveErr
 	}
 }
 
 func (a *App) Shutdown() {
 	const op = "sso.Shutdown"
 	log := a.log.With(slog.String("op", op))
+	a.health.Shutdown()
 	a.server.GracefulStop()
+	a.stopReload()
+	a.stopEmails()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	a.metrics.Shutdown(ctx)
 	log.Info("gRPC server stopped")
 }
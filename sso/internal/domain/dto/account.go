@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type DeleteAccountDTO struct {
+	Password string `validate:"required_without=Token"`
+	Token    string `validate:"required_without=Password"`
+}
+
+type DataExportDTO struct {
+	ExportedAt   time.Time              `json:"exported_at"`
+	Customer     CustomerExportDTO      `json:"customer"`
+	Reservations []ReservationExportDTO `json:"reservations"`
+}
+
+type CustomerExportDTO struct {
+	CustomerID      string     `json:"customer_id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	BirthDate       *time.Time `json:"birth_date,omitempty"`
+	TotalSpent      float64    `json:"total_spent"`
+	RegisteredAt    time.Time  `json:"registered_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+type ReservationExportDTO struct {
+	ReservationID string    `json:"reservation_id"`
+	TableID       string    `json:"table_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Status        string    `json:"status"`
+}
@@ -3,21 +3,30 @@ package dto
 import "time"
 
 type CreateCustomerDTO struct {
-	Email     string
-	Name      string
-	Birthdate time.Time
-	Password  []byte
+	IdentityID    string
+	Email         string
+	Name          string
+	Birthdate     *time.Time
+	EmailVerified bool
 }
 
 type CreateAdminDTO struct {
-	Login    string
-	Password []byte
-	Note     string
+	IdentityID string
+	Note       string
 }
 
 type CreateWaiterDTO struct {
-	Login     string
-	Password  []byte
-	FirstName string
-	LastName  string
+	IdentityID string
+	FirstName  string
+	LastName   string
+}
+
+type CreateInvitationDTO struct {
+	CodeHash  []byte
+	Role      string
+	FirstName *string
+	LastName  *string
+	Note      *string
+	CreatedBy string
+	ExpiresAt time.Time
 }
@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+
+type SearchCustomersDTO struct {
+	Query    string
+	Page     int `validate:"gte=0"`
+	PageSize int `validate:"gte=0,lte=100"`
+}
+
+type SetCustomerBlockedDTO struct {
+	CustomerID string `validate:"required,uuid"`
+	Blocked    bool
+	Reason     string
+}
+
+type CustomersPageDTO struct {
+	Customers []*entities.CustomerEntity
+	Total     int
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type IntrospectDTO struct {
+	Active      bool
+	EntityID    string
+	Role        string
+	Permissions []string
+	ExpiresAt   time.Time
+	Fired       bool
+	Blocked     bool
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type IssueInvitationDTO struct {
+	Role      string `validate:"required,oneof=waiter admin"`
+	FirstName string
+	LastName  string
+	Note      string
+	TTL       time.Duration `validate:"gte=0"`
+}
+
+type InvitationDTO struct {
+	InvitationID string
+	Code         string
+	ExpiresAt    time.Time
+}
@@ -3,14 +3,46 @@ package dto
 type LoginCustomerDTO struct {
 	Email    string `validate:"required,email"`
 	Password string `validate:"required"`
+	IP       string
 }
 
 type LoginEmployeeDTO struct {
 	Login    string `validate:"required"`
 	Password string `validate:"required"`
+	IP       string
+}
+
+type LoginDTO struct {
+	Login    string `validate:"required"`
+	Password string `validate:"required"`
+	Role     string `validate:"required,oneof=customer waiter admin"`
+	IP       string
 }
 
 type TokensDTO struct {
 	AccessToken  string
 	RefreshToken string
+	MFAToken     string
+}
+
+type UnlockAccountDTO struct {
+	Role  string `validate:"required,oneof=customer waiter admin"`
+	Login string `validate:"required"`
+}
+
+type ExternalIdentityDTO struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type OIDCAuthorizationDTO struct {
+	URL   string
+	State string
+}
+
+type OIDCCallbackDTO struct {
+	State string `validate:"required"`
+	Code  string `validate:"required"`
 }
@@ -0,0 +1,11 @@
+package dto
+
+type MFAEnrollmentDTO struct {
+	Secret string
+	URI    string
+}
+
+type VerifyMFADTO struct {
+	Token string `validate:"required"`
+	Code  string `validate:"required"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type ResetPasswordDTO struct {
+	Token    string `validate:"required"`
+	Password string `validate:"required"`
+}
+
+type ChangePasswordDTO struct {
+	OldPassword string `validate:"required"`
+	NewPassword string `validate:"required"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type RolePermissionDTO struct {
+	Role       string `validate:"required,oneof=customer waiter admin"`
+	Permission string `validate:"required"`
+}
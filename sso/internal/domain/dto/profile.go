@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+
+type ProfileDTO struct {
+	Role     string
+	Customer *entities.CustomerEntity
+	Waiter   *entities.WaiterEntity
+	Admin    *entities.AdminEntity
+}
+
+type UpdateProfileDTO struct {
+	Name      string
+	Email     string `validate:"omitempty,email"`
+	FirstName string
+	LastName  string
+	Note      *string
+}
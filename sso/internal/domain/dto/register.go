@@ -7,19 +7,28 @@ type RegisterCustomerDTO struct {
 	Name      string    `validate:"required"`
 	Birthdate time.Time `validate:"required"`
 	Password  string    `validate:"required"`
+	IP        string
 }
 
 type RegisterAdminDTO struct {
-	Note     string
-	Login    string `validate:"required"`
-	Password string `validate:"required"`
-	Token    string `validate:"required"`
+	Note           string
+	Login          string `validate:"required"`
+	Password       string `validate:"required"`
+	InvitationCode string `validate:"required"`
+	IP             string
 }
 
 type RegisterWaiterDTO struct {
-	Login     string `validate:"required"`
-	Password  string `validate:"required"`
-	FirstName string `validate:"required"`
-	LastName  string `validate:"required"`
-	Token     string `validate:"required"`
+	Login          string `validate:"required"`
+	Password       string `validate:"required"`
+	FirstName      string
+	LastName       string
+	InvitationCode string `validate:"required"`
+	IP             string
+}
+
+type BootstrapAdminDTO struct {
+	Login    string `validate:"required"`
+	Password string `validate:"required"`
+	Note     string
 }
@@ -0,0 +1,39 @@
+package dto
+
+import "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+
+type FireWaiterDTO struct {
+	WaiterID string `validate:"required,uuid"`
+	Reason   string `validate:"required"`
+}
+
+type ListWaitersDTO struct {
+	Page     int    `validate:"gte=0"`
+	PageSize int    `validate:"gte=0,lte=100"`
+	Status   string `validate:"omitempty,oneof=active fired"`
+	SortBy   string `validate:"omitempty,oneof=rating hired_at"`
+	Desc     bool
+}
+
+type ListAdminsDTO struct {
+	Page     int `validate:"gte=0"`
+	PageSize int `validate:"gte=0,lte=100"`
+}
+
+type WaiterFilterDTO struct {
+	Status string
+	SortBy string
+	Desc   bool
+	Limit  int
+	Offset int
+}
+
+type WaitersPageDTO struct {
+	Waiters []*entities.WaiterEntity
+	Total   int
+}
+
+type AdminsPageDTO struct {
+	Admins []*entities.AdminEntity
+	Total  int
+}
@@ -2,20 +2,51 @@ package entities
 
 import "time"
 
+type IdentityEntity struct {
+	IdentityID string    `db:"identity_id"`
+	Login      string    `db:"login"`
+	Password   []byte    `db:"password"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type IdentityRoleEntity struct {
+	IdentityID string    `db:"identity_id"`
+	Role       string    `db:"role"`
+	EntityID   string    `db:"entity_id"`
+	GrantedAt  time.Time `db:"granted_at"`
+}
+
+type ExternalIdentityEntity struct {
+	Provider   string    `db:"provider"`
+	Subject    string    `db:"subject"`
+	IdentityID string    `db:"identity_id"`
+	Email      *string   `db:"email"`
+	LinkedAt   time.Time `db:"linked_at"`
+}
+
+type OIDCStateEntity struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+}
+
 type CustomerEntity struct {
-	CustomerID   string    `db:"customer_id"`
-	Name         string    `db:"name"`
-	BirthDate    time.Time `db:"birth_date"`
-	TotalSpent   float64   `db:"total_spent"`
-	Email        string    `db:"email"`
-	Password     []byte    `db:"password"`
-	RegisteredAt time.Time `db:"registered_at"`
+	CustomerID      string     `db:"customer_id"`
+	IdentityID      string     `db:"identity_id"`
+	Name            string     `db:"name"`
+	BirthDate       *time.Time `db:"birth_date"`
+	TotalSpent      float64    `db:"total_spent"`
+	Email           string     `db:"email"`
+	RegisteredAt    time.Time  `db:"registered_at"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	BlockedAt       *time.Time `db:"blocked_at"`
+	BlockedReason   *string    `db:"blocked_reason"`
+	DeletedAt       *time.Time `db:"deleted_at"`
 }
 
 type WaiterEntity struct {
 	WaiterID    string     `db:"waiter_id"`
+	IdentityID  string     `db:"identity_id"`
 	Login       string     `db:"login"`
-	Password    []byte     `db:"password"`
 	FirstName   string     `db:"first_name"`
 	LastName    string     `db:"last_name"`
 	HiredAt     time.Time  `db:"hired_at"`
@@ -25,10 +56,46 @@ type WaiterEntity struct {
 }
 
 type AdminEntity struct {
-	AdminID  string  `db:"admin_id"`
-	Note     *string `db:"note"`
-	Login    string  `db:"login"`
-	Password []byte  `db:"password"`
+	AdminID    string  `db:"admin_id"`
+	IdentityID string  `db:"identity_id"`
+	Note       *string `db:"note"`
+	Login      string  `db:"login"`
+}
+
+type MFAEntity struct {
+	EntityID     string     `db:"entity_id"`
+	Role         string     `db:"role"`
+	Secret       string     `db:"secret"`
+	CreatedAt    time.Time  `db:"created_at"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	LastTOTPStep *int64     `db:"last_totp_step"`
+}
+
+type InvitationEntity struct {
+	InvitationID string     `db:"invitation_id"`
+	CodeHash     []byte     `db:"code_hash"`
+	Role         string     `db:"role"`
+	FirstName    *string    `db:"first_name"`
+	LastName     *string    `db:"last_name"`
+	Note         *string    `db:"note"`
+	CreatedBy    *string    `db:"created_by"`
+	CreatedAt    time.Time  `db:"created_at"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	UsedAt       *time.Time `db:"used_at"`
+}
+
+type ReservationEntity struct {
+	ReservationID string    `db:"reservation_id"`
+	CustomerID    string    `db:"customer_id"`
+	StartTime     time.Time `db:"start_time"`
+	EndTime       time.Time `db:"end_time"`
+	Status        string    `db:"status"`
+	TableID       string    `db:"table_id"`
+}
+
+type EmailVerificationEntity struct {
+	CustomerID string `json:"customer_id"`
+	Email      string `json:"email"`
 }
 
 type RefreshTokenEntity struct {
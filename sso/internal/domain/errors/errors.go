@@ -1,15 +1,62 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
-	ErrInvalidJwtToken       = errors.New("invalid jwt token")
-	ErrInvalidSecretToken    = errors.New("invalid secret token")
-	ErrInvalidCredentials    = errors.New("invalid credentials")
-	ErrCustomerNotFound      = errors.New("customer not found")
-	ErrCustomerAlreadyExists = errors.New("customer already exists")
-	ErrWaiterAlreadyExists   = errors.New("customer already exists")
-	ErrWaiterNotFound        = errors.New("waiter not found")
-	ErrAdminAlreadyExists    = errors.New("admin already exists")
-	ErrAdminNotFound         = errors.New("admin not found")
+	ErrInvalidJwtToken        = errors.New("invalid jwt token")
+	ErrInvalidInvitation      = errors.New("invalid invitation")
+	ErrInvitationTTLTooLong   = errors.New("invitation ttl exceeds the maximum")
+	ErrInvitationNotAllowed   = errors.New("not allowed to issue this invitation")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrCustomerNotFound       = errors.New("customer not found")
+	ErrCustomerAlreadyExists  = errors.New("customer already exists")
+	ErrWaiterAlreadyExists    = errors.New("customer already exists")
+	ErrWaiterNotFound         = errors.New("waiter not found")
+	ErrAdminAlreadyExists     = errors.New("admin already exists")
+	ErrAdminNotFound          = errors.New("admin not found")
+	ErrMFANotFound            = errors.New("mfa not found")
+	ErrMFAAlreadyEnabled      = errors.New("mfa already enabled")
+	ErrMFANotEnabled          = errors.New("mfa not enabled")
+	ErrInvalidMFACode         = errors.New("invalid mfa code")
+	ErrInvalidMFAToken        = errors.New("invalid mfa token")
+	ErrInvalidVerifyToken     = errors.New("invalid verification token")
+	ErrInvalidResetToken      = errors.New("invalid reset token")
+	ErrInvalidDeletionToken   = errors.New("invalid deletion token")
+	ErrPasswordNotSet         = errors.New("password is not set")
+	ErrInvalidPassword        = errors.New("invalid password")
+	ErrInvalidRole            = errors.New("invalid role")
+	ErrTooManyAttempts        = errors.New("too many attempts")
+	ErrNameRequired           = errors.New("name is required")
+	ErrAlreadyBootstrapped    = errors.New("admin already bootstrapped")
+	ErrWaiterFired            = errors.New("waiter is fired")
+	ErrWaiterNotFired         = errors.New("waiter is not fired")
+	ErrCustomerBlocked        = errors.New("customer is blocked")
+	ErrIdentityNotFound       = errors.New("identity not found")
+	ErrLoginTaken             = errors.New("login already taken")
+	ErrRoleNotGranted         = errors.New("role not granted")
+	ErrPermissionNotFound     = errors.New("permission not found")
+	ErrPermissionNotGranted   = errors.New("permission not granted")
+	ErrProtectedPermission    = errors.New("permission cannot be revoked")
+	ErrWeakPassword           = errors.New("password does not satisfy the policy")
+	ErrUnknownOIDCProvider    = errors.New("unknown identity provider")
+	ErrInvalidOIDCState       = errors.New("invalid or expired oidc state")
+	ErrOIDCLoginFailed        = errors.New("identity provider login failed")
+	ErrEmailNotVerified       = errors.New("email is not verified by the identity provider")
+	ErrAccountNotLinkable     = errors.New("account with this email cannot be linked to the identity provider")
+	ErrExternalIdentityLinked = errors.New("external identity already linked")
 )
+
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *TooManyAttemptsError) Unwrap() error {
+	return ErrTooManyAttempts
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SergeyBogomolovv/restaurant/common/interceptors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func (h *ssoHandler) authenticate(ctx context.Context, roles ...string) (*dto.IntrospectDTO, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization")
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization")
+	}
+
+	result, err := h.auth.Introspect(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to authenticate")
+	}
+	if !result.Active {
+		return nil, status.Error(codes.Unauthenticated, "invalid accessToken")
+	}
+	interceptors.SetCaller(ctx, result.EntityID)
+	if len(roles) > 0 && !slices.Contains(roles, result.Role) {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return result, nil
+}
+
+func (h *ssoHandler) authorize(ctx context.Context, permissions ...string) (*dto.IntrospectDTO, error) {
+	result, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.HasPermissions(result.Permissions, permissions...) {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return result, nil
+}
+
+// clientIP returns the address of the client. The address forwarded in
+// x-forwarded-for is only honoured from the gateway, which terminates client
+// connections: a peer authenticated with a client certificate or one of the
+// configured trusted proxies. Anyone else could rotate it to dodge the
+// per-IP login limit.
+func (h *ssoHandler) clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if !h.trustsForwarding(p, host) {
+		return host
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-forwarded-for"); len(values) > 0 && values[0] != "" {
+			forwarded, _, _ := strings.Cut(values[0], ",")
+			return strings.TrimSpace(forwarded)
+		}
+	}
+	return host
+}
+
+func (h *ssoHandler) trustsForwarding(p *peer.Peer, host string) bool {
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range h.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses addresses and CIDR ranges of the proxies allowed
+// to forward the client address.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func tooManyAttempts(ctx context.Context, err error) error {
+	var lockErr *errs.TooManyAttemptsError
+	if errors.As(err, &lockErr) {
+		retryAfter := strconv.Itoa(int(math.Ceil(lockErr.RetryAfter.Seconds())))
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+	}
+	return status.Error(codes.ResourceExhausted, "too many login attempts, try again later")
+}
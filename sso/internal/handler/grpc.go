@@ -3,10 +3,13 @@ package handler
 import (
 	"context"
 	"errors"
+	"net/netip"
 	"time"
 
 	pb "github.com/SergeyBogomolovv/restaurant/common/api/gen/sso"
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -19,8 +22,16 @@ type AuthUsecase interface {
 	LoginCustomer(ctx context.Context, dto *dto.LoginCustomerDTO) (*dto.TokensDTO, error)
 	LoginWaiter(ctx context.Context, dto *dto.LoginEmployeeDTO) (*dto.TokensDTO, error)
 	LoginAdmin(ctx context.Context, dto *dto.LoginEmployeeDTO) (*dto.TokensDTO, error)
+	Login(ctx context.Context, dto *dto.LoginDTO) (*dto.TokensDTO, error)
 	Refresh(ctx context.Context, token string) (string, error)
 	Logout(ctx context.Context, token string) error
+	Introspect(ctx context.Context, token string) (*dto.IntrospectDTO, error)
+	UnlockAccount(ctx context.Context, dto *dto.UnlockAccountDTO) error
+}
+
+type OIDCUsecase interface {
+	StartOIDCLogin(ctx context.Context, provider string) (*dto.OIDCAuthorizationDTO, error)
+	CompleteOIDCLogin(ctx context.Context, dto *dto.OIDCCallbackDTO) (*dto.TokensDTO, error)
 }
 
 type RegisterUsecase interface {
@@ -29,18 +40,108 @@ type RegisterUsecase interface {
 	RegisterAdmin(ctx context.Context, dto *dto.RegisterAdminDTO) (uuid.UUID, error)
 }
 
+type MFAUsecase interface {
+	EnrollMFA(ctx context.Context, entityID string, role string) (*dto.MFAEnrollmentDTO, error)
+	ConfirmMFA(ctx context.Context, entityID string, code string) ([]string, error)
+	DisableMFA(ctx context.Context, entityID string, code string) error
+	VerifyMFA(ctx context.Context, dto *dto.VerifyMFADTO) (*dto.TokensDTO, error)
+}
+
+type VerificationUsecase interface {
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, email string) error
+}
+
+type PasswordUsecase interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, dto *dto.ResetPasswordDTO) error
+	ChangePassword(ctx context.Context, entityID string, role string, dto *dto.ChangePasswordDTO) error
+}
+
+type InvitationUsecase interface {
+	IssueInvitation(ctx context.Context, adminID string, callerRole string, dto *dto.IssueInvitationDTO) (*dto.InvitationDTO, error)
+}
+
+type StaffUsecase interface {
+	GetWaiter(ctx context.Context, waiterID string) (*entities.WaiterEntity, error)
+	ListWaiters(ctx context.Context, dto *dto.ListWaitersDTO) (*dto.WaitersPageDTO, error)
+	ListAdmins(ctx context.Context, dto *dto.ListAdminsDTO) (*dto.AdminsPageDTO, error)
+	FireWaiter(ctx context.Context, dto *dto.FireWaiterDTO) error
+	RehireWaiter(ctx context.Context, waiterID string) error
+}
+
+type CustomersUsecase interface {
+	SearchCustomers(ctx context.Context, dto *dto.SearchCustomersDTO) (*dto.CustomersPageDTO, error)
+	GetCustomer(ctx context.Context, customerID string) (*entities.CustomerEntity, error)
+	SetCustomerBlocked(ctx context.Context, dto *dto.SetCustomerBlockedDTO) error
+}
+
+type AccountUsecase interface {
+	ExportMyData(ctx context.Context, customerID string) ([]byte, error)
+	RequestAccountDeletion(ctx context.Context, customerID string) error
+	DeleteAccount(ctx context.Context, customerID string, dto *dto.DeleteAccountDTO) error
+}
+
+type PermissionUsecase interface {
+	ListRolePermissions(ctx context.Context, role string) ([]string, error)
+	GrantPermission(ctx context.Context, dto *dto.RolePermissionDTO) error
+	RevokePermission(ctx context.Context, dto *dto.RolePermissionDTO) error
+}
+
+type ProfileUsecase interface {
+	GetProfile(ctx context.Context, entityID string, role string) (*dto.ProfileDTO, error)
+	UpdateProfile(ctx context.Context, entityID string, role string, dto *dto.UpdateProfileDTO) (*dto.ProfileDTO, error)
+}
+
 type ssoHandler struct {
-	validate *validator.Validate
-	auth     AuthUsecase
-	register RegisterUsecase
+	validate       *validator.Validate
+	auth           AuthUsecase
+	register       RegisterUsecase
+	mfa            MFAUsecase
+	verification   VerificationUsecase
+	password       PasswordUsecase
+	profile        ProfileUsecase
+	invitation     InvitationUsecase
+	staff          StaffUsecase
+	customers      CustomersUsecase
+	account        AccountUsecase
+	permissions    PermissionUsecase
+	oidc           OIDCUsecase
+	trustedProxies []netip.Prefix
 	pb.UnimplementedSSOServer
 }
 
-func RegisterGRPCHandler(server *grpc.Server, auth AuthUsecase, register RegisterUsecase) {
+func RegisterGRPCHandler(
+	server *grpc.Server,
+	auth AuthUsecase,
+	register RegisterUsecase,
+	mfa MFAUsecase,
+	verification VerificationUsecase,
+	password PasswordUsecase,
+	profile ProfileUsecase,
+	invitation InvitationUsecase,
+	staff StaffUsecase,
+	customers CustomersUsecase,
+	account AccountUsecase,
+	permissions PermissionUsecase,
+	oidc OIDCUsecase,
+	trustedProxies []netip.Prefix,
+) {
 	handler := &ssoHandler{
-		validate: validator.New(validator.WithRequiredStructEnabled()),
-		auth:     auth,
-		register: register,
+		validate:       validator.New(validator.WithRequiredStructEnabled()),
+		auth:           auth,
+		register:       register,
+		mfa:            mfa,
+		verification:   verification,
+		password:       password,
+		profile:        profile,
+		invitation:     invitation,
+		staff:          staff,
+		customers:      customers,
+		account:        account,
+		permissions:    permissions,
+		oidc:           oidc,
+		trustedProxies: trustedProxies,
 	}
 	pb.RegisterSSOServer(server, handler)
 }
@@ -51,6 +152,7 @@ func (h *ssoHandler) RegisterCustomer(ctx context.Context, req *pb.RegisterCusto
 		Name:      req.Name,
 		Birthdate: time.Unix(req.Birthdate, 0),
 		Password:  req.Password,
+		IP:        h.clientIP(ctx),
 	}
 	if err := h.validate.Struct(dto); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
@@ -58,8 +160,12 @@ func (h *ssoHandler) RegisterCustomer(ctx context.Context, req *pb.RegisterCusto
 	entityID, err := h.register.RegisterCustomer(ctx, dto)
 	if err != nil {
 		switch {
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
 		case errors.Is(err, errs.ErrCustomerAlreadyExists):
 			return nil, status.Error(codes.AlreadyExists, "Customer with this email already exists")
+		case errors.Is(err, errs.ErrWeakPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		default:
 			return nil, status.Error(codes.Internal, "failed to register customer")
 		}
@@ -69,11 +175,12 @@ func (h *ssoHandler) RegisterCustomer(ctx context.Context, req *pb.RegisterCusto
 
 func (h *ssoHandler) RegisterWaiter(ctx context.Context, req *pb.RegisterWaiterRequest) (*pb.RegisterResponse, error) {
 	dto := &dto.RegisterWaiterDTO{
-		Login:     req.Login,
-		Password:  req.Password,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Token:     req.SecretToken,
+		Login:          req.Login,
+		Password:       req.Password,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		InvitationCode: req.InvitationCode,
+		IP:             h.clientIP(ctx),
 	}
 	if err := h.validate.Struct(dto); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
@@ -82,10 +189,16 @@ func (h *ssoHandler) RegisterWaiter(ctx context.Context, req *pb.RegisterWaiterR
 	entityID, err := h.register.RegisterWaiter(ctx, dto)
 	if err != nil {
 		switch {
-		case errors.Is(err, errs.ErrInvalidSecretToken):
-			return nil, status.Error(codes.Unauthenticated, "invalid secret token")
+		case errors.Is(err, errs.ErrInvalidInvitation):
+			return nil, status.Error(codes.PermissionDenied, "invalid invitation code")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
+		case errors.Is(err, errs.ErrNameRequired):
+			return nil, status.Error(codes.InvalidArgument, "first name and last name are required")
 		case errors.Is(err, errs.ErrWaiterAlreadyExists):
 			return nil, status.Error(codes.AlreadyExists, "Waiter with this login already exists")
+		case errors.Is(err, errs.ErrWeakPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		default:
 			return nil, status.Error(codes.Internal, "failed to register waiter")
 		}
@@ -95,10 +208,11 @@ func (h *ssoHandler) RegisterWaiter(ctx context.Context, req *pb.RegisterWaiterR
 
 func (h *ssoHandler) RegisterAdmin(ctx context.Context, req *pb.RegisterAdminRequest) (*pb.RegisterResponse, error) {
 	dto := &dto.RegisterAdminDTO{
-		Note:     req.Note,
-		Login:    req.Login,
-		Password: req.Password,
-		Token:    req.SecretToken,
+		Note:           req.Note,
+		Login:          req.Login,
+		Password:       req.Password,
+		InvitationCode: req.InvitationCode,
+		IP:             h.clientIP(ctx),
 	}
 	if err := h.validate.Struct(dto); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
@@ -106,10 +220,14 @@ func (h *ssoHandler) RegisterAdmin(ctx context.Context, req *pb.RegisterAdminReq
 	entityID, err := h.register.RegisterAdmin(ctx, dto)
 	if err != nil {
 		switch {
-		case errors.Is(err, errs.ErrInvalidSecretToken):
-			return nil, status.Error(codes.Unauthenticated, "invalid secret token")
+		case errors.Is(err, errs.ErrInvalidInvitation):
+			return nil, status.Error(codes.PermissionDenied, "invalid invitation code")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
 		case errors.Is(err, errs.ErrAdminAlreadyExists):
 			return nil, status.Error(codes.AlreadyExists, "Admin with this login already exists")
+		case errors.Is(err, errs.ErrWeakPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		default:
 			return nil, status.Error(codes.Internal, "failed to register admin")
 		}
@@ -121,6 +239,7 @@ func (h *ssoHandler) LoginCustomer(ctx context.Context, req *pb.LoginCustomerReq
 	dto := &dto.LoginCustomerDTO{
 		Email:    req.Email,
 		Password: req.Password,
+		IP:       h.clientIP(ctx),
 	}
 	if err := h.validate.Struct(dto); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
@@ -130,17 +249,22 @@ func (h *ssoHandler) LoginCustomer(ctx context.Context, req *pb.LoginCustomerReq
 		switch {
 		case errors.Is(err, errs.ErrInvalidCredentials):
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
+		case errors.Is(err, errs.ErrCustomerBlocked):
+			return nil, status.Error(codes.PermissionDenied, "customer is blocked")
 		default:
 			return nil, status.Error(codes.Internal, "failed to login customer")
 		}
 	}
-	return &pb.LoginResponse{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+	return loginResponse(tokens), nil
 }
 
 func (h *ssoHandler) LoginWaiter(ctx context.Context, req *pb.LoginEmployeeRequest) (*pb.LoginResponse, error) {
 	dto := &dto.LoginEmployeeDTO{
 		Login:    req.Login,
 		Password: req.Password,
+		IP:       h.clientIP(ctx),
 	}
 	if err := h.validate.Struct(dto); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
@@ -150,17 +274,22 @@ func (h *ssoHandler) LoginWaiter(ctx context.Context, req *pb.LoginEmployeeReque
 		switch {
 		case errors.Is(err, errs.ErrInvalidCredentials):
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
+		case errors.Is(err, errs.ErrWaiterFired):
+			return nil, status.Error(codes.PermissionDenied, "waiter is fired")
 		default:
 			return nil, status.Error(codes.Internal, "failed to login waiter")
 		}
 	}
-	return &pb.LoginResponse{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+	return loginResponse(tokens), nil
 }
 
 func (h *ssoHandler) LoginAdmin(ctx context.Context, req *pb.LoginEmployeeRequest) (*pb.LoginResponse, error) {
 	dto := &dto.LoginEmployeeDTO{
 		Login:    req.Login,
 		Password: req.Password,
+		IP:       h.clientIP(ctx),
 	}
 	if err := h.validate.Struct(dto); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
@@ -170,11 +299,91 @@ func (h *ssoHandler) LoginAdmin(ctx context.Context, req *pb.LoginEmployeeReques
 		switch {
 		case errors.Is(err, errs.ErrInvalidCredentials):
 			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
 		default:
 			return nil, status.Error(codes.Internal, "failed to login admin")
 		}
 	}
-	return &pb.LoginResponse{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+	return loginResponse(tokens), nil
+}
+
+func (h *ssoHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	dto := &dto.LoginDTO{
+		Login:    req.Login,
+		Password: req.Password,
+		Role:     req.Role,
+		IP:       h.clientIP(ctx),
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	tokens, err := h.auth.Login(ctx, dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
+		case errors.Is(err, errs.ErrWaiterFired):
+			return nil, status.Error(codes.PermissionDenied, "waiter is fired")
+		case errors.Is(err, errs.ErrCustomerBlocked):
+			return nil, status.Error(codes.PermissionDenied, "customer is blocked")
+		default:
+			return nil, status.Error(codes.Internal, "failed to login")
+		}
+	}
+	return loginResponse(tokens), nil
+}
+
+func (h *ssoHandler) StartOIDCLogin(ctx context.Context, req *pb.StartOIDCLoginRequest) (*pb.StartOIDCLoginResponse, error) {
+	if err := h.validate.Var(req.Provider, "required"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	auth, err := h.oidc.StartOIDCLogin(ctx, req.Provider)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrUnknownOIDCProvider):
+			return nil, status.Error(codes.NotFound, "unknown identity provider")
+		default:
+			return nil, status.Error(codes.Internal, "failed to start login")
+		}
+	}
+	return &pb.StartOIDCLoginResponse{AuthorizationUrl: auth.URL, State: auth.State}, nil
+}
+
+func (h *ssoHandler) CompleteOIDCLogin(ctx context.Context, req *pb.CompleteOIDCLoginRequest) (*pb.LoginResponse, error) {
+	dto := &dto.OIDCCallbackDTO{
+		State: req.State,
+		Code:  req.Code,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	tokens, err := h.oidc.CompleteOIDCLogin(ctx, dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidOIDCState):
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired state")
+		case errors.Is(err, errs.ErrUnknownOIDCProvider):
+			return nil, status.Error(codes.NotFound, "unknown identity provider")
+		case errors.Is(err, errs.ErrOIDCLoginFailed):
+			return nil, status.Error(codes.Unauthenticated, "identity provider login failed")
+		case errors.Is(err, errs.ErrEmailNotVerified):
+			return nil, status.Error(codes.FailedPrecondition, "email is not verified by the identity provider")
+		case errors.Is(err, errs.ErrAccountNotLinkable):
+			return nil, status.Error(codes.FailedPrecondition, "account with this email exists, sign in with its password instead")
+		case errors.Is(err, errs.ErrCustomerAlreadyExists), errors.Is(err, errs.ErrExternalIdentityLinked):
+			return nil, status.Error(codes.AlreadyExists, "account is already linked")
+		case errors.Is(err, errs.ErrCustomerBlocked):
+			return nil, status.Error(codes.PermissionDenied, "customer is blocked")
+		case errors.Is(err, errs.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		default:
+			return nil, status.Error(codes.Internal, "failed to login")
+		}
+	}
+	return loginResponse(tokens), nil
 }
 
 func (h *ssoHandler) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
@@ -183,6 +392,10 @@ func (h *ssoHandler) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.R
 		switch {
 		case errors.Is(err, errs.ErrInvalidJwtToken):
 			return nil, status.Error(codes.Unauthenticated, "invalid refreshToken")
+		case errors.Is(err, errs.ErrWaiterFired):
+			return nil, status.Error(codes.PermissionDenied, "waiter is fired")
+		case errors.Is(err, errs.ErrCustomerBlocked):
+			return nil, status.Error(codes.PermissionDenied, "customer is blocked")
 		default:
 			return nil, status.Error(codes.Internal, "failed to refresh token")
 		}
@@ -197,3 +410,685 @@ func (h *ssoHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Log
 	}
 	return &pb.LogoutResponse{Status: "OK"}, nil
 }
+
+func (h *ssoHandler) Introspect(ctx context.Context, req *pb.IntrospectRequest) (*pb.IntrospectResponse, error) {
+	result, err := h.auth.Introspect(ctx, req.AccessToken)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to introspect token")
+	}
+	if !result.Active {
+		return &pb.IntrospectResponse{Active: false, Fired: result.Fired, Blocked: result.Blocked}, nil
+	}
+	return &pb.IntrospectResponse{
+		Active:      true,
+		EntityId:    result.EntityID,
+		Role:        result.Role,
+		ExpiresAt:   result.ExpiresAt.Unix(),
+		Permissions: result.Permissions,
+	}, nil
+}
+
+func (h *ssoHandler) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionStaffManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.UnlockAccountDTO{
+		Role:  req.Role,
+		Login: req.Login,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.auth.UnlockAccount(ctx, dto); err != nil {
+		return nil, status.Error(codes.Internal, "failed to unlock account")
+	}
+	return &pb.UnlockAccountResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) CreateInvitation(ctx context.Context, req *pb.CreateInvitationRequest) (*pb.CreateInvitationResponse, error) {
+	caller, err := h.authorize(ctx, constants.PermissionStaffManage)
+	if err != nil {
+		return nil, err
+	}
+	dto := &dto.IssueInvitationDTO{
+		Role:      req.Role,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Note:      req.Note,
+		TTL:       time.Duration(req.TtlSeconds) * time.Second,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	invitation, err := h.invitation.IssueInvitation(ctx, caller.EntityID, caller.Role, dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvitationTTLTooLong):
+			return nil, status.Error(codes.InvalidArgument, "invitation ttl exceeds the maximum")
+		case errors.Is(err, errs.ErrInvitationNotAllowed):
+			return nil, status.Error(codes.PermissionDenied, "not allowed to issue this invitation")
+		default:
+			return nil, status.Error(codes.Internal, "failed to create invitation")
+		}
+	}
+	return &pb.CreateInvitationResponse{
+		InvitationId: invitation.InvitationID,
+		Code:         invitation.Code,
+		ExpiresAt:    invitation.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (h *ssoHandler) FireWaiter(ctx context.Context, req *pb.FireWaiterRequest) (*pb.FireWaiterResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionStaffManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.FireWaiterDTO{
+		WaiterID: req.WaiterId,
+		Reason:   req.Reason,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.staff.FireWaiter(ctx, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrWaiterNotFound):
+			return nil, status.Error(codes.NotFound, "waiter not found")
+		case errors.Is(err, errs.ErrWaiterFired):
+			return nil, status.Error(codes.FailedPrecondition, "waiter already fired")
+		default:
+			return nil, status.Error(codes.Internal, "failed to fire waiter")
+		}
+	}
+	return &pb.FireWaiterResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) RehireWaiter(ctx context.Context, req *pb.RehireWaiterRequest) (*pb.RehireWaiterResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionStaffManage); err != nil {
+		return nil, err
+	}
+	if err := h.validate.Var(req.WaiterId, "required,uuid"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.staff.RehireWaiter(ctx, req.WaiterId); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrWaiterNotFound):
+			return nil, status.Error(codes.NotFound, "waiter not found")
+		case errors.Is(err, errs.ErrWaiterNotFired):
+			return nil, status.Error(codes.FailedPrecondition, "waiter is not fired")
+		default:
+			return nil, status.Error(codes.Internal, "failed to rehire waiter")
+		}
+	}
+	return &pb.RehireWaiterResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) GetWaiter(ctx context.Context, req *pb.GetWaiterRequest) (*pb.Waiter, error) {
+	if _, err := h.authorize(ctx, constants.PermissionStaffManage); err != nil {
+		return nil, err
+	}
+	if err := h.validate.Var(req.WaiterId, "required,uuid"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	waiter, err := h.staff.GetWaiter(ctx, req.WaiterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrWaiterNotFound):
+			return nil, status.Error(codes.NotFound, "waiter not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to get waiter")
+		}
+	}
+	return waiterResponse(waiter), nil
+}
+
+func (h *ssoHandler) ListWaiters(ctx context.Context, req *pb.ListWaitersRequest) (*pb.ListWaitersResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionStaffManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.ListWaitersDTO{
+		Page:     int(req.Page),
+		PageSize: int(req.PageSize),
+		Status:   req.Status,
+		SortBy:   req.SortBy,
+		Desc:     req.Desc,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	page, err := h.staff.ListWaiters(ctx, dto)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list waiters")
+	}
+	waiters := make([]*pb.Waiter, 0, len(page.Waiters))
+	for _, waiter := range page.Waiters {
+		waiters = append(waiters, waiterResponse(waiter))
+	}
+	return &pb.ListWaitersResponse{Waiters: waiters, Total: int64(page.Total)}, nil
+}
+
+func (h *ssoHandler) ListAdmins(ctx context.Context, req *pb.ListAdminsRequest) (*pb.ListAdminsResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionStaffManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.ListAdminsDTO{
+		Page:     int(req.Page),
+		PageSize: int(req.PageSize),
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	page, err := h.staff.ListAdmins(ctx, dto)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list admins")
+	}
+	admins := make([]*pb.AdminProfile, 0, len(page.Admins))
+	for _, admin := range page.Admins {
+		admins = append(admins, adminResponse(admin))
+	}
+	return &pb.ListAdminsResponse{Admins: admins, Total: int64(page.Total)}, nil
+}
+
+func (h *ssoHandler) SearchCustomers(ctx context.Context, req *pb.SearchCustomersRequest) (*pb.SearchCustomersResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionCustomersManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.SearchCustomersDTO{
+		Query:    req.Query,
+		Page:     int(req.Page),
+		PageSize: int(req.PageSize),
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	page, err := h.customers.SearchCustomers(ctx, dto)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to search customers")
+	}
+	customers := make([]*pb.Customer, 0, len(page.Customers))
+	for _, customer := range page.Customers {
+		customers = append(customers, customerResponse(customer))
+	}
+	return &pb.SearchCustomersResponse{Customers: customers, Total: int64(page.Total)}, nil
+}
+
+func (h *ssoHandler) GetCustomer(ctx context.Context, req *pb.GetCustomerRequest) (*pb.Customer, error) {
+	if _, err := h.authorize(ctx, constants.PermissionCustomersManage); err != nil {
+		return nil, err
+	}
+	if err := h.validate.Var(req.CustomerId, "required,uuid"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	customer, err := h.customers.GetCustomer(ctx, req.CustomerId)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerNotFound):
+			return nil, status.Error(codes.NotFound, "customer not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to get customer")
+		}
+	}
+	return customerResponse(customer), nil
+}
+
+func (h *ssoHandler) SetCustomerBlocked(ctx context.Context, req *pb.SetCustomerBlockedRequest) (*pb.SetCustomerBlockedResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionCustomersManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.SetCustomerBlockedDTO{
+		CustomerID: req.CustomerId,
+		Blocked:    req.Blocked,
+		Reason:     req.Reason,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.customers.SetCustomerBlocked(ctx, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerNotFound):
+			return nil, status.Error(codes.NotFound, "customer not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to update customer")
+		}
+	}
+	return &pb.SetCustomerBlockedResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) ListRolePermissions(ctx context.Context, req *pb.ListRolePermissionsRequest) (*pb.ListRolePermissionsResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionPermissionsManage); err != nil {
+		return nil, err
+	}
+	if err := h.validate.Var(req.Role, "required,oneof=customer waiter admin"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	permissions, err := h.permissions.ListRolePermissions(ctx, req.Role)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list permissions")
+	}
+	return &pb.ListRolePermissionsResponse{Permissions: permissions}, nil
+}
+
+func (h *ssoHandler) GrantPermission(ctx context.Context, req *pb.GrantPermissionRequest) (*pb.GrantPermissionResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionPermissionsManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.RolePermissionDTO{
+		Role:       req.Role,
+		Permission: req.Permission,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.permissions.GrantPermission(ctx, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrPermissionNotFound):
+			return nil, status.Error(codes.NotFound, "permission not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to grant permission")
+		}
+	}
+	return &pb.GrantPermissionResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) RevokePermission(ctx context.Context, req *pb.RevokePermissionRequest) (*pb.RevokePermissionResponse, error) {
+	if _, err := h.authorize(ctx, constants.PermissionPermissionsManage); err != nil {
+		return nil, err
+	}
+	dto := &dto.RolePermissionDTO{
+		Role:       req.Role,
+		Permission: req.Permission,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.permissions.RevokePermission(ctx, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrPermissionNotGranted):
+			return nil, status.Error(codes.NotFound, "permission not granted")
+		case errors.Is(err, errs.ErrProtectedPermission):
+			return nil, status.Error(codes.FailedPrecondition, "permission cannot be revoked")
+		default:
+			return nil, status.Error(codes.Internal, "failed to revoke permission")
+		}
+	}
+	return &pb.RevokePermissionResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.LoginResponse, error) {
+	dto := &dto.VerifyMFADTO{
+		Token: req.MfaToken,
+		Code:  req.Code,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	tokens, err := h.mfa.VerifyMFA(ctx, dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidMFAToken):
+			return nil, status.Error(codes.Unauthenticated, "invalid mfaToken")
+		case errors.Is(err, errs.ErrInvalidMFACode):
+			return nil, status.Error(codes.Unauthenticated, "invalid mfa code")
+		case errors.Is(err, errs.ErrWaiterFired):
+			return nil, status.Error(codes.PermissionDenied, "waiter is fired")
+		case errors.Is(err, errs.ErrCustomerBlocked):
+			return nil, status.Error(codes.PermissionDenied, "customer is blocked")
+		default:
+			return nil, status.Error(codes.Internal, "failed to verify mfa")
+		}
+	}
+	return loginResponse(tokens), nil
+}
+
+func (h *ssoHandler) EnrollMFA(ctx context.Context, req *pb.EnrollMFARequest) (*pb.EnrollMFAResponse, error) {
+	caller, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := h.mfa.EnrollMFA(ctx, caller.EntityID, caller.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrMFAAlreadyEnabled):
+			return nil, status.Error(codes.FailedPrecondition, "mfa already enabled")
+		default:
+			return nil, status.Error(codes.Internal, "failed to enroll mfa")
+		}
+	}
+	return &pb.EnrollMFAResponse{Secret: enrollment.Secret, OtpauthUri: enrollment.URI}, nil
+}
+
+func (h *ssoHandler) ConfirmMFA(ctx context.Context, req *pb.ConfirmMFARequest) (*pb.ConfirmMFAResponse, error) {
+	caller, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+	recoveryCodes, err := h.mfa.ConfirmMFA(ctx, caller.EntityID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrMFANotEnabled):
+			return nil, status.Error(codes.FailedPrecondition, "mfa enrolment not started")
+		case errors.Is(err, errs.ErrMFAAlreadyEnabled):
+			return nil, status.Error(codes.FailedPrecondition, "mfa already enabled")
+		case errors.Is(err, errs.ErrInvalidMFACode):
+			return nil, status.Error(codes.InvalidArgument, "invalid mfa code")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
+		default:
+			return nil, status.Error(codes.Internal, "failed to confirm mfa")
+		}
+	}
+	return &pb.ConfirmMFAResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (h *ssoHandler) DisableMFA(ctx context.Context, req *pb.DisableMFARequest) (*pb.DisableMFAResponse, error) {
+	caller, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.mfa.DisableMFA(ctx, caller.EntityID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrMFANotEnabled):
+			return nil, status.Error(codes.FailedPrecondition, "mfa not enabled")
+		case errors.Is(err, errs.ErrInvalidMFACode):
+			return nil, status.Error(codes.InvalidArgument, "invalid mfa code")
+		case errors.Is(err, errs.ErrTooManyAttempts):
+			return nil, tooManyAttempts(ctx, err)
+		default:
+			return nil, status.Error(codes.Internal, "failed to disable mfa")
+		}
+	}
+	return &pb.DisableMFAResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	if err := h.verification.VerifyEmail(ctx, req.Token); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidVerifyToken):
+			return nil, status.Error(codes.InvalidArgument, "invalid verification token")
+		case errors.Is(err, errs.ErrCustomerAlreadyExists):
+			return nil, status.Error(codes.AlreadyExists, "Customer with this email already exists")
+		default:
+			return nil, status.Error(codes.Internal, "failed to verify email")
+		}
+	}
+	return &pb.VerifyEmailResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	if err := h.validate.Var(req.Email, "required,email"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.verification.ResendVerification(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, "failed to resend verification")
+	}
+	return &pb.ResendVerificationResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if err := h.validate.Var(req.Email, "required,email"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.password.RequestPasswordReset(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, "failed to request password reset")
+	}
+	return &pb.RequestPasswordResetResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	dto := &dto.ResetPasswordDTO{
+		Token:    req.Token,
+		Password: req.Password,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.password.ResetPassword(ctx, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidResetToken):
+			return nil, status.Error(codes.InvalidArgument, "invalid reset token")
+		case errors.Is(err, errs.ErrWeakPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "failed to reset password")
+		}
+	}
+	return &pb.ResetPasswordResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	caller, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dto := &dto.ChangePasswordDTO{
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.password.ChangePassword(ctx, caller.EntityID, caller.Role, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidPassword):
+			return nil, status.Error(codes.PermissionDenied, "invalid old password")
+		case errors.Is(err, errs.ErrIdentityNotFound):
+			return nil, status.Error(codes.NotFound, "account not found")
+		case errors.Is(err, errs.ErrWeakPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "failed to change password")
+		}
+	}
+	return &pb.ChangePasswordResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) ExportMyData(ctx context.Context, req *pb.ExportMyDataRequest) (*pb.ExportMyDataResponse, error) {
+	caller, err := h.authenticate(ctx, constants.RoleCustomer)
+	if err != nil {
+		return nil, err
+	}
+	data, err := h.account.ExportMyData(ctx, caller.EntityID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerNotFound):
+			return nil, status.Error(codes.NotFound, "account not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to export data")
+		}
+	}
+	return &pb.ExportMyDataResponse{Data: data}, nil
+}
+
+func (h *ssoHandler) RequestAccountDeletion(ctx context.Context, req *pb.RequestAccountDeletionRequest) (*pb.RequestAccountDeletionResponse, error) {
+	caller, err := h.authenticate(ctx, constants.RoleCustomer)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.account.RequestAccountDeletion(ctx, caller.EntityID); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerNotFound):
+			return nil, status.Error(codes.NotFound, "account not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to request account deletion")
+		}
+	}
+	return &pb.RequestAccountDeletionResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	caller, err := h.authenticate(ctx, constants.RoleCustomer)
+	if err != nil {
+		return nil, err
+	}
+	dto := &dto.DeleteAccountDTO{Password: req.Password, Token: req.Token}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	if err := h.account.DeleteAccount(ctx, caller.EntityID, dto); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidPassword):
+			return nil, status.Error(codes.PermissionDenied, "invalid password")
+		case errors.Is(err, errs.ErrInvalidDeletionToken):
+			return nil, status.Error(codes.PermissionDenied, "invalid deletion token")
+		case errors.Is(err, errs.ErrPasswordNotSet):
+			return nil, status.Error(codes.FailedPrecondition, "password is not set, confirm the deletion by email")
+		case errors.Is(err, errs.ErrCustomerNotFound), errors.Is(err, errs.ErrIdentityNotFound):
+			return nil, status.Error(codes.NotFound, "account not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to delete account")
+		}
+	}
+	return &pb.DeleteAccountResponse{Status: "OK"}, nil
+}
+
+func (h *ssoHandler) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.ProfileResponse, error) {
+	caller, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := h.profile.GetProfile(ctx, caller.EntityID, caller.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerNotFound), errors.Is(err, errs.ErrWaiterNotFound), errors.Is(err, errs.ErrAdminNotFound):
+			return nil, status.Error(codes.NotFound, "profile not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to get profile")
+		}
+	}
+	return profileResponse(profile), nil
+}
+
+func (h *ssoHandler) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.ProfileResponse, error) {
+	caller, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dto := &dto.UpdateProfileDTO{}
+	switch p := req.Profile.(type) {
+	case *pb.UpdateProfileRequest_Customer:
+		if caller.Role != constants.RoleCustomer {
+			return nil, status.Error(codes.InvalidArgument, "profile does not match caller role")
+		}
+		dto.Name = p.Customer.Name
+		dto.Email = p.Customer.Email
+	case *pb.UpdateProfileRequest_Waiter:
+		if caller.Role != constants.RoleWaiter {
+			return nil, status.Error(codes.InvalidArgument, "profile does not match caller role")
+		}
+		dto.FirstName = p.Waiter.FirstName
+		dto.LastName = p.Waiter.LastName
+	case *pb.UpdateProfileRequest_Admin:
+		if caller.Role != constants.RoleAdmin {
+			return nil, status.Error(codes.InvalidArgument, "profile does not match caller role")
+		}
+		dto.Note = &p.Admin.Note
+	default:
+		return nil, status.Error(codes.InvalidArgument, "profile is required")
+	}
+	if err := h.validate.Struct(dto); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload, error: %v", err)
+	}
+	profile, err := h.profile.UpdateProfile(ctx, caller.EntityID, caller.Role, dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerAlreadyExists):
+			return nil, status.Error(codes.AlreadyExists, "Customer with this email already exists")
+		case errors.Is(err, errs.ErrCustomerNotFound), errors.Is(err, errs.ErrWaiterNotFound), errors.Is(err, errs.ErrAdminNotFound):
+			return nil, status.Error(codes.NotFound, "profile not found")
+		default:
+			return nil, status.Error(codes.Internal, "failed to update profile")
+		}
+	}
+	return profileResponse(profile), nil
+}
+
+func loginResponse(tokens *dto.TokensDTO) *pb.LoginResponse {
+	if tokens.MFAToken != "" {
+		return &pb.LoginResponse{MfaRequired: true, MfaToken: tokens.MFAToken}
+	}
+	return &pb.LoginResponse{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+}
+
+func profileResponse(profile *dto.ProfileDTO) *pb.ProfileResponse {
+	switch {
+	case profile.Customer != nil:
+		c := profile.Customer
+		customer := &pb.CustomerProfile{
+			CustomerId:    c.CustomerID,
+			Email:         c.Email,
+			Name:          c.Name,
+			TotalSpent:    c.TotalSpent,
+			RegisteredAt:  c.RegisteredAt.Unix(),
+			EmailVerified: c.EmailVerifiedAt != nil,
+		}
+		if c.BirthDate != nil {
+			customer.Birthdate = c.BirthDate.Unix()
+		}
+		return &pb.ProfileResponse{Profile: &pb.ProfileResponse_Customer{Customer: customer}}
+	case profile.Waiter != nil:
+		w := profile.Waiter
+		return &pb.ProfileResponse{Profile: &pb.ProfileResponse_Waiter{Waiter: &pb.WaiterProfile{
+			WaiterId:  w.WaiterID,
+			Login:     w.Login,
+			FirstName: w.FirstName,
+			LastName:  w.LastName,
+			HiredAt:   w.HiredAt.Unix(),
+			Rating:    w.Rating,
+		}}}
+	case profile.Admin != nil:
+		return &pb.ProfileResponse{Profile: &pb.ProfileResponse_Admin{Admin: adminResponse(profile.Admin)}}
+	}
+	return &pb.ProfileResponse{}
+}
+
+func customerResponse(customer *entities.CustomerEntity) *pb.Customer {
+	res := &pb.Customer{
+		CustomerId:    customer.CustomerID,
+		Email:         customer.Email,
+		Name:          customer.Name,
+		TotalSpent:    customer.TotalSpent,
+		RegisteredAt:  customer.RegisteredAt.Unix(),
+		EmailVerified: customer.EmailVerifiedAt != nil,
+	}
+	if customer.BirthDate != nil {
+		res.Birthdate = customer.BirthDate.Unix()
+	}
+	if customer.BlockedAt != nil {
+		res.BlockedAt = customer.BlockedAt.Unix()
+	}
+	if customer.BlockedReason != nil {
+		res.BlockedReason = *customer.BlockedReason
+	}
+	return res
+}
+
+func adminResponse(admin *entities.AdminEntity) *pb.AdminProfile {
+	res := &pb.AdminProfile{AdminId: admin.AdminID, Login: admin.Login}
+	if admin.Note != nil {
+		res.Note = *admin.Note
+	}
+	return res
+}
+
+func waiterResponse(waiter *entities.WaiterEntity) *pb.Waiter {
+	res := &pb.Waiter{
+		WaiterId:  waiter.WaiterID,
+		Login:     waiter.Login,
+		FirstName: waiter.FirstName,
+		LastName:  waiter.LastName,
+		HiredAt:   waiter.HiredAt.Unix(),
+		Rating:    waiter.Rating,
+	}
+	if waiter.FiredAt != nil {
+		res.FiredAt = waiter.FiredAt.Unix()
+	}
+	if waiter.FiredReason != nil {
+		res.FiredReason = *waiter.FiredReason
+	}
+	return res
+}
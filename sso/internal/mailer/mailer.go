@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+)
+
+const (
+	driverSMTP   = "smtp"
+	driverFile   = "file"
+	driverMemory = "memory"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+func New(cfg config.MailerConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case driverSMTP:
+		return NewSMTPMailer(cfg), nil
+	case driverFile:
+		return NewFileMailer(cfg.Dir, cfg.From), nil
+	case driverMemory:
+		return NewMemoryMailer(), nil
+	}
+	return nil, fmt.Errorf("unknown mailer driver: %s", cfg.Driver)
+}
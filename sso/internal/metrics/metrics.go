@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	OutcomeSuccess            = "success"
+	OutcomeMFARequired        = "mfa_required"
+	OutcomeInvalidCredentials = "invalid_credentials"
+	OutcomeLocked             = "locked"
+	OutcomeBlocked            = "blocked"
+	OutcomeFired              = "fired"
+	OutcomeError              = "error"
+)
+
+var Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "sso_logins_total",
+	Help: "Password logins by role and outcome.",
+}, []string{"role", "outcome"})
+
+func RecordLogin(role string, tokens *dto.TokensDTO, err error) {
+	Logins.WithLabelValues(role, loginOutcome(tokens, err)).Inc()
+}
+
+func loginOutcome(tokens *dto.TokensDTO, err error) string {
+	switch {
+	case err == nil && tokens.MFAToken != "":
+		return OutcomeMFARequired
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, errs.ErrInvalidCredentials):
+		return OutcomeInvalidCredentials
+	case errors.Is(err, errs.ErrTooManyAttempts):
+		return OutcomeLocked
+	case errors.Is(err, errs.ErrCustomerBlocked):
+		return OutcomeBlocked
+	case errors.Is(err, errs.ErrWaiterFired):
+		return OutcomeFired
+	default:
+		return OutcomeError
+	}
+}
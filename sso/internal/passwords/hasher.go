@@ -0,0 +1,169 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+
+	argon2idPrefix = "$argon2id$"
+	saltLength     = 16
+	keyLength      = 32
+)
+
+var (
+	ErrMismatchedPassword = errors.New("password does not match")
+	ErrUnknownHash        = errors.New("unknown password hash format")
+)
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// Hasher hashes new passwords with the configured algorithm and verifies both
+// argon2id and legacy bcrypt hashes, so stored bcrypt hashes keep working until
+// they are upgraded on the next successful login.
+type Hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+}
+
+func NewHasher(cfg config.PasswordConfig) (*Hasher, error) {
+	switch cfg.Algorithm {
+	case AlgorithmArgon2id:
+		if err := validateArgon2(cfg.Argon2); err != nil {
+			return nil, err
+		}
+	case AlgorithmBcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cfg.BcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hashing algorithm: %s", cfg.Algorithm)
+	}
+	return &Hasher{
+		algorithm:  cfg.Algorithm,
+		bcryptCost: cfg.BcryptCost,
+		argon2: argon2Params{
+			memory:      cfg.Argon2.Memory,
+			iterations:  cfg.Argon2.Iterations,
+			parallelism: cfg.Argon2.Parallelism,
+		},
+	}, nil
+}
+
+// validateArgon2 rejects parameters argon2 would panic on or silently raise.
+func validateArgon2(cfg config.Argon2Config) error {
+	if cfg.Parallelism == 0 {
+		return errors.New("argon2 parallelism must be positive")
+	}
+	if cfg.Iterations == 0 {
+		return errors.New("argon2 iterations must be positive")
+	}
+	if cfg.Memory < 8*uint32(cfg.Parallelism) {
+		return fmt.Errorf("argon2 memory must be at least %d KiB for parallelism %d, got %d", 8*uint32(cfg.Parallelism), cfg.Parallelism, cfg.Memory)
+	}
+	return nil
+}
+
+func (h *Hasher) Hash(password string) ([]byte, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		return bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.argon2.iterations, h.argon2.memory, h.argon2.parallelism, keyLength)
+	return []byte(fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		h.argon2.memory,
+		h.argon2.iterations,
+		h.argon2.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)), nil
+}
+
+func (h *Hasher) Compare(hash []byte, password string) error {
+	if !isArgon2id(hash) {
+		if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrMismatchedPassword
+			}
+			return err
+		}
+		return nil
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// NeedsRehash reports whether hash was produced by a different algorithm or
+// with weaker parameters than the ones currently configured.
+func (h *Hasher) NeedsRehash(hash []byte) bool {
+	if h.algorithm == AlgorithmBcrypt {
+		cost, err := bcrypt.Cost(hash)
+		return err != nil || cost != h.bcryptCost
+	}
+
+	if !isArgon2id(hash) {
+		return true
+	}
+	params, _, _, err := decodeArgon2id(hash)
+	return err != nil || params != h.argon2
+}
+
+func isArgon2id(hash []byte) bool {
+	return strings.HasPrefix(string(hash), argon2idPrefix)
+}
+
+func decodeArgon2id(hash []byte) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHash
+	}
+	return params, salt, key, nil
+}
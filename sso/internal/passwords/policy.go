@@ -0,0 +1,72 @@
+package passwords
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+// minIdentifierLength keeps very short logins from rejecting most passwords.
+const minIdentifierLength = 3
+
+type Policy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+func NewPolicy(cfg config.PasswordConfig) (*Policy, error) {
+	policy := &Policy{minLength: cfg.MinLength, breached: make(map[string]struct{})}
+	if cfg.BreachedListPath == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(cfg.BreachedListPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		policy.breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return policy, nil
+}
+
+// Validate checks password against the policy. identifiers are the email or
+// login of the account, which must not appear inside the password.
+func (p *Policy) Validate(password string, identifiers ...string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("%w: must be at least %d characters long", errs.ErrWeakPassword, p.minLength)
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.breached[lower]; ok {
+		return fmt.Errorf("%w: password appears in a list of breached passwords", errs.ErrWeakPassword)
+	}
+
+	for _, identifier := range identifiers {
+		identifier = strings.ToLower(identifier)
+		if local, _, found := strings.Cut(identifier, "@"); found && utf8.RuneCountInString(local) >= minIdentifierLength {
+			if strings.Contains(lower, local) {
+				return fmt.Errorf("%w: must not contain your email", errs.ErrWeakPassword)
+			}
+		}
+		if utf8.RuneCountInString(identifier) >= minIdentifierLength && strings.Contains(lower, identifier) {
+			return fmt.Errorf("%w: must not contain your login", errs.ErrWeakPassword)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package passwords_test
+
+import (
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/passwords"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	argon2Config = config.PasswordConfig{
+		Algorithm: passwords.AlgorithmArgon2id,
+		Argon2:    config.Argon2Config{Memory: 1024, Iterations: 1, Parallelism: 1},
+	}
+	bcryptConfig = config.PasswordConfig{Algorithm: passwords.AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+)
+
+func TestNewHasher(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PasswordConfig
+		err  string
+	}{
+		{name: "argon2id", cfg: argon2Config},
+		{name: "bcrypt", cfg: bcryptConfig},
+		{
+			name: "unknown algorithm",
+			cfg:  config.PasswordConfig{Algorithm: "md5"},
+			err:  "unknown password hashing algorithm: md5",
+		},
+		{
+			name: "zero parallelism",
+			cfg:  config.PasswordConfig{Algorithm: passwords.AlgorithmArgon2id, Argon2: config.Argon2Config{Memory: 1024, Iterations: 1}},
+			err:  "argon2 parallelism must be positive",
+		},
+		{
+			name: "zero iterations",
+			cfg:  config.PasswordConfig{Algorithm: passwords.AlgorithmArgon2id, Argon2: config.Argon2Config{Memory: 1024, Parallelism: 1}},
+			err:  "argon2 iterations must be positive",
+		},
+		{
+			name: "too little memory",
+			cfg:  config.PasswordConfig{Algorithm: passwords.AlgorithmArgon2id, Argon2: config.Argon2Config{Memory: 8, Iterations: 1, Parallelism: 2}},
+			err:  "argon2 memory must be at least 16 KiB for parallelism 2, got 8",
+		},
+		{
+			name: "bcrypt cost too low",
+			cfg:  config.PasswordConfig{Algorithm: passwords.AlgorithmBcrypt, BcryptCost: 3},
+			err:  "bcrypt cost must be between 4 and 31, got 3",
+		},
+		{
+			name: "bcrypt cost too high",
+			cfg:  config.PasswordConfig{Algorithm: passwords.AlgorithmBcrypt, BcryptCost: 32},
+			err:  "bcrypt cost must be between 4 and 31, got 32",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := passwords.NewHasher(tt.cfg)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Nil(t, hasher)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, hasher)
+		})
+	}
+}
+
+func TestHasher_HashAndCompare(t *testing.T) {
+	for _, cfg := range []config.PasswordConfig{argon2Config, bcryptConfig} {
+		t.Run(cfg.Algorithm, func(t *testing.T) {
+			hasher, err := passwords.NewHasher(cfg)
+			assert.NoError(t, err)
+
+			hash, err := hasher.Hash("password")
+			assert.NoError(t, err)
+
+			assert.NoError(t, hasher.Compare(hash, "password"))
+			assert.ErrorIs(t, hasher.Compare(hash, "other password"), passwords.ErrMismatchedPassword)
+			assert.False(t, hasher.NeedsRehash(hash))
+		})
+	}
+
+	t.Run("salts every hash", func(t *testing.T) {
+		hasher, _ := passwords.NewHasher(argon2Config)
+
+		first, _ := hasher.Hash("password")
+		second, _ := hasher.Hash("password")
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("malformed argon2id hash", func(t *testing.T) {
+		hasher, _ := passwords.NewHasher(argon2Config)
+
+		err := hasher.Compare([]byte("$argon2id$v=19$m=1024$salt$key"), "password")
+
+		assert.ErrorIs(t, err, passwords.ErrUnknownHash)
+	})
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	argon2Hasher, _ := passwords.NewHasher(argon2Config)
+	bcryptHasher, _ := passwords.NewHasher(bcryptConfig)
+
+	bcryptHash, _ := bcryptHasher.Hash("password")
+	argon2Hash, _ := argon2Hasher.Hash("password")
+
+	t.Run("legacy bcrypt hash is upgraded", func(t *testing.T) {
+		assert.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+		assert.NoError(t, argon2Hasher.Compare(bcryptHash, "password"))
+	})
+
+	t.Run("weaker argon2id parameters", func(t *testing.T) {
+		cfg := argon2Config
+		cfg.Argon2.Iterations = 2
+		stronger, _ := passwords.NewHasher(cfg)
+
+		assert.True(t, stronger.NeedsRehash(argon2Hash))
+		assert.NoError(t, stronger.Compare(argon2Hash, "password"))
+	})
+
+	t.Run("different bcrypt cost", func(t *testing.T) {
+		cfg := bcryptConfig
+		cfg.BcryptCost = bcrypt.MinCost + 1
+		stronger, _ := passwords.NewHasher(cfg)
+
+		assert.True(t, stronger.NeedsRehash(bcryptHash))
+	})
+}
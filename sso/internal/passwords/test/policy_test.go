@@ -0,0 +1,54 @@
+package passwords_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/passwords"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "breached.txt")
+	assert.NoError(t, os.WriteFile(listPath, []byte("# common passwords\n\nSunshine1\n"), 0o600))
+
+	policy, err := passwords.NewPolicy(config.PasswordConfig{MinLength: 8, BreachedListPath: listPath})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		password    string
+		identifiers []string
+		err         string
+	}{
+		{name: "strong password", password: "correct horse", identifiers: []string{"test@example.com"}},
+		{name: "too short", password: "short", err: "must be at least 8 characters long"},
+		{name: "counts runes", password: "пароль12"},
+		{name: "breached ignoring case", password: "SUNSHINE1", err: "password appears in a list of breached passwords"},
+		{name: "contains login", password: "mywaiter2024", identifiers: []string{"Waiter"}, err: "must not contain your login"},
+		{name: "contains email local part", password: "john.doe-2024", identifiers: []string{"john.doe@example.com"}, err: "must not contain your email"},
+		{name: "short identifier ignored", password: "ab-password", identifiers: []string{"ab"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.identifiers...)
+			if tt.err != "" {
+				assert.ErrorIs(t, err, errs.ErrWeakPassword)
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewPolicy_MissingList(t *testing.T) {
+	policy, err := passwords.NewPolicy(config.PasswordConfig{BreachedListPath: filepath.Join(t.TempDir(), "missing.txt")})
+
+	assert.Nil(t, policy)
+	assert.ErrorContains(t, err, "failed to open breached password list")
+}
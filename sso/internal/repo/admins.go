@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
@@ -12,6 +13,8 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+const selectAdmins = "SELECT a.*, i.login FROM admins a JOIN identities i ON i.identity_id = a.identity_id"
+
 type adminRepo struct {
 	db *sqlx.DB
 }
@@ -20,9 +23,53 @@ func NewAdminRepo(db *sqlx.DB) *adminRepo {
 	return &adminRepo{db: db}
 }
 
-func (r *adminRepo) GetAdminByLogin(ctx context.Context, login string) (*entities.AdminEntity, error) {
+const insertAdmin = `
+	WITH admin AS (
+		INSERT INTO admins (identity_id, note) VALUES ($1, $2) RETURNING admin_id
+	)
+	INSERT INTO identity_roles (identity_id, role, entity_id)
+	SELECT $1, $3, admin_id FROM admin RETURNING entity_id`
+
+func (r *adminRepo) CreateAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error) {
+	var id uuid.UUID
+	if err := r.db.GetContext(ctx, &id, insertAdmin, dto.IdentityID, dto.Note, constants.RoleAdmin); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// CreateFirstAdmin creates the admin only while there is none. The table lock
+// makes concurrent bootstraps wait for each other, so only one of them can see
+// the table empty.
+func (r *adminRepo) CreateFirstAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE admins IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return uuid.Nil, err
+	}
+
+	var hasAdmins bool
+	if err := tx.GetContext(ctx, &hasAdmins, "SELECT EXISTS (SELECT 1 FROM admins)"); err != nil {
+		return uuid.Nil, err
+	}
+	if hasAdmins {
+		return uuid.Nil, errs.ErrAlreadyBootstrapped
+	}
+
+	var id uuid.UUID
+	if err := tx.GetContext(ctx, &id, insertAdmin, dto.IdentityID, dto.Note, constants.RoleAdmin); err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit()
+}
+
+func (r *adminRepo) GetAdminByID(ctx context.Context, adminID string) (*entities.AdminEntity, error) {
 	admin := new(entities.AdminEntity)
-	if err := r.db.GetContext(ctx, admin, "SELECT * FROM admins WHERE login = $1", login); err != nil {
+	if err := r.db.GetContext(ctx, admin, selectAdmins+" WHERE admin_id = $1", adminID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.ErrAdminNotFound
 		}
@@ -31,23 +78,26 @@ func (r *adminRepo) GetAdminByLogin(ctx context.Context, login string) (*entitie
 	return admin, nil
 }
 
-func (r *adminRepo) CheckLoginExists(ctx context.Context, login string) (bool, error) {
-	var isExists bool
-	if err := r.db.GetContext(ctx, &isExists, "SELECT TRUE FROM admins WHERE login = $1", login); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
-		}
-		return false, err
+func (r *adminRepo) UpdateAdminNote(ctx context.Context, adminID string, note string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE admins SET note = $2 WHERE admin_id = $1", adminID, note)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrAdminNotFound
 	}
-	return isExists, nil
+	return nil
 }
 
-func (r *adminRepo) CreateAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error) {
-	var id uuid.UUID
-	if err := r.db.GetContext(ctx, &id, `
-		INSERT INTO admins (login, password, note) VALUES ($1, $2, $3) RETURNING admin_id
-		`, dto.Login, dto.Password, dto.Note); err != nil {
-		return uuid.Nil, err
+func (r *adminRepo) ListAdmins(ctx context.Context, limit, offset int) ([]*entities.AdminEntity, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM admins"); err != nil {
+		return nil, 0, err
 	}
-	return id, nil
+
+	admins := make([]*entities.AdminEntity, 0)
+	if err := r.db.SelectContext(ctx, &admins, selectAdmins+" ORDER BY login LIMIT $1 OFFSET $2", limit, offset); err != nil {
+		return nil, 0, err
+	}
+	return admins, total, nil
 }
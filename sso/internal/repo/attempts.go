@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/redis/go-redis/v9"
+)
+
+type attemptsRepo struct {
+	db  *redis.Client
+	cfg config.LoginLimitConfig
+}
+
+func NewAttemptsRepo(db *redis.Client, cfg config.LoginLimitConfig) *attemptsRepo {
+	return &attemptsRepo{db: db, cfg: cfg}
+}
+
+// registerAttempt checks and counts an attempt in one step, so concurrent
+// guesses cannot slip past the limit. While any key is locked nothing is
+// counted; otherwise every counter is increased and a key over its limit is
+// locked, the lockout doubling with every attempt past it.
+//
+// KEYS: the attempts and lockout key of every limit, in pairs.
+// ARGV: window, base and max lockout in ms, then the limit of every pair.
+var registerAttempt = redis.NewScript(`
+local window, base, cap = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local locked = 0
+for i = 1, #KEYS, 2 do
+	locked = math.max(locked, redis.call('PTTL', KEYS[i + 1]))
+end
+if locked > 0 then
+	return locked
+end
+for i = 1, #KEYS, 2 do
+	local count = redis.call('INCR', KEYS[i])
+	redis.call('PEXPIRE', KEYS[i], window)
+	local over = count - tonumber(ARGV[3 + (i + 1) / 2])
+	if over > 0 then
+		local lockout = math.floor(math.min(base * 2 ^ (over - 1), cap))
+		redis.call('SET', KEYS[i + 1], count, 'PX', lockout)
+		locked = math.max(locked, lockout)
+	end
+end
+return locked
+`)
+
+// RegisterAttempt counts a login attempt and returns the lockout if it is not
+// allowed. An empty login counts the attempt against the IP only.
+func (r *attemptsRepo) RegisterAttempt(ctx context.Context, role, login, ip string) (time.Duration, error) {
+	limits := r.limits(role, login, ip)
+	if len(limits) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, 2*len(limits))
+	args := []any{r.cfg.Window.Milliseconds(), r.cfg.BaseLockout.Milliseconds(), r.cfg.MaxLockout.Milliseconds()}
+	for _, limit := range limits {
+		keys = append(keys, attemptsKey(limit.key), lockoutKey(limit.key))
+		args = append(args, limit.max)
+	}
+
+	lockout, err := registerAttempt.Run(ctx, r.db, keys, args...).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(lockout) * time.Millisecond, nil
+}
+
+// ResetAttempts clears the counter and lockout of the account only.
+func (r *attemptsRepo) ResetAttempts(ctx context.Context, role, login string) error {
+	key := accountKey(role, login)
+	return r.db.Del(ctx, attemptsKey(key), lockoutKey(key)).Err()
+}
+
+type attemptsLimit struct {
+	key string
+	max int
+}
+
+func (r *attemptsRepo) limits(role, login, ip string) []attemptsLimit {
+	limits := make([]attemptsLimit, 0, 2)
+	if login != "" {
+		limits = append(limits, attemptsLimit{key: accountKey(role, login), max: r.cfg.MaxAttempts})
+	}
+	if ip != "" {
+		limits = append(limits, attemptsLimit{key: ipKey(ip), max: r.cfg.MaxIPAttempts})
+	}
+	return limits
+}
+
+func accountKey(role, login string) string {
+	return fmt.Sprintf("account:%s:%s", role, login)
+}
+
+func ipKey(ip string) string {
+	return fmt.Sprintf("ip:%s", ip)
+}
+
+func attemptsKey(key string) string {
+	return fmt.Sprintf("login_attempts:%s", key)
+}
+
+func lockoutKey(key string) string {
+	return fmt.Sprintf("login_lockout:%s", key)
+}
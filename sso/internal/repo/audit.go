@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const insertAuditEvent = "INSERT INTO audit_log (entity_id, role, action) VALUES ($1, $2, $3)"
+
+type auditRepo struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepo(db *sqlx.DB) *auditRepo {
+	return &auditRepo{db: db}
+}
+
+func (r *auditRepo) RecordEvent(ctx context.Context, entityID, role, action string) error {
+	_, err := r.db.ExecContext(ctx, insertAuditEvent, entityID, role, action)
+	return err
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+const uniqueViolation = "23505"
+
 type customerRepo struct {
 	db *sqlx.DB
 }
@@ -33,10 +40,8 @@ func (r *customerRepo) GetCustomerByEmail(ctx context.Context, email string) (*e
 
 func (r *customerRepo) CheckEmailExists(ctx context.Context, email string) (bool, error) {
 	var isExists bool
-	if err := r.db.GetContext(ctx, &isExists, "SELECT TRUE FROM customers WHERE email = $1", email); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
-		}
+	query := "SELECT EXISTS (SELECT 1 FROM customers WHERE email = $1) OR EXISTS (SELECT 1 FROM identities WHERE login = $1)"
+	if err := r.db.GetContext(ctx, &isExists, query, email); err != nil {
 		return false, err
 	}
 	return isExists, nil
@@ -44,9 +49,175 @@ func (r *customerRepo) CheckEmailExists(ctx context.Context, email string) (bool
 
 func (r *customerRepo) CreateCustomer(ctx context.Context, dto *dto.CreateCustomerDTO) (uuid.UUID, error) {
 	var customerId uuid.UUID
-	query := "INSERT INTO customers (email, password, name, birth_date) VALUES ($1, $2, $3, $4) RETURNING customer_id"
-	if err := r.db.GetContext(ctx, &customerId, query, dto.Email, dto.Password, dto.Name, dto.Birthdate); err != nil {
+	query := `
+		WITH customer AS (
+			INSERT INTO customers (identity_id, email, name, birth_date, email_verified_at)
+			VALUES ($1, $2, $3, $4, CASE WHEN $6 THEN now() END) RETURNING customer_id
+		)
+		INSERT INTO identity_roles (identity_id, role, entity_id)
+		SELECT $1, $5, customer_id FROM customer RETURNING entity_id`
+	if err := r.db.GetContext(ctx, &customerId, query, dto.IdentityID, dto.Email, dto.Name, dto.Birthdate, constants.RoleCustomer, dto.EmailVerified); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return uuid.Nil, errs.ErrCustomerAlreadyExists
+		}
 		return uuid.Nil, err
 	}
 	return customerId, nil
 }
+
+func (r *customerRepo) GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	customer := new(entities.CustomerEntity)
+	if err := r.db.GetContext(ctx, customer, "SELECT * FROM customers WHERE customer_id = $1", customerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (r *customerRepo) VerifyEmail(ctx context.Context, customerID string, email string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var identityID string
+	query := "UPDATE customers SET email = $2, email_verified_at = now() WHERE customer_id = $1 RETURNING identity_id"
+	if err := tx.GetContext(ctx, &identityID, query, customerID, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrCustomerNotFound
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errs.ErrCustomerAlreadyExists
+		}
+		return err
+	}
+
+	// The login belongs to every role of the identity, so when staff roles
+	// share it the customer role moves to an identity of its own instead.
+	var shared bool
+	query = "SELECT EXISTS (SELECT 1 FROM identity_roles WHERE identity_id = $1 AND role <> $2)"
+	if err := tx.GetContext(ctx, &shared, query, identityID, constants.RoleCustomer); err != nil {
+		return err
+	}
+
+	if shared {
+		query = `
+			WITH identity AS (
+				INSERT INTO identities (login, password)
+				SELECT $2, password FROM identities WHERE identity_id = $1 RETURNING identity_id
+			), roles AS (
+				UPDATE identity_roles SET identity_id = (SELECT identity_id FROM identity) WHERE identity_id = $1 AND role = $3
+			), external AS (
+				UPDATE external_identities SET identity_id = (SELECT identity_id FROM identity) WHERE identity_id = $1
+			)
+			UPDATE customers SET identity_id = (SELECT identity_id FROM identity) WHERE customer_id = $4`
+		_, err = tx.ExecContext(ctx, query, identityID, email, constants.RoleCustomer, customerID)
+	} else {
+		_, err = tx.ExecContext(ctx, "UPDATE identities SET login = $2 WHERE identity_id = $1", identityID, email)
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errs.ErrCustomerAlreadyExists
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *customerRepo) UpdateCustomerName(ctx context.Context, customerID string, name string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE customers SET name = $2 WHERE customer_id = $1", customerID, name)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrCustomerNotFound
+	}
+	return nil
+}
+
+func (r *customerRepo) SearchCustomers(ctx context.Context, query string, limit, offset int) ([]*entities.CustomerEntity, int, error) {
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+	where := " WHERE email ILIKE $1 OR name ILIKE $1"
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM customers"+where, pattern); err != nil {
+		return nil, 0, err
+	}
+
+	customers := make([]*entities.CustomerEntity, 0)
+	if err := r.db.SelectContext(ctx, &customers,
+		"SELECT * FROM customers"+where+" ORDER BY registered_at DESC, customer_id LIMIT $2 OFFSET $3",
+		pattern, limit, offset,
+	); err != nil {
+		return nil, 0, err
+	}
+	return customers, total, nil
+}
+
+func (r *customerRepo) SetCustomerBlocked(ctx context.Context, customerID string, blocked bool, reason string) error {
+	query := `
+		UPDATE customers SET
+			blocked_at = CASE WHEN $2 THEN COALESCE(blocked_at, now()) ELSE NULL END,
+			blocked_reason = CASE WHEN $2 THEN NULLIF($3, '') ELSE NULL END
+		WHERE customer_id = $1`
+	res, err := r.db.ExecContext(ctx, query, customerID, blocked, reason)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrCustomerNotFound
+	}
+	return nil
+}
+
+func (r *customerRepo) DeleteCustomer(ctx context.Context, customerID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE customers SET
+			email = 'deleted-' || customer_id || '@deleted.invalid',
+			name = 'Deleted customer',
+			birth_date = NULL,
+			email_verified_at = NULL,
+			deleted_at = now()
+		WHERE customer_id = $1 AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, query, customerID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrCustomerNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_secrets WHERE entity_id = $1", customerID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM identity_roles WHERE role = $1 AND entity_id = $2", constants.RoleCustomer, customerID); err != nil {
+		return err
+	}
+	query = "DELETE FROM external_identities WHERE identity_id = (SELECT identity_id FROM customers WHERE customer_id = $1)"
+	if _, err := tx.ExecContext(ctx, query, customerID); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE identities SET login = 'deleted-' || identity_id, password = ''::BYTEA
+		WHERE identity_id = (SELECT identity_id FROM customers WHERE customer_id = $1)
+		AND NOT EXISTS (SELECT 1 FROM identity_roles r WHERE r.identity_id = identities.identity_id)`
+	if _, err := tx.ExecContext(ctx, query, customerID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, insertAuditEvent, customerID, constants.RoleCustomer, constants.AuditActionAccountDeleted); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
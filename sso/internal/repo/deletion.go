@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type deletionRepo struct {
+	db  *redis.Client
+	ttl time.Duration
+}
+
+func NewDeletionRepo(db *redis.Client, ttl time.Duration) *deletionRepo {
+	return &deletionRepo{db: db, ttl: ttl}
+}
+
+func (r *deletionRepo) GenerateDeletionToken(ctx context.Context, customerID string) (string, error) {
+	token := uuid.NewString()
+	if err := r.db.Set(ctx, deletionKey(token), customerID, r.ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *deletionRepo) ConsumeDeletionToken(ctx context.Context, token string) (string, error) {
+	customerID, err := r.db.GetDel(ctx, deletionKey(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", errs.ErrInvalidDeletionToken
+		}
+		return "", err
+	}
+	return customerID, nil
+}
+
+func deletionKey(token string) string {
+	return fmt.Sprintf("account_deletion:%s", token)
+}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type identityRepo struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepo(db *sqlx.DB) *identityRepo {
+	return &identityRepo{db: db}
+}
+
+func (r *identityRepo) GetIdentityByLogin(ctx context.Context, login string) (*entities.IdentityEntity, error) {
+	identity := new(entities.IdentityEntity)
+	if err := r.db.GetContext(ctx, identity, "SELECT * FROM identities WHERE login = $1", login); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *identityRepo) GetIdentityByEntity(ctx context.Context, role string, entityID string) (*entities.IdentityEntity, error) {
+	identity := new(entities.IdentityEntity)
+	query := `
+		SELECT i.* FROM identities i
+		JOIN identity_roles r ON r.identity_id = i.identity_id
+		WHERE r.role = $1 AND r.entity_id = $2`
+	if err := r.db.GetContext(ctx, identity, query, role, entityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *identityRepo) CreateIdentity(ctx context.Context, login string, password []byte) (string, error) {
+	var identityID string
+	query := "INSERT INTO identities (login, password) VALUES ($1, $2) RETURNING identity_id"
+	if err := r.db.GetContext(ctx, &identityID, query, login, password); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return "", errs.ErrLoginTaken
+		}
+		return "", err
+	}
+	return identityID, nil
+}
+
+func (r *identityRepo) DeleteIdentity(ctx context.Context, identityID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM identities WHERE identity_id = $1", identityID)
+	return err
+}
+
+func (r *identityRepo) UpdatePassword(ctx context.Context, identityID string, password []byte) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE identities SET password = $2 WHERE identity_id = $1", identityID, password)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrIdentityNotFound
+	}
+	return nil
+}
+
+func (r *identityRepo) GetRole(ctx context.Context, identityID string, role string) (*entities.IdentityRoleEntity, error) {
+	grant := new(entities.IdentityRoleEntity)
+	query := "SELECT * FROM identity_roles WHERE identity_id = $1 AND role = $2"
+	if err := r.db.GetContext(ctx, grant, query, identityID, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrRoleNotGranted
+		}
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (r *identityRepo) ListRoles(ctx context.Context, identityID string) ([]*entities.IdentityRoleEntity, error) {
+	roles := make([]*entities.IdentityRoleEntity, 0)
+	query := "SELECT * FROM identity_roles WHERE identity_id = $1 ORDER BY granted_at"
+	if err := r.db.SelectContext(ctx, &roles, query, identityID); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *identityRepo) GetIdentityByExternal(ctx context.Context, provider string, subject string) (*entities.IdentityEntity, error) {
+	identity := new(entities.IdentityEntity)
+	query := `
+		SELECT i.* FROM identities i
+		JOIN external_identities e ON e.identity_id = i.identity_id
+		WHERE e.provider = $1 AND e.subject = $2`
+	if err := r.db.GetContext(ctx, identity, query, provider, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *identityRepo) LinkExternalIdentity(ctx context.Context, identityID string, provider string, subject string, email string) error {
+	query := "INSERT INTO external_identities (provider, subject, identity_id, email) VALUES ($1, $2, $3, NULLIF($4, ''))"
+	if _, err := r.db.ExecContext(ctx, query, provider, subject, identityID, email); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errs.ErrExternalIdentityLinked
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type invitationRepo struct {
+	db *sqlx.DB
+}
+
+func NewInvitationRepo(db *sqlx.DB) *invitationRepo {
+	return &invitationRepo{db: db}
+}
+
+func (r *invitationRepo) CreateInvitation(ctx context.Context, dto *dto.CreateInvitationDTO) (*entities.InvitationEntity, error) {
+	invitation := new(entities.InvitationEntity)
+	query := `
+		INSERT INTO invitations (code_hash, role, first_name, last_name, note, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *`
+	if err := r.db.GetContext(ctx, invitation, query,
+		dto.CodeHash, dto.Role, dto.FirstName, dto.LastName, dto.Note, dto.CreatedBy, dto.ExpiresAt,
+	); err != nil {
+		// created_by references admins, so anyone else is refused here.
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == foreignKeyViolation {
+			return nil, errs.ErrInvitationNotAllowed
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepo) ConsumeInvitation(ctx context.Context, codeHash []byte, role string) (*entities.InvitationEntity, error) {
+	invitation := new(entities.InvitationEntity)
+	query := `
+		UPDATE invitations SET used_at = now()
+		WHERE code_hash = $1 AND role = $2 AND used_at IS NULL AND expires_at > now()
+		RETURNING *`
+	if err := r.db.GetContext(ctx, invitation, query, codeHash, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepo) ReleaseInvitation(ctx context.Context, invitationID string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE invitations SET used_at = NULL WHERE invitation_id = $1", invitationID)
+	return err
+}
@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+type mfaRepo struct {
+	db *sqlx.DB
+}
+
+func NewMFARepo(db *sqlx.DB) *mfaRepo {
+	return &mfaRepo{db: db}
+}
+
+func (r *mfaRepo) GetMFA(ctx context.Context, entityID string) (*entities.MFAEntity, error) {
+	mfa := new(entities.MFAEntity)
+	if err := r.db.GetContext(ctx, mfa, "SELECT * FROM mfa_secrets WHERE entity_id = $1", entityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrMFANotFound
+		}
+		return nil, err
+	}
+	return mfa, nil
+}
+
+func (r *mfaRepo) IsMFAEnabled(ctx context.Context, entityID string) (bool, error) {
+	var isEnabled bool
+	query := "SELECT TRUE FROM mfa_secrets WHERE entity_id = $1 AND confirmed_at IS NOT NULL"
+	if err := r.db.GetContext(ctx, &isEnabled, query, entityID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return isEnabled, nil
+}
+
+func (r *mfaRepo) SaveMFASecret(ctx context.Context, entityID, role, secret string) error {
+	query := `
+		INSERT INTO mfa_secrets (entity_id, role, secret) VALUES ($1, $2, $3)
+		ON CONFLICT (entity_id) DO UPDATE SET secret = $3, created_at = now(), confirmed_at = NULL
+		WHERE mfa_secrets.confirmed_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, entityID, role, secret)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+func (r *mfaRepo) ConfirmMFA(ctx context.Context, entityID string, recoveryCodeHashes [][]byte) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE mfa_secrets SET confirmed_at = now() WHERE entity_id = $1 AND confirmed_at IS NULL", entityID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrMFAAlreadyEnabled
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE entity_id = $1", entityID); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (entity_id, code_hash) VALUES ($1, $2)", entityID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, entityID string, codeHash []byte) (bool, error) {
+	query := "UPDATE mfa_recovery_codes SET used_at = now() WHERE entity_id = $1 AND code_hash = $2 AND used_at IS NULL"
+	res, err := r.db.ExecContext(ctx, query, entityID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *mfaRepo) UseTOTPStep(ctx context.Context, entityID string, step int64) (bool, error) {
+	query := "UPDATE mfa_secrets SET last_totp_step = $2 WHERE entity_id = $1 AND (last_totp_step IS NULL OR last_totp_step < $2)"
+	res, err := r.db.ExecContext(ctx, query, entityID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *mfaRepo) DeleteMFA(ctx context.Context, entityID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM mfa_secrets WHERE entity_id = $1", entityID)
+	return err
+}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+type oidcStateRepo struct {
+	db  *redis.Client
+	ttl time.Duration
+}
+
+func NewOIDCStateRepo(db *redis.Client, ttl time.Duration) *oidcStateRepo {
+	return &oidcStateRepo{db: db, ttl: ttl}
+}
+
+func (r *oidcStateRepo) SaveOIDCState(ctx context.Context, state string, entity *entities.OIDCStateEntity) error {
+	payload, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	return r.db.Set(ctx, oidcStateKey(state), payload, r.ttl).Err()
+}
+
+func (r *oidcStateRepo) ConsumeOIDCState(ctx context.Context, state string) (*entities.OIDCStateEntity, error) {
+	res, err := r.db.GetDel(ctx, oidcStateKey(state)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errs.ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+
+	entity := new(entities.OIDCStateEntity)
+	if err := json.Unmarshal(res, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func oidcStateKey(state string) string {
+	return fmt.Sprintf("oidc_state:%s", state)
+}
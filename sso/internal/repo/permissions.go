@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const foreignKeyViolation = "23503"
+
+type permissionRepo struct {
+	db *sqlx.DB
+}
+
+func NewPermissionRepo(db *sqlx.DB) *permissionRepo {
+	return &permissionRepo{db: db}
+}
+
+func (r *permissionRepo) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	permissions := make([]string, 0)
+	query := "SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission"
+	if err := r.db.SelectContext(ctx, &permissions, query, role); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *permissionRepo) GrantPermission(ctx context.Context, role string, permission string) error {
+	query := "INSERT INTO role_permissions (role, permission) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+	if _, err := r.db.ExecContext(ctx, query, role, permission); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == foreignKeyViolation {
+			return errs.ErrPermissionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *permissionRepo) RevokePermission(ctx context.Context, role string, permission string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM role_permissions WHERE role = $1 AND permission = $2", role, permission)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrPermissionNotGranted
+	}
+	return nil
+}
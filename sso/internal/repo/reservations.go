@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	"github.com/jmoiron/sqlx"
+)
+
+type reservationRepo struct {
+	db *sqlx.DB
+}
+
+func NewReservationRepo(db *sqlx.DB) *reservationRepo {
+	return &reservationRepo{db: db}
+}
+
+func (r *reservationRepo) GetCustomerReservations(ctx context.Context, customerID string) ([]*entities.ReservationEntity, error) {
+	reservations := make([]*entities.ReservationEntity, 0)
+	query := "SELECT * FROM reservations WHERE customer_id = $1 ORDER BY start_time DESC"
+	if err := r.db.SelectContext(ctx, &reservations, query, customerID); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
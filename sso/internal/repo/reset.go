@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type resetRepo struct {
+	db       *redis.Client
+	ttl      time.Duration
+	cooldown time.Duration
+}
+
+func NewResetRepo(db *redis.Client, ttl time.Duration, cooldown time.Duration) *resetRepo {
+	return &resetRepo{db: db, ttl: ttl, cooldown: cooldown}
+}
+
+// generateResetToken stores a new token and drops the one issued before it,
+// so only the latest reset link works.
+var generateResetToken = redis.NewScript(`
+local previous = redis.call('GET', KEYS[2])
+if previous then
+	redis.call('DEL', ARGV[3] .. previous)
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+redis.call('SET', KEYS[2], ARGV[4], 'PX', ARGV[2])
+return 1
+`)
+
+func (r *resetRepo) GenerateResetToken(ctx context.Context, customerID string) (string, error) {
+	token := uuid.NewString()
+	keys := []string{resetKey(token), customerResetKey(customerID)}
+	if err := generateResetToken.Run(ctx, r.db, keys, customerID, r.ttl.Milliseconds(), resetKey(""), token).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *resetRepo) GetResetToken(ctx context.Context, token string) (string, error) {
+	customerID, err := r.db.Get(ctx, resetKey(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", errs.ErrInvalidResetToken
+		}
+		return "", err
+	}
+	return customerID, nil
+}
+
+func (r *resetRepo) ConsumeResetToken(ctx context.Context, token string) (string, error) {
+	customerID, err := r.db.GetDel(ctx, resetKey(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", errs.ErrInvalidResetToken
+		}
+		return "", err
+	}
+	return customerID, nil
+}
+
+// StartResetCooldown reports false while an earlier reset request for the
+// email is still cooling down.
+func (r *resetRepo) StartResetCooldown(ctx context.Context, email string) (bool, error) {
+	return r.db.SetNX(ctx, resetCooldownKey(email), 1, r.cooldown).Result()
+}
+
+func resetKey(token string) string {
+	return fmt.Sprintf("password_reset:%s", token)
+}
+
+func customerResetKey(customerID string) string {
+	return fmt.Sprintf("password_reset_customer:%s", customerID)
+}
+
+func resetCooldownKey(email string) string {
+	return fmt.Sprintf("password_reset_request:%s", strings.ToLower(email))
+}
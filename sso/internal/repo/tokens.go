@@ -11,15 +11,19 @@ import (
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
 	"github.com/SergeyBogomolovv/restaurant/sso/pkg/payload"
+	"github.com/SergeyBogomolovv/restaurant/sso/pkg/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
+const maxMFAAttempts = 5
+
 type tokensRepo struct {
 	db         *redis.Client
 	refreshTTL time.Duration
 	accessTTL  time.Duration
+	mfaTTL     time.Duration
 	jwtSecret  []byte
 }
 
@@ -28,6 +32,7 @@ func NewTokensRepo(db *redis.Client, jwtConfig config.JwtConfig) *tokensRepo {
 		db:         db,
 		refreshTTL: jwtConfig.RefreshTTL,
 		accessTTL:  jwtConfig.AccessTTL,
+		mfaTTL:     jwtConfig.MFATTL,
 		jwtSecret:  []byte(jwtConfig.Secret),
 	}
 }
@@ -54,7 +59,11 @@ func (r *tokensRepo) GenerateRefreshToken(ctx context.Context, entityID string,
 	if err != nil {
 		return "", err
 	}
-	if err := r.db.Set(ctx, tokenKey(tokenID), payload, r.refreshTTL).Err(); err != nil {
+	pipe := r.db.TxPipeline()
+	pipe.Set(ctx, tokenKey(tokenID), payload, r.refreshTTL)
+	pipe.SAdd(ctx, entityTokensKey(entityID), tokenID)
+	pipe.Expire(ctx, entityTokensKey(entityID), r.refreshTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return "", err
 	}
 
@@ -80,7 +89,7 @@ func (r *tokensRepo) VerifyRefreshToken(ctx context.Context, token string) (*pay
 	if refreshToken.ExpiresAt.Before(time.Now()) {
 		return nil, errs.ErrInvalidJwtToken
 	}
-	return &payload.JwtPayload{EntityID: refreshToken.EntityID, Role: refreshToken.Role}, nil
+	return &payload.JwtPayload{EntityID: refreshToken.EntityID, Role: refreshToken.Role, ExpiresAt: refreshToken.ExpiresAt}, nil
 }
 
 func (r *tokensRepo) RevokeRefreshToken(ctx context.Context, token string) error {
@@ -91,6 +100,19 @@ func (r *tokensRepo) RevokeRefreshToken(ctx context.Context, token string) error
 	return r.db.Del(ctx, tokenKey(tokenID)).Err()
 }
 
+func (r *tokensRepo) RevokeAllRefreshTokens(ctx context.Context, entityID string) error {
+	tokenIDs, err := r.db.SMembers(ctx, entityTokensKey(entityID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{entityTokensKey(entityID)}
+	for _, tokenID := range tokenIDs {
+		keys = append(keys, tokenKey(tokenID))
+	}
+	return r.db.Del(ctx, keys...).Err()
+}
+
 func (r *tokensRepo) verifyRefreshTokenID(jwtToken string) (string, error) {
 	parsed, err := jwt.Parse(jwtToken, func(token *jwt.Token) (interface{}, error) {
 		return r.jwtSecret, nil
@@ -101,18 +123,91 @@ func (r *tokensRepo) verifyRefreshTokenID(jwtToken string) (string, error) {
 	return parsed.Claims.GetSubject()
 }
 
-func (r *tokensRepo) SignAccessToken(entityID string, role string) (string, error) {
+func (r *tokensRepo) SignAccessToken(entityID string, role string, permissions []string) (string, error) {
 	iat := time.Now()
 	exp := iat.Add(r.accessTTL)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Subject:   entityID,
-		Audience:  jwt.ClaimStrings{role},
-		IssuedAt:  jwt.NewNumericDate(iat),
-		ExpiresAt: jwt.NewNumericDate(exp),
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload.AccessClaims{
+		Permissions: permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   entityID,
+			Audience:  jwt.ClaimStrings{role},
+			IssuedAt:  jwt.NewNumericDate(iat),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
 	})
 	return token.SignedString(r.jwtSecret)
 }
 
+func (r *tokensRepo) VerifyAccessToken(token string) (*payload.JwtPayload, error) {
+	payload, err := utils.VerifyToken(token, r.jwtSecret)
+	if err != nil {
+		return nil, errs.ErrInvalidJwtToken
+	}
+	return payload, nil
+}
+
+func (r *tokensRepo) GenerateMFAToken(ctx context.Context, entityID string, role string) (string, error) {
+	token := uuid.NewString()
+	key := mfaTokenKey(token)
+
+	pipe := r.db.TxPipeline()
+	pipe.HSet(ctx, key, "entity_id", entityID, "role", role, "attempts", 0)
+	pipe.Expire(ctx, key, r.mfaTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *tokensRepo) VerifyMFAToken(ctx context.Context, token string) (*payload.JwtPayload, error) {
+	res, err := r.db.HGetAll(ctx, mfaTokenKey(token)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errs.ErrInvalidMFAToken
+	}
+	return &payload.JwtPayload{EntityID: res["entity_id"], Role: res["role"]}, nil
+}
+
+// failMFAToken counts a failed attempt, dropping the token at the limit. An
+// expired token is left alone rather than recreated without a TTL.
+var failMFAToken = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if attempts >= tonumber(ARGV[1]) then
+	redis.call('DEL', KEYS[1])
+end
+return attempts
+`)
+
+func (r *tokensRepo) FailMFAToken(ctx context.Context, token string) error {
+	return failMFAToken.Run(ctx, r.db, []string{mfaTokenKey(token)}, maxMFAAttempts).Err()
+}
+
+// ConsumeMFAToken deletes the token and fails if it is already gone, so of
+// concurrent verifications with the same token only one gets a session.
+func (r *tokensRepo) ConsumeMFAToken(ctx context.Context, token string) error {
+	deleted, err := r.db.Del(ctx, mfaTokenKey(token)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errs.ErrInvalidMFAToken
+	}
+	return nil
+}
+
 func tokenKey(tokenID string) string {
 	return fmt.Sprintf("refresh_token:%s", tokenID)
 }
+
+func entityTokensKey(entityID string) string {
+	return fmt.Sprintf("refresh_tokens:%s", entityID)
+}
+
+func mfaTokenKey(token string) string {
+	return fmt.Sprintf("mfa_token:%s", token)
+}
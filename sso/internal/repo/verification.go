@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type verificationRepo struct {
+	db       *redis.Client
+	ttl      time.Duration
+	cooldown time.Duration
+}
+
+func NewVerificationRepo(db *redis.Client, ttl time.Duration, cooldown time.Duration) *verificationRepo {
+	return &verificationRepo{db: db, ttl: ttl, cooldown: cooldown}
+}
+
+func (r *verificationRepo) GenerateVerificationToken(ctx context.Context, customerID string, email string) (string, error) {
+	token := uuid.NewString()
+	payload, err := json.Marshal(&entities.EmailVerificationEntity{CustomerID: customerID, Email: email})
+	if err != nil {
+		return "", err
+	}
+	if err := r.db.Set(ctx, verificationKey(token), payload, r.ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *verificationRepo) ConsumeVerificationToken(ctx context.Context, token string) (*entities.EmailVerificationEntity, error) {
+	res, err := r.db.GetDel(ctx, verificationKey(token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errs.ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+
+	verification := new(entities.EmailVerificationEntity)
+	if err := json.Unmarshal(res, verification); err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+// StartResendCooldown reports false while an earlier resend for the customer
+// is still cooling down.
+func (r *verificationRepo) StartResendCooldown(ctx context.Context, customerID string) (bool, error) {
+	return r.db.SetNX(ctx, resendCooldownKey(customerID), 1, r.cooldown).Result()
+}
+
+func verificationKey(token string) string {
+	return fmt.Sprintf("email_verification:%s", token)
+}
+
+func resendCooldownKey(customerID string) string {
+	return fmt.Sprintf("email_verification_resend:%s", customerID)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
@@ -12,6 +14,8 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+const selectWaiters = "SELECT w.*, i.login FROM waiters w JOIN identities i ON i.identity_id = w.identity_id"
+
 type waiterRepo struct {
 	db *sqlx.DB
 }
@@ -20,9 +24,23 @@ func NewWaiterRepo(db *sqlx.DB) *waiterRepo {
 	return &waiterRepo{db: db}
 }
 
-func (r *waiterRepo) GetWaiterByLogin(ctx context.Context, login string) (*entities.WaiterEntity, error) {
+func (r *waiterRepo) CreateWaiter(ctx context.Context, dto *dto.CreateWaiterDTO) (uuid.UUID, error) {
+	var id uuid.UUID
+	query := `
+		WITH waiter AS (
+			INSERT INTO waiters (identity_id, first_name, last_name) VALUES ($1, $2, $3) RETURNING waiter_id
+		)
+		INSERT INTO identity_roles (identity_id, role, entity_id)
+		SELECT $1, $4, waiter_id FROM waiter RETURNING entity_id`
+	if err := r.db.GetContext(ctx, &id, query, dto.IdentityID, dto.FirstName, dto.LastName, constants.RoleWaiter); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func (r *waiterRepo) GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error) {
 	waiter := new(entities.WaiterEntity)
-	if err := r.db.GetContext(ctx, waiter, "SELECT * FROM waiters WHERE login = $1", login); err != nil {
+	if err := r.db.GetContext(ctx, waiter, selectWaiters+" WHERE waiter_id = $1", waiterID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.ErrWaiterNotFound
 		}
@@ -31,23 +49,79 @@ func (r *waiterRepo) GetWaiterByLogin(ctx context.Context, login string) (*entit
 	return waiter, nil
 }
 
-func (r *waiterRepo) CheckLoginExists(ctx context.Context, login string) (bool, error) {
-	var isExists bool
-	if err := r.db.GetContext(ctx, &isExists, "SELECT TRUE FROM waiters WHERE login = $1", login); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
-		}
-		return false, err
+func (r *waiterRepo) UpdateWaiterName(ctx context.Context, waiterID string, firstName string, lastName string) error {
+	query := "UPDATE waiters SET first_name = $2, last_name = $3 WHERE waiter_id = $1"
+	res, err := r.db.ExecContext(ctx, query, waiterID, firstName, lastName)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrWaiterNotFound
 	}
-	return isExists, nil
+	return nil
 }
 
-func (r *waiterRepo) CreateWaiter(ctx context.Context, dto *dto.CreateWaiterDTO) (uuid.UUID, error) {
-	var id uuid.UUID
-	if err := r.db.GetContext(ctx, &id, `
-		INSERT INTO waiters (login, password, first_name, last_name) VALUES ($1, $2, $3, $4) RETURNING waiter_id
-		`, dto.Login, dto.Password, dto.FirstName, dto.LastName); err != nil {
-		return uuid.Nil, err
+// FireWaiter only fires a waiter who is still active, so that a concurrent
+// request cannot overwrite the original fired_at and reason.
+func (r *waiterRepo) FireWaiter(ctx context.Context, waiterID string, reason string) error {
+	query := "UPDATE waiters SET fired_at = now(), fired_reason = $2 WHERE waiter_id = $1 AND fired_at IS NULL"
+	res, err := r.db.ExecContext(ctx, query, waiterID, reason)
+	if err != nil {
+		return err
 	}
-	return id, nil
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errs.ErrWaiterFired
+	}
+	return nil
+}
+
+func (r *waiterRepo) RehireWaiter(ctx context.Context, waiterID string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE waiters SET fired_at = NULL, fired_reason = NULL WHERE waiter_id = $1", waiterID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return errs.ErrWaiterNotFound
+	}
+	return nil
+}
+
+var waiterSortColumns = map[string]string{
+	"rating":   "rating",
+	"hired_at": "hired_at",
+}
+
+func (r *waiterRepo) ListWaiters(ctx context.Context, filter *dto.WaiterFilterDTO) ([]*entities.WaiterEntity, int, error) {
+	where := ""
+	switch filter.Status {
+	case "active":
+		where = " WHERE fired_at IS NULL"
+	case "fired":
+		where = " WHERE fired_at IS NOT NULL"
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM waiters"+where); err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := waiterSortColumns[filter.SortBy]
+	if !ok {
+		column = "hired_at"
+	}
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+	query := fmt.Sprintf("%s%s ORDER BY %s %s, waiter_id LIMIT $1 OFFSET $2", selectWaiters, where, column, direction)
+
+	waiters := make([]*entities.WaiterEntity, 0)
+	if err := r.db.SelectContext(ctx, &waiters, query, filter.Limit, filter.Offset); err != nil {
+		return nil, 0, err
+	}
+	return waiters, total, nil
 }
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type IdentityLookupRepo interface {
+	GetIdentityByEntity(ctx context.Context, role string, entityID string) (*entities.IdentityEntity, error)
+}
+
+type CustomerAccountRepo interface {
+	GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error)
+	DeleteCustomer(ctx context.Context, customerID string) error
+}
+
+type ReservationHistoryRepo interface {
+	GetCustomerReservations(ctx context.Context, customerID string) ([]*entities.ReservationEntity, error)
+}
+
+type AuditRepo interface {
+	RecordEvent(ctx context.Context, entityID, role, action string) error
+}
+
+type DeletionRepo interface {
+	GenerateDeletionToken(ctx context.Context, customerID string) (string, error)
+	ConsumeDeletionToken(ctx context.Context, token string) (string, error)
+}
+
+type accountUsecase struct {
+	identities   IdentityLookupRepo
+	customers    CustomerAccountRepo
+	reservations ReservationHistoryRepo
+	audit        AuditRepo
+	tokens       TokensRevoker
+	deletions    DeletionRepo
+	mailer       Mailer
+	hasher       PasswordHasher
+	deleteURL    string
+	log          *slog.Logger
+}
+
+func NewAccountUsecase(
+	log *slog.Logger,
+	identities IdentityLookupRepo,
+	customers CustomerAccountRepo,
+	reservations ReservationHistoryRepo,
+	audit AuditRepo,
+	tokens TokensRevoker,
+	deletions DeletionRepo,
+	mailer Mailer,
+	hasher PasswordHasher,
+	deleteURL string,
+) *accountUsecase {
+	return &accountUsecase{
+		identities:   identities,
+		customers:    customers,
+		reservations: reservations,
+		audit:        audit,
+		tokens:       tokens,
+		deletions:    deletions,
+		mailer:       mailer,
+		hasher:       hasher,
+		deleteURL:    deleteURL,
+		log:          log,
+	}
+}
+
+func (u *accountUsecase) ExportMyData(ctx context.Context, customerID string) ([]byte, error) {
+	const op = "account.ExportData"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", customerID))
+
+	customer, err := u.getCustomer(ctx, customerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get customer", "error", err)
+		return nil, err
+	}
+
+	reservations, err := u.reservations.GetCustomerReservations(ctx, customerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get reservations", "error", err)
+		return nil, err
+	}
+
+	export := &dto.DataExportDTO{
+		ExportedAt: time.Now().UTC(),
+		Customer: dto.CustomerExportDTO{
+			CustomerID:      customer.CustomerID,
+			Email:           customer.Email,
+			Name:            customer.Name,
+			BirthDate:       customer.BirthDate,
+			TotalSpent:      customer.TotalSpent,
+			RegisteredAt:    customer.RegisteredAt,
+			EmailVerifiedAt: customer.EmailVerifiedAt,
+		},
+		Reservations: make([]dto.ReservationExportDTO, 0, len(reservations)),
+	}
+	for _, reservation := range reservations {
+		export.Reservations = append(export.Reservations, dto.ReservationExportDTO{
+			ReservationID: reservation.ReservationID,
+			TableID:       reservation.TableID,
+			StartTime:     reservation.StartTime,
+			EndTime:       reservation.EndTime,
+			Status:        reservation.Status,
+		})
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to marshal export", "error", err)
+		return nil, err
+	}
+
+	if err := u.audit.RecordEvent(ctx, customerID, constants.RoleCustomer, constants.AuditActionDataExported); err != nil {
+		log.ErrorContext(ctx, "failed to record audit event", "error", err)
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "customer data exported")
+	return data, nil
+}
+
+// RequestAccountDeletion emails a link confirming the deletion, the way for
+// customers signed up through an identity provider, who have no password, to
+// prove they are still in control of the account.
+func (u *accountUsecase) RequestAccountDeletion(ctx context.Context, customerID string) error {
+	const op = "account.RequestDeletion"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", customerID))
+
+	log.InfoContext(ctx, "requesting account deletion")
+
+	customer, err := u.getCustomer(ctx, customerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get customer", "error", err)
+		return err
+	}
+
+	token, err := u.deletions.GenerateDeletionToken(ctx, customerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate deletion token", "error", err)
+		return err
+	}
+
+	link, err := tokenLink(u.deleteURL, token)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to build deletion link", "error", err)
+		return err
+	}
+
+	body := fmt.Sprintf("Confirm deleting your account by following the link: %s", link)
+	if err := u.mailer.Send(ctx, customer.Email, "Account deletion", body); err != nil {
+		log.ErrorContext(ctx, "failed to send deletion email", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "account deletion email sent")
+	return nil
+}
+
+func (u *accountUsecase) DeleteAccount(ctx context.Context, customerID string, payload *dto.DeleteAccountDTO) error {
+	const op = "account.Delete"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", customerID))
+
+	log.InfoContext(ctx, "deleting account")
+
+	if _, err := u.getCustomer(ctx, customerID); err != nil {
+		log.ErrorContext(ctx, "failed to get customer", "error", err)
+		return err
+	}
+
+	if payload.Token != "" {
+		if err := u.consumeDeletionToken(ctx, log, customerID, payload.Token); err != nil {
+			return err
+		}
+	} else if err := u.comparePassword(ctx, log, customerID, payload.Password); err != nil {
+		return err
+	}
+
+	// Revoked first, so a failure leaves the account to retry the deletion on
+	// rather than deleted with its sessions still alive.
+	if err := u.tokens.RevokeAllRefreshTokens(ctx, customerID); err != nil {
+		log.ErrorContext(ctx, "failed to revoke refresh tokens", "error", err)
+		return err
+	}
+
+	if err := u.customers.DeleteCustomer(ctx, customerID); err != nil {
+		log.ErrorContext(ctx, "failed to delete customer", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "account deleted")
+	return nil
+}
+
+func (u *accountUsecase) comparePassword(ctx context.Context, log *slog.Logger, customerID string, password string) error {
+	identity, err := u.identities.GetIdentityByEntity(ctx, constants.RoleCustomer, customerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get identity", "error", err)
+		return err
+	}
+	if len(identity.Password) == 0 {
+		log.InfoContext(ctx, "password not set")
+		return errs.ErrPasswordNotSet
+	}
+	if err := u.hasher.Compare(identity.Password, password); err != nil {
+		log.InfoContext(ctx, "invalid password")
+		return errs.ErrInvalidPassword
+	}
+	return nil
+}
+
+func (u *accountUsecase) consumeDeletionToken(ctx context.Context, log *slog.Logger, customerID string, token string) error {
+	owner, err := u.deletions.ConsumeDeletionToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidDeletionToken) {
+			log.InfoContext(ctx, "invalid deletion token")
+			return errs.ErrInvalidDeletionToken
+		}
+		log.ErrorContext(ctx, "failed to consume deletion token", "error", err)
+		return err
+	}
+	if owner != customerID {
+		log.InfoContext(ctx, "deletion token issued to another customer")
+		return errs.ErrInvalidDeletionToken
+	}
+	return nil
+}
+
+func (u *accountUsecase) getCustomer(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	customer, err := u.customers.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, errs.ErrCustomerNotFound) {
+			return nil, errs.ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	if customer.DeletedAt != nil {
+		return nil, errs.ErrCustomerNotFound
+	}
+	return customer, nil
+}
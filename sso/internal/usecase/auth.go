@@ -4,154 +4,270 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/metrics"
 	"github.com/SergeyBogomolovv/restaurant/sso/pkg/payload"
-	"golang.org/x/crypto/bcrypt"
 )
 
-type CustomerAuthRepo interface {
-	GetCustomerByEmail(ctx context.Context, email string) (*entities.CustomerEntity, error)
+type IdentityAuthRepo interface {
+	GetIdentityByLogin(ctx context.Context, login string) (*entities.IdentityEntity, error)
+	GetRole(ctx context.Context, identityID string, role string) (*entities.IdentityRoleEntity, error)
+	UpdatePassword(ctx context.Context, identityID string, password []byte) error
+}
+
+type PasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	Compare(hash []byte, password string) error
+	NeedsRehash(hash []byte) bool
 }
 
-type AdminAuthRepo interface {
-	GetAdminByLogin(ctx context.Context, login string) (*entities.AdminEntity, error)
+type CustomerAuthRepo interface {
+	GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error)
 }
 
 type WaiterAuthRepo interface {
-	GetWaiterByLogin(ctx context.Context, login string) (*entities.WaiterEntity, error)
+	GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error)
 }
 
 type TokensRepo interface {
 	GenerateRefreshToken(ctx context.Context, entityID string, role string) (string, error)
 	VerifyRefreshToken(ctx context.Context, token string) (*payload.JwtPayload, error)
 	RevokeRefreshToken(ctx context.Context, token string) error
-	SignAccessToken(entityID string, role string) (string, error)
+	SignAccessToken(entityID string, role string, permissions []string) (string, error)
+	VerifyAccessToken(token string) (*payload.JwtPayload, error)
+	GenerateMFAToken(ctx context.Context, entityID string, role string) (string, error)
+}
+
+type MFAStatusRepo interface {
+	IsMFAEnabled(ctx context.Context, entityID string) (bool, error)
+}
+
+type AttemptsRepo interface {
+	RegisterAttempt(ctx context.Context, role, login, ip string) (time.Duration, error)
+	ResetAttempts(ctx context.Context, role, login string) error
 }
 
 type authUsecase struct {
-	customers CustomerAuthRepo
-	admins    AdminAuthRepo
-	waiters   WaiterAuthRepo
-	tokens    TokensRepo
-	log       *slog.Logger
+	identities  IdentityAuthRepo
+	customers   CustomerAuthRepo
+	waiters     WaiterAuthRepo
+	tokens      TokensRepo
+	permissions PermissionsRepo
+	mfa         MFAStatusRepo
+	attempts    AttemptsRepo
+	hasher      PasswordHasher
+	log         *slog.Logger
 }
 
-func NewAuthUsecase(log *slog.Logger, customers CustomerAuthRepo, waiters WaiterAuthRepo, admins AdminAuthRepo, tokens TokensRepo) *authUsecase {
+func NewAuthUsecase(
+	log *slog.Logger,
+	identities IdentityAuthRepo,
+	customers CustomerAuthRepo,
+	waiters WaiterAuthRepo,
+	tokens TokensRepo,
+	permissions PermissionsRepo,
+	mfa MFAStatusRepo,
+	attempts AttemptsRepo,
+	hasher PasswordHasher,
+) *authUsecase {
 	return &authUsecase{
-		customers: customers,
-		tokens:    tokens,
-		admins:    admins,
-		waiters:   waiters,
-		log:       log,
+		identities:  identities,
+		customers:   customers,
+		waiters:     waiters,
+		tokens:      tokens,
+		permissions: permissions,
+		mfa:         mfa,
+		attempts:    attempts,
+		hasher:      hasher,
+		log:         log,
 	}
 }
 
-func (u *authUsecase) LoginCustomer(ctx context.Context, loginCustomerDto *dto.LoginCustomerDTO) (*dto.TokensDTO, error) {
-	const op = "login.Customer"
-	log := u.log.With(slog.String("op", op), slog.String("email", loginCustomerDto.Email))
+func (u *authUsecase) LoginCustomer(ctx context.Context, payload *dto.LoginCustomerDTO) (*dto.TokensDTO, error) {
+	return u.Login(ctx, &dto.LoginDTO{
+		Login:    payload.Email,
+		Password: payload.Password,
+		Role:     constants.RoleCustomer,
+		IP:       payload.IP,
+	})
+}
 
-	log.Info("logging in customer")
+func (u *authUsecase) LoginAdmin(ctx context.Context, payload *dto.LoginEmployeeDTO) (*dto.TokensDTO, error) {
+	return u.Login(ctx, &dto.LoginDTO{
+		Login:    payload.Login,
+		Password: payload.Password,
+		Role:     constants.RoleAdmin,
+		IP:       payload.IP,
+	})
+}
 
-	customer, err := u.customers.GetCustomerByEmail(ctx, loginCustomerDto.Email)
+func (u *authUsecase) LoginWaiter(ctx context.Context, payload *dto.LoginEmployeeDTO) (*dto.TokensDTO, error) {
+	return u.Login(ctx, &dto.LoginDTO{
+		Login:    payload.Login,
+		Password: payload.Password,
+		Role:     constants.RoleWaiter,
+		IP:       payload.IP,
+	})
+}
+
+func (u *authUsecase) Login(ctx context.Context, payload *dto.LoginDTO) (*dto.TokensDTO, error) {
+	tokens, err := u.login(ctx, payload)
+	metrics.RecordLogin(payload.Role, tokens, err)
+	return tokens, err
+}
+
+func (u *authUsecase) login(ctx context.Context, payload *dto.LoginDTO) (*dto.TokensDTO, error) {
+	const op = "auth.Login"
+	log := u.log.With(slog.String("op", op), slog.String("role", payload.Role), slog.String("login", payload.Login))
+
+	log.InfoContext(ctx, "logging in")
+
+	identity, err := u.identities.GetIdentityByLogin(ctx, payload.Login)
 	if err != nil {
-		if errors.Is(err, errs.ErrCustomerNotFound) {
-			log.Info("customer not found")
+		if errors.Is(err, errs.ErrIdentityNotFound) {
+			log.InfoContext(ctx, "identity not found")
+			if err := registerAttempt(ctx, log, u.attempts, payload.Role, "", payload.IP); err != nil {
+				return nil, err
+			}
 			return nil, errs.ErrInvalidCredentials
 		}
-		log.Error("failed to get customer by email", "error", err)
+		log.ErrorContext(ctx, "failed to get identity by login", "error", err)
 		return nil, err
 	}
 
-	if err := ComparePassword(customer.Password, loginCustomerDto.Password); err != nil {
-		log.Info("invalid password")
+	if err := registerAttempt(ctx, log, u.attempts, payload.Role, payload.Login, payload.IP); err != nil {
+		return nil, err
+	}
+
+	if err := u.hasher.Compare(identity.Password, payload.Password); err != nil {
+		log.InfoContext(ctx, "invalid password")
 		return nil, errs.ErrInvalidCredentials
 	}
 
-	accessToken, err := u.tokens.SignAccessToken(customer.CustomerID, constants.RoleCustomer)
+	grant, err := u.identities.GetRole(ctx, identity.IdentityID, payload.Role)
 	if err != nil {
-		log.Error("failed to sign access token", "error", err)
+		if errors.Is(err, errs.ErrRoleNotGranted) {
+			log.InfoContext(ctx, "role not granted")
+			return nil, errs.ErrInvalidCredentials
+		}
+		log.ErrorContext(ctx, "failed to get identity role", "error", err)
 		return nil, err
 	}
+	resetAttempts(ctx, log, u.attempts, payload.Role, payload.Login)
 
-	refreshToken, err := u.tokens.GenerateRefreshToken(ctx, customer.CustomerID, constants.RoleCustomer)
-	if err != nil {
-		log.Error("failed to generate refresh token", "error", err)
+	if err := u.CheckStatus(ctx, payload.Role, grant.EntityID); err != nil {
+		if errors.Is(err, errs.ErrRoleNotGranted) {
+			log.InfoContext(ctx, "profile not found", "entityId", grant.EntityID)
+			return nil, errs.ErrInvalidCredentials
+		}
+		if errors.Is(err, errs.ErrWaiterFired) || errors.Is(err, errs.ErrCustomerBlocked) {
+			log.InfoContext(ctx, "account disabled", "error", err)
+			return nil, err
+		}
+		log.ErrorContext(ctx, "failed to check account status", "error", err)
 		return nil, err
 	}
 
-	return &dto.TokensDTO{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+	u.rehash(ctx, log, identity, payload.Password)
+	return u.issueTokens(ctx, log, grant.EntityID, payload.Role)
 }
 
-func (u *authUsecase) LoginAdmin(ctx context.Context, payload *dto.LoginEmployeeDTO) (*dto.TokensDTO, error) {
-	const op = "login.Admin"
-	log := u.log.With(slog.String("op", op), slog.String("login", payload.Login))
-
-	log.Info("logging in admin")
+// StartSession issues tokens for a profile that was authenticated by other
+// means than a password, such as an external identity provider.
+func (u *authUsecase) StartSession(ctx context.Context, entityID string, role string) (*dto.TokensDTO, error) {
+	const op = "auth.StartSession"
+	log := u.log.With(slog.String("op", op), slog.String("role", role), slog.String("entityId", entityID))
 
-	admin, err := u.admins.GetAdminByLogin(ctx, payload.Login)
-	if err != nil {
-		if errors.Is(err, errs.ErrAdminNotFound) {
-			log.Info("admin not found")
+	if err := u.CheckStatus(ctx, role, entityID); err != nil {
+		if errors.Is(err, errs.ErrRoleNotGranted) {
+			log.InfoContext(ctx, "profile not found")
 			return nil, errs.ErrInvalidCredentials
 		}
-		log.Error("failed to get admin by login", "error", err)
+		if errors.Is(err, errs.ErrWaiterFired) || errors.Is(err, errs.ErrCustomerBlocked) {
+			log.InfoContext(ctx, "account disabled", "error", err)
+			return nil, err
+		}
+		log.ErrorContext(ctx, "failed to check account status", "error", err)
 		return nil, err
 	}
 
-	if err := ComparePassword(admin.Password, payload.Password); err != nil {
-		log.Info("invalid password")
-		return nil, errs.ErrInvalidCredentials
-	}
+	return u.issueTokens(ctx, log, entityID, role)
+}
 
-	accessToken, err := u.tokens.SignAccessToken(admin.AdminID, constants.RoleAdmin)
-	if err != nil {
-		log.Error("failed to sign access token", "error", err)
-		return nil, err
-	}
+func (u *authUsecase) UnlockAccount(ctx context.Context, payload *dto.UnlockAccountDTO) error {
+	const op = "auth.UnlockAccount"
+	log := u.log.With(slog.String("op", op), slog.String("role", payload.Role), slog.String("login", payload.Login))
 
-	refreshToken, err := u.tokens.GenerateRefreshToken(ctx, admin.AdminID, constants.RoleAdmin)
-	if err != nil {
-		log.Error("failed to generate refresh token", "error", err)
-		return nil, err
+	if err := u.attempts.ResetAttempts(ctx, payload.Role, payload.Login); err != nil {
+		log.ErrorContext(ctx, "failed to reset login attempts", "error", err)
+		return err
 	}
 
-	return &dto.TokensDTO{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+	log.InfoContext(ctx, "account unlocked")
+	return nil
 }
 
-func (u *authUsecase) LoginWaiter(ctx context.Context, payload *dto.LoginEmployeeDTO) (*dto.TokensDTO, error) {
-	const op = "login.Waiter"
-	log := u.log.With(slog.String("op", op), slog.String("login", payload.Login))
+// registerAttempt counts the attempt before the credentials are checked. An
+// unavailable limiter is logged and ignored rather than locking everybody out.
+func registerAttempt(ctx context.Context, log *slog.Logger, attempts AttemptsRepo, role, login, ip string) error {
+	lockout, err := attempts.RegisterAttempt(ctx, role, login, ip)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to register login attempt", "error", err)
+		return nil
+	}
+	if lockout > 0 {
+		log.InfoContext(ctx, "login locked", "retryAfter", lockout)
+		return &errs.TooManyAttemptsError{RetryAfter: lockout}
+	}
+	return nil
+}
 
-	log.Info("logging in waiter")
+// resetAttempts clears the account counter after a successful login. The IP
+// counter is left to expire, or logging into an own account between guesses
+// would reset the budget of the address.
+func resetAttempts(ctx context.Context, log *slog.Logger, attempts AttemptsRepo, role, login string) {
+	if err := attempts.ResetAttempts(ctx, role, login); err != nil {
+		log.ErrorContext(ctx, "failed to reset login attempts", "error", err)
+	}
+}
 
-	waiter, err := u.waiters.GetWaiterByLogin(ctx, payload.Login)
+func (u *authUsecase) issueTokens(ctx context.Context, log *slog.Logger, entityID string, role string) (*dto.TokensDTO, error) {
+	mfaEnabled, err := u.mfa.IsMFAEnabled(ctx, entityID)
 	if err != nil {
-		if errors.Is(err, errs.ErrWaiterNotFound) {
-			log.Info("waiter not found")
-			return nil, errs.ErrInvalidCredentials
-		}
-		log.Error("failed to get waiter by login", "error", err)
+		log.ErrorContext(ctx, "failed to check mfa enabled", "error", err)
 		return nil, err
 	}
 
-	if err := ComparePassword(waiter.Password, payload.Password); err != nil {
-		log.Info("invalid password")
-		return nil, errs.ErrInvalidCredentials
+	if mfaEnabled {
+		mfaToken, err := u.tokens.GenerateMFAToken(ctx, entityID, role)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to generate mfa token", "error", err)
+			return nil, err
+		}
+		log.InfoContext(ctx, "mfa required")
+		return &dto.TokensDTO{MFAToken: mfaToken}, nil
 	}
 
-	accessToken, err := u.tokens.SignAccessToken(waiter.WaiterID, constants.RoleWaiter)
+	permissions, err := u.permissions.GetRolePermissions(ctx, role)
 	if err != nil {
-		log.Error("failed to sign access token", "error", err)
+		log.ErrorContext(ctx, "failed to get role permissions", "error", err)
 		return nil, err
 	}
 
-	refreshToken, err := u.tokens.GenerateRefreshToken(ctx, waiter.WaiterID, constants.RoleWaiter)
+	accessToken, err := u.tokens.SignAccessToken(entityID, role, permissions)
 	if err != nil {
-		log.Error("failed to generate refresh token", "error", err)
+		log.ErrorContext(ctx, "failed to sign access token", "error", err)
+		return nil, err
+	}
+
+	refreshToken, err := u.tokens.GenerateRefreshToken(ctx, entityID, role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate refresh token", "error", err)
 		return nil, err
 	}
 
@@ -167,9 +283,29 @@ func (u *authUsecase) Refresh(ctx context.Context, token string) (string, error)
 		return "", errs.ErrInvalidJwtToken
 	}
 
-	accessToken, err := u.tokens.SignAccessToken(payload.EntityID, payload.Role)
+	if err := u.CheckStatus(ctx, payload.Role, payload.EntityID); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrRoleNotGranted):
+			log.InfoContext(ctx, "profile not found", "entityId", payload.EntityID)
+			return "", errs.ErrInvalidJwtToken
+		case errors.Is(err, errs.ErrWaiterFired), errors.Is(err, errs.ErrCustomerBlocked):
+			log.InfoContext(ctx, "account disabled", "entityId", payload.EntityID, "error", err)
+			return "", err
+		default:
+			log.ErrorContext(ctx, "failed to check account status", "error", err)
+			return "", err
+		}
+	}
+
+	permissions, err := u.permissions.GetRolePermissions(ctx, payload.Role)
 	if err != nil {
-		log.Error("failed to sign access token", "error", err)
+		log.ErrorContext(ctx, "failed to get role permissions", "error", err)
+		return "", err
+	}
+
+	accessToken, err := u.tokens.SignAccessToken(payload.EntityID, payload.Role, permissions)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to sign access token", "error", err)
 		return "", err
 	}
 
@@ -184,12 +320,97 @@ func (u *authUsecase) Logout(ctx context.Context, token string) error {
 		if errors.Is(err, errs.ErrInvalidJwtToken) {
 			return nil
 		}
-		log.Error("failed to revoke refresh token", "error", err)
+		log.ErrorContext(ctx, "failed to revoke refresh token", "error", err)
 		return err
 	}
 	return nil
 }
 
-func ComparePassword(hashedPassword []byte, password string) error {
-	return bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
+func (u *authUsecase) Introspect(ctx context.Context, token string) (*dto.IntrospectDTO, error) {
+	const op = "auth.Introspect"
+	log := u.log.With(slog.String("op", op))
+
+	payload, err := u.tokens.VerifyAccessToken(token)
+	if err != nil {
+		return &dto.IntrospectDTO{Active: false}, nil
+	}
+
+	result := &dto.IntrospectDTO{
+		Active:      true,
+		EntityID:    payload.EntityID,
+		Role:        payload.Role,
+		Permissions: payload.Permissions,
+		ExpiresAt:   payload.ExpiresAt,
+	}
+
+	if err := u.CheckStatus(ctx, payload.Role, payload.EntityID); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrRoleNotGranted):
+			log.InfoContext(ctx, "profile not found", "entityId", payload.EntityID)
+			return &dto.IntrospectDTO{Active: false}, nil
+		case errors.Is(err, errs.ErrWaiterFired):
+			result.Active = false
+			result.Fired = true
+		case errors.Is(err, errs.ErrCustomerBlocked):
+			result.Active = false
+			result.Blocked = true
+		default:
+			log.ErrorContext(ctx, "failed to check account status", "error", err)
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// CheckStatus reports whether the profile may still sign in: ErrRoleNotGranted
+// when it does not exist or was deleted, ErrWaiterFired or ErrCustomerBlocked.
+func (u *authUsecase) CheckStatus(ctx context.Context, role string, entityID string) error {
+	switch role {
+	case constants.RoleWaiter:
+		waiter, err := u.waiters.GetWaiterByID(ctx, entityID)
+		if err != nil {
+			if errors.Is(err, errs.ErrWaiterNotFound) {
+				return errs.ErrRoleNotGranted
+			}
+			return err
+		}
+		if waiter.FiredAt != nil {
+			return errs.ErrWaiterFired
+		}
+	case constants.RoleCustomer:
+		customer, err := u.customers.GetCustomerByID(ctx, entityID)
+		if err != nil {
+			if errors.Is(err, errs.ErrCustomerNotFound) {
+				return errs.ErrRoleNotGranted
+			}
+			return err
+		}
+		if customer.DeletedAt != nil {
+			return errs.ErrRoleNotGranted
+		}
+		if customer.BlockedAt != nil {
+			return errs.ErrCustomerBlocked
+		}
+	}
+	return nil
+}
+
+// rehash upgrades a stored hash made with an outdated algorithm or parameters.
+// Failing to do so must not block the login, the old hash still works.
+func (u *authUsecase) rehash(ctx context.Context, log *slog.Logger, identity *entities.IdentityEntity, password string) {
+	if !u.hasher.NeedsRehash(identity.Password) {
+		return
+	}
+
+	hashedPassword, err := u.hasher.Hash(password)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to rehash password", "error", err)
+		return
+	}
+	if err := u.identities.UpdatePassword(ctx, identity.IdentityID, hashedPassword); err != nil {
+		log.ErrorContext(ctx, "failed to store rehashed password", "error", err)
+		return
+	}
+	log.InfoContext(ctx, "password hash upgraded")
 }
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type CustomerDirectoryRepo interface {
+	GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error)
+	SearchCustomers(ctx context.Context, query string, limit, offset int) ([]*entities.CustomerEntity, int, error)
+	SetCustomerBlocked(ctx context.Context, customerID string, blocked bool, reason string) error
+}
+
+type customersUsecase struct {
+	customers CustomerDirectoryRepo
+	tokens    TokensRevoker
+	log       *slog.Logger
+}
+
+func NewCustomersUsecase(log *slog.Logger, customers CustomerDirectoryRepo, tokens TokensRevoker) *customersUsecase {
+	return &customersUsecase{
+		customers: customers,
+		tokens:    tokens,
+		log:       log,
+	}
+}
+
+func (u *customersUsecase) SearchCustomers(ctx context.Context, payload *dto.SearchCustomersDTO) (*dto.CustomersPageDTO, error) {
+	const op = "customers.Search"
+	log := u.log.With(slog.String("op", op), slog.String("query", payload.Query))
+
+	limit, offset := paginate(payload.Page, payload.PageSize)
+	customers, total, err := u.customers.SearchCustomers(ctx, payload.Query, limit, offset)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to search customers", "error", err)
+		return nil, err
+	}
+	return &dto.CustomersPageDTO{Customers: customers, Total: total}, nil
+}
+
+func (u *customersUsecase) GetCustomer(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	const op = "customers.Get"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", customerID))
+
+	customer, err := u.customers.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, errs.ErrCustomerNotFound) {
+			log.InfoContext(ctx, "customer not found")
+			return nil, errs.ErrCustomerNotFound
+		}
+		log.ErrorContext(ctx, "failed to get customer by id", "error", err)
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (u *customersUsecase) SetCustomerBlocked(ctx context.Context, payload *dto.SetCustomerBlockedDTO) error {
+	const op = "customers.SetBlocked"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", payload.CustomerID), slog.Bool("blocked", payload.Blocked))
+
+	if err := u.customers.SetCustomerBlocked(ctx, payload.CustomerID, payload.Blocked, payload.Reason); err != nil {
+		if errors.Is(err, errs.ErrCustomerNotFound) {
+			log.InfoContext(ctx, "customer not found")
+			return errs.ErrCustomerNotFound
+		}
+		log.ErrorContext(ctx, "failed to set customer blocked", "error", err)
+		return err
+	}
+
+	if payload.Blocked {
+		if err := u.tokens.RevokeAllRefreshTokens(ctx, payload.CustomerID); err != nil {
+			log.ErrorContext(ctx, "failed to revoke refresh tokens", "error", err)
+			return err
+		}
+	}
+
+	log.InfoContext(ctx, "customer block status updated")
+	return nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type InvitationRepo interface {
+	CreateInvitation(ctx context.Context, dto *dto.CreateInvitationDTO) (*entities.InvitationEntity, error)
+}
+
+type invitationUsecase struct {
+	invitations InvitationRepo
+	ttl         time.Duration
+	maxTTL      time.Duration
+	log         *slog.Logger
+}
+
+func NewInvitationUsecase(log *slog.Logger, invitations InvitationRepo, ttl time.Duration, maxTTL time.Duration) *invitationUsecase {
+	return &invitationUsecase{
+		invitations: invitations,
+		ttl:         ttl,
+		maxTTL:      maxTTL,
+		log:         log,
+	}
+}
+
+// IssueInvitation creates an invitation on behalf of the caller. Only admins
+// may invite other admins, whatever permissions the caller was granted.
+func (u *invitationUsecase) IssueInvitation(ctx context.Context, adminID string, callerRole string, payload *dto.IssueInvitationDTO) (*dto.InvitationDTO, error) {
+	const op = "invitation.Issue"
+	log := u.log.With(slog.String("op", op), slog.String("adminId", adminID), slog.String("role", payload.Role))
+
+	log.InfoContext(ctx, "issuing invitation")
+
+	if payload.Role == constants.RoleAdmin && callerRole != constants.RoleAdmin {
+		log.InfoContext(ctx, "caller is not an admin", "callerRole", callerRole)
+		return nil, errs.ErrInvitationNotAllowed
+	}
+	if payload.TTL > u.maxTTL {
+		log.InfoContext(ctx, "invitation ttl too long", "ttl", payload.TTL)
+		return nil, errs.ErrInvitationTTLTooLong
+	}
+
+	code, err := GenerateInvitationCode()
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate invitation code", "error", err)
+		return nil, err
+	}
+
+	ttl := u.ttl
+	if payload.TTL > 0 {
+		ttl = payload.TTL
+	}
+
+	invitation, err := u.invitations.CreateInvitation(ctx, &dto.CreateInvitationDTO{
+		CodeHash:  HashInvitationCode(code),
+		Role:      payload.Role,
+		FirstName: optional(payload.FirstName),
+		LastName:  optional(payload.LastName),
+		Note:      optional(payload.Note),
+		CreatedBy: adminID,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrInvitationNotAllowed) {
+			log.InfoContext(ctx, "issuer is not an admin")
+			return nil, errs.ErrInvitationNotAllowed
+		}
+		log.ErrorContext(ctx, "failed to create invitation", "error", err)
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "invitation issued", "invitationId", invitation.InvitationID)
+	return &dto.InvitationDTO{
+		InvitationID: invitation.InvitationID,
+		Code:         code,
+		ExpiresAt:    invitation.ExpiresAt,
+	}, nil
+}
+
+func GenerateInvitationCode() (string, error) {
+	buf := make([]byte, 15)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.EncodeToString(buf)), nil
+}
+
+func HashInvitationCode(code string) []byte {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hash[:]
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
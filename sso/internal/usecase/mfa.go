@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/pkg/payload"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	recoveryCodesCount = 10
+	totpPeriod         = 30
+	// mfaAttemptsRole scopes the attempts of confirming and disabling mfa,
+	// which are counted per entity like logins are per account.
+	mfaAttemptsRole = "mfa"
+)
+
+type MFARepo interface {
+	GetMFA(ctx context.Context, entityID string) (*entities.MFAEntity, error)
+	SaveMFASecret(ctx context.Context, entityID, role, secret string) error
+	ConfirmMFA(ctx context.Context, entityID string, recoveryCodeHashes [][]byte) error
+	UseRecoveryCode(ctx context.Context, entityID string, codeHash []byte) (bool, error)
+	UseTOTPStep(ctx context.Context, entityID string, step int64) (bool, error)
+	DeleteMFA(ctx context.Context, entityID string) error
+}
+
+type MFATokensRepo interface {
+	VerifyMFAToken(ctx context.Context, token string) (*payload.JwtPayload, error)
+	FailMFAToken(ctx context.Context, token string) error
+	ConsumeMFAToken(ctx context.Context, token string) error
+	GenerateRefreshToken(ctx context.Context, entityID string, role string) (string, error)
+	SignAccessToken(entityID string, role string, permissions []string) (string, error)
+}
+
+// AccountStatusChecker tells whether the profile behind a challenge may still
+// sign in, it may have been disabled after the password was checked.
+type AccountStatusChecker interface {
+	CheckStatus(ctx context.Context, role string, entityID string) error
+}
+
+type mfaUsecase struct {
+	mfa         MFARepo
+	tokens      MFATokensRepo
+	permissions PermissionsRepo
+	identities  IdentityLookupRepo
+	attempts    AttemptsRepo
+	status      AccountStatusChecker
+	issuer      string
+	log         *slog.Logger
+}
+
+func NewMFAUsecase(
+	log *slog.Logger,
+	mfa MFARepo,
+	tokens MFATokensRepo,
+	permissions PermissionsRepo,
+	identities IdentityLookupRepo,
+	attempts AttemptsRepo,
+	status AccountStatusChecker,
+	issuer string,
+) *mfaUsecase {
+	return &mfaUsecase{
+		mfa:         mfa,
+		tokens:      tokens,
+		permissions: permissions,
+		identities:  identities,
+		attempts:    attempts,
+		status:      status,
+		issuer:      issuer,
+		log:         log,
+	}
+}
+
+func (u *mfaUsecase) EnrollMFA(ctx context.Context, entityID string, role string) (*dto.MFAEnrollmentDTO, error) {
+	const op = "mfa.Enroll"
+	log := u.log.With(slog.String("op", op), slog.String("entityId", entityID))
+
+	log.InfoContext(ctx, "enrolling mfa")
+
+	accountName, err := u.accountName(ctx, entityID, role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get account name", "error", err)
+		return nil, err
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: u.issuer, AccountName: accountName})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate totp key", "error", err)
+		return nil, err
+	}
+
+	if err := u.mfa.SaveMFASecret(ctx, entityID, role, key.Secret()); err != nil {
+		if errors.Is(err, errs.ErrMFAAlreadyEnabled) {
+			log.InfoContext(ctx, "mfa already enabled")
+			return nil, errs.ErrMFAAlreadyEnabled
+		}
+		log.ErrorContext(ctx, "failed to save mfa secret", "error", err)
+		return nil, err
+	}
+
+	return &dto.MFAEnrollmentDTO{Secret: key.Secret(), URI: key.URL()}, nil
+}
+
+func (u *mfaUsecase) ConfirmMFA(ctx context.Context, entityID string, code string) ([]string, error) {
+	const op = "mfa.Confirm"
+	log := u.log.With(slog.String("op", op), slog.String("entityId", entityID))
+
+	log.InfoContext(ctx, "confirming mfa")
+
+	mfa, err := u.mfa.GetMFA(ctx, entityID)
+	if err != nil {
+		if errors.Is(err, errs.ErrMFANotFound) {
+			log.InfoContext(ctx, "mfa not enrolled")
+			return nil, errs.ErrMFANotEnabled
+		}
+		log.ErrorContext(ctx, "failed to get mfa", "error", err)
+		return nil, err
+	}
+	if mfa.ConfirmedAt != nil {
+		log.InfoContext(ctx, "mfa already enabled")
+		return nil, errs.ErrMFAAlreadyEnabled
+	}
+
+	if err := registerAttempt(ctx, log, u.attempts, mfaAttemptsRole, entityID, ""); err != nil {
+		return nil, err
+	}
+	valid, err := u.useTOTPCode(ctx, mfa, code)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to check mfa code", "error", err)
+		return nil, err
+	}
+	if !valid {
+		log.InfoContext(ctx, "invalid mfa code")
+		return nil, errs.ErrInvalidMFACode
+	}
+	resetAttempts(ctx, log, u.attempts, mfaAttemptsRole, entityID)
+
+	codes, hashes, err := GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate recovery codes", "error", err)
+		return nil, err
+	}
+
+	if err := u.mfa.ConfirmMFA(ctx, entityID, hashes); err != nil {
+		if errors.Is(err, errs.ErrMFAAlreadyEnabled) {
+			log.InfoContext(ctx, "mfa already enabled")
+			return nil, errs.ErrMFAAlreadyEnabled
+		}
+		log.ErrorContext(ctx, "failed to confirm mfa", "error", err)
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "mfa enabled")
+	return codes, nil
+}
+
+func (u *mfaUsecase) DisableMFA(ctx context.Context, entityID string, code string) error {
+	const op = "mfa.Disable"
+	log := u.log.With(slog.String("op", op), slog.String("entityId", entityID))
+
+	log.InfoContext(ctx, "disabling mfa")
+
+	mfa, err := u.mfa.GetMFA(ctx, entityID)
+	if err != nil {
+		if errors.Is(err, errs.ErrMFANotFound) {
+			log.InfoContext(ctx, "mfa not enabled")
+			return errs.ErrMFANotEnabled
+		}
+		log.ErrorContext(ctx, "failed to get mfa", "error", err)
+		return err
+	}
+
+	if mfa.ConfirmedAt != nil {
+		if err := registerAttempt(ctx, log, u.attempts, mfaAttemptsRole, entityID, ""); err != nil {
+			return err
+		}
+		valid, err := u.checkCode(ctx, mfa, code)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to check mfa code", "error", err)
+			return err
+		}
+		if !valid {
+			log.InfoContext(ctx, "invalid mfa code")
+			return errs.ErrInvalidMFACode
+		}
+		resetAttempts(ctx, log, u.attempts, mfaAttemptsRole, entityID)
+	}
+
+	if err := u.mfa.DeleteMFA(ctx, entityID); err != nil {
+		log.ErrorContext(ctx, "failed to delete mfa", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "mfa disabled")
+	return nil
+}
+
+func (u *mfaUsecase) VerifyMFA(ctx context.Context, payload *dto.VerifyMFADTO) (*dto.TokensDTO, error) {
+	const op = "mfa.Verify"
+	log := u.log.With(slog.String("op", op))
+
+	challenge, err := u.tokens.VerifyMFAToken(ctx, payload.Token)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidMFAToken) {
+			log.InfoContext(ctx, "invalid mfa token")
+			return nil, errs.ErrInvalidMFAToken
+		}
+		log.ErrorContext(ctx, "failed to verify mfa token", "error", err)
+		return nil, err
+	}
+	log = log.With(slog.String("entityId", challenge.EntityID))
+
+	mfa, err := u.mfa.GetMFA(ctx, challenge.EntityID)
+	if err != nil {
+		if errors.Is(err, errs.ErrMFANotFound) {
+			log.InfoContext(ctx, "mfa not enabled")
+			return nil, errs.ErrInvalidMFAToken
+		}
+		log.ErrorContext(ctx, "failed to get mfa", "error", err)
+		return nil, err
+	}
+
+	valid, err := u.checkCode(ctx, mfa, payload.Code)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to check mfa code", "error", err)
+		return nil, err
+	}
+	if !valid {
+		log.InfoContext(ctx, "invalid mfa code")
+		if err := u.tokens.FailMFAToken(ctx, payload.Token); err != nil {
+			log.ErrorContext(ctx, "failed to register mfa attempt", "error", err)
+		}
+		return nil, errs.ErrInvalidMFACode
+	}
+
+	if err := u.status.CheckStatus(ctx, challenge.Role, challenge.EntityID); err != nil {
+		if errors.Is(err, errs.ErrRoleNotGranted) {
+			log.InfoContext(ctx, "profile not found")
+			return nil, errs.ErrInvalidMFAToken
+		}
+		if errors.Is(err, errs.ErrWaiterFired) || errors.Is(err, errs.ErrCustomerBlocked) {
+			log.InfoContext(ctx, "account disabled", "error", err)
+			return nil, err
+		}
+		log.ErrorContext(ctx, "failed to check account status", "error", err)
+		return nil, err
+	}
+
+	if err := u.tokens.ConsumeMFAToken(ctx, payload.Token); err != nil {
+		if errors.Is(err, errs.ErrInvalidMFAToken) {
+			log.InfoContext(ctx, "mfa token already used")
+			return nil, errs.ErrInvalidMFAToken
+		}
+		log.ErrorContext(ctx, "failed to consume mfa token", "error", err)
+		return nil, err
+	}
+
+	permissions, err := u.permissions.GetRolePermissions(ctx, challenge.Role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get role permissions", "error", err)
+		return nil, err
+	}
+
+	accessToken, err := u.tokens.SignAccessToken(challenge.EntityID, challenge.Role, permissions)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to sign access token", "error", err)
+		return nil, err
+	}
+
+	refreshToken, err := u.tokens.GenerateRefreshToken(ctx, challenge.EntityID, challenge.Role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate refresh token", "error", err)
+		return nil, err
+	}
+
+	return &dto.TokensDTO{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (u *mfaUsecase) checkCode(ctx context.Context, mfa *entities.MFAEntity, code string) (bool, error) {
+	if step, ok := totpStep(code, mfa.Secret, time.Now()); ok {
+		return u.mfa.UseTOTPStep(ctx, mfa.EntityID, step)
+	}
+	return u.mfa.UseRecoveryCode(ctx, mfa.EntityID, HashRecoveryCode(code))
+}
+
+func (u *mfaUsecase) useTOTPCode(ctx context.Context, mfa *entities.MFAEntity, code string) (bool, error) {
+	step, ok := totpStep(code, mfa.Secret, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return u.mfa.UseTOTPStep(ctx, mfa.EntityID, step)
+}
+
+// totpStep returns the time step the code was generated for, accepting one
+// step of clock drift either way like totp.Validate does.
+func totpStep(code string, secret string, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	for _, skew := range []int64{0, -1, 1} {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		if valid, _ := totp.ValidateCustom(code, secret, at, opts); valid {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+func (u *mfaUsecase) accountName(ctx context.Context, entityID string, role string) (string, error) {
+	identity, err := u.identities.GetIdentityByEntity(ctx, role, entityID)
+	if err != nil {
+		return "", err
+	}
+	return identity.Login, nil
+}
+
+func GenerateRecoveryCodes(count int) ([]string, [][]byte, error) {
+	codes := make([]string, count)
+	hashes := make([][]byte, count)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+func HashRecoveryCode(code string) []byte {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return hash[:]
+}
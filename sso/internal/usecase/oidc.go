@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/google/uuid"
+)
+
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state string, nonce string) (string, error)
+	Exchange(ctx context.Context, code string, nonce string) (*dto.ExternalIdentityDTO, error)
+}
+
+type OIDCStateRepo interface {
+	SaveOIDCState(ctx context.Context, state string, entity *entities.OIDCStateEntity) error
+	ConsumeOIDCState(ctx context.Context, state string) (*entities.OIDCStateEntity, error)
+}
+
+type IdentityOIDCRepo interface {
+	GetIdentityByExternal(ctx context.Context, provider string, subject string) (*entities.IdentityEntity, error)
+	GetIdentityByLogin(ctx context.Context, login string) (*entities.IdentityEntity, error)
+	GetRole(ctx context.Context, identityID string, role string) (*entities.IdentityRoleEntity, error)
+	ListRoles(ctx context.Context, identityID string) ([]*entities.IdentityRoleEntity, error)
+	CreateIdentity(ctx context.Context, login string, password []byte) (string, error)
+	DeleteIdentity(ctx context.Context, identityID string) error
+	LinkExternalIdentity(ctx context.Context, identityID string, provider string, subject string, email string) error
+}
+
+type CustomerOIDCRepo interface {
+	GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error)
+	CreateCustomer(ctx context.Context, dto *dto.CreateCustomerDTO) (uuid.UUID, error)
+}
+
+type SessionIssuer interface {
+	StartSession(ctx context.Context, entityID string, role string) (*dto.TokensDTO, error)
+}
+
+type oidcUsecase struct {
+	providers  map[string]OIDCProvider
+	states     OIDCStateRepo
+	identities IdentityOIDCRepo
+	customers  CustomerOIDCRepo
+	sessions   SessionIssuer
+	log        *slog.Logger
+}
+
+func NewOIDCUsecase(
+	log *slog.Logger,
+	providers map[string]OIDCProvider,
+	states OIDCStateRepo,
+	identities IdentityOIDCRepo,
+	customers CustomerOIDCRepo,
+	sessions SessionIssuer,
+) *oidcUsecase {
+	return &oidcUsecase{
+		providers:  providers,
+		states:     states,
+		identities: identities,
+		customers:  customers,
+		sessions:   sessions,
+		log:        log,
+	}
+}
+
+func (u *oidcUsecase) StartOIDCLogin(ctx context.Context, providerName string) (*dto.OIDCAuthorizationDTO, error) {
+	const op = "oidc.Start"
+	log := u.log.With(slog.String("op", op), slog.String("provider", providerName))
+
+	provider, ok := u.providers[providerName]
+	if !ok {
+		log.InfoContext(ctx, "unknown provider")
+		return nil, errs.ErrUnknownOIDCProvider
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate state", "error", err)
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate nonce", "error", err)
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to build authorization url", "error", err)
+		return nil, err
+	}
+
+	if err := u.states.SaveOIDCState(ctx, state, &entities.OIDCStateEntity{Provider: providerName, Nonce: nonce}); err != nil {
+		log.ErrorContext(ctx, "failed to save state", "error", err)
+		return nil, err
+	}
+
+	return &dto.OIDCAuthorizationDTO{URL: authURL, State: state}, nil
+}
+
+func (u *oidcUsecase) CompleteOIDCLogin(ctx context.Context, payload *dto.OIDCCallbackDTO) (*dto.TokensDTO, error) {
+	const op = "oidc.Complete"
+	log := u.log.With(slog.String("op", op))
+
+	state, err := u.states.ConsumeOIDCState(ctx, payload.State)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidOIDCState) {
+			log.InfoContext(ctx, "invalid state")
+			return nil, errs.ErrInvalidOIDCState
+		}
+		log.ErrorContext(ctx, "failed to consume state", "error", err)
+		return nil, err
+	}
+	log = log.With(slog.String("provider", state.Provider))
+
+	provider, ok := u.providers[state.Provider]
+	if !ok {
+		log.InfoContext(ctx, "unknown provider")
+		return nil, errs.ErrUnknownOIDCProvider
+	}
+
+	external, err := provider.Exchange(ctx, payload.Code, state.Nonce)
+	if err != nil {
+		log.WarnContext(ctx, "identity provider login failed", "error", err)
+		return nil, errs.ErrOIDCLoginFailed
+	}
+	log = log.With(slog.String("subject", external.Subject))
+
+	identityID, err := u.resolveIdentity(ctx, log, state.Provider, external)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := u.identities.GetRole(ctx, identityID, constants.RoleCustomer)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get customer role", "error", err)
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "oidc login succeeded", "customerId", grant.EntityID)
+	return u.sessions.StartSession(ctx, grant.EntityID, constants.RoleCustomer)
+}
+
+// resolveIdentity returns the identity linked to the external account. Unknown
+// accounts are linked by their verified email to an existing customer whose
+// email is verified too, or get a new password-less identity with a customer
+// profile.
+func (u *oidcUsecase) resolveIdentity(ctx context.Context, log *slog.Logger, provider string, external *dto.ExternalIdentityDTO) (string, error) {
+	identity, err := u.identities.GetIdentityByExternal(ctx, provider, external.Subject)
+	if err == nil {
+		return identity.IdentityID, nil
+	}
+	if !errors.Is(err, errs.ErrIdentityNotFound) {
+		log.ErrorContext(ctx, "failed to get identity by external account", "error", err)
+		return "", err
+	}
+
+	if external.Email == "" || !external.EmailVerified {
+		log.InfoContext(ctx, "email not verified by provider")
+		return "", errs.ErrEmailNotVerified
+	}
+
+	var identityID string
+	created := false
+	identity, err = u.identities.GetIdentityByLogin(ctx, external.Email)
+	switch {
+	case err == nil:
+		if err := u.checkLinkable(ctx, log, identity.IdentityID, external.Email); err != nil {
+			return "", err
+		}
+		identityID = identity.IdentityID
+	case errors.Is(err, errs.ErrIdentityNotFound):
+		// An empty hash never matches, the customer can set a password through a reset.
+		identityID, err = u.identities.CreateIdentity(ctx, external.Email, []byte{})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create identity", "error", err)
+			return "", err
+		}
+		created = true
+	default:
+		log.ErrorContext(ctx, "failed to get identity by login", "error", err)
+		return "", err
+	}
+
+	if err := u.ensureCustomer(ctx, log, identityID, external); err != nil {
+		if created {
+			if err := u.identities.DeleteIdentity(ctx, identityID); err != nil {
+				log.ErrorContext(ctx, "failed to delete identity", "error", err, "identityId", identityID)
+			}
+		}
+		return "", err
+	}
+
+	if err := u.identities.LinkExternalIdentity(ctx, identityID, provider, external.Subject, external.Email); err != nil {
+		log.ErrorContext(ctx, "failed to link external identity", "error", err)
+		return "", err
+	}
+
+	log.InfoContext(ctx, "external identity linked", "identityId", identityID)
+	return identityID, nil
+}
+
+// checkLinkable only allows linking to an identity that is nothing but a
+// customer who proved owning the email. Otherwise whoever registered the email
+// first, without verifying it, would keep access to the account, and staff
+// accounts could be taken over through a provider account.
+func (u *oidcUsecase) checkLinkable(ctx context.Context, log *slog.Logger, identityID string, email string) error {
+	roles, err := u.identities.ListRoles(ctx, identityID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to list identity roles", "error", err)
+		return err
+	}
+	if len(roles) != 1 || roles[0].Role != constants.RoleCustomer {
+		log.WarnContext(ctx, "refusing to link identity that is not only a customer", "identityId", identityID)
+		return errs.ErrAccountNotLinkable
+	}
+
+	customer, err := u.customers.GetCustomerByID(ctx, roles[0].EntityID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get customer", "error", err)
+		return err
+	}
+	if customer.EmailVerifiedAt == nil || !strings.EqualFold(customer.Email, email) {
+		log.WarnContext(ctx, "refusing to link customer with unverified email", "customerId", customer.CustomerID)
+		return errs.ErrAccountNotLinkable
+	}
+	return nil
+}
+
+func (u *oidcUsecase) ensureCustomer(ctx context.Context, log *slog.Logger, identityID string, external *dto.ExternalIdentityDTO) error {
+	_, err := u.identities.GetRole(ctx, identityID, constants.RoleCustomer)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, errs.ErrRoleNotGranted) {
+		log.ErrorContext(ctx, "failed to get customer role", "error", err)
+		return err
+	}
+
+	name := external.Name
+	if name == "" {
+		name, _, _ = strings.Cut(external.Email, "@")
+	}
+	if _, err := u.customers.CreateCustomer(ctx, &dto.CreateCustomerDTO{
+		IdentityID:    identityID,
+		Email:         external.Email,
+		Name:          name,
+		EmailVerified: true,
+	}); err != nil {
+		log.ErrorContext(ctx, "failed to create customer", "error", err)
+		return err
+	}
+	return nil
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
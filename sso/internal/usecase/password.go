@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type IdentityPasswordRepo interface {
+	GetIdentityByEntity(ctx context.Context, role string, entityID string) (*entities.IdentityEntity, error)
+	ListRoles(ctx context.Context, identityID string) ([]*entities.IdentityRoleEntity, error)
+	UpdatePassword(ctx context.Context, identityID string, password []byte) error
+}
+
+type PasswordPolicy interface {
+	Validate(password string, identifiers ...string) error
+}
+
+type CustomerPasswordRepo interface {
+	GetCustomerByEmail(ctx context.Context, email string) (*entities.CustomerEntity, error)
+}
+
+type ResetRepo interface {
+	StartResetCooldown(ctx context.Context, email string) (bool, error)
+	GenerateResetToken(ctx context.Context, customerID string) (string, error)
+	GetResetToken(ctx context.Context, token string) (string, error)
+	ConsumeResetToken(ctx context.Context, token string) (string, error)
+}
+
+type TokensRevoker interface {
+	RevokeAllRefreshTokens(ctx context.Context, entityID string) error
+}
+
+const (
+	resetEmailWorkers = 4
+	resetEmailQueue   = 256
+)
+
+type passwordUsecase struct {
+	identities IdentityPasswordRepo
+	customers  CustomerPasswordRepo
+	resets     ResetRepo
+	tokens     TokensRevoker
+	mailer     Mailer
+	hasher     PasswordHasher
+	policy     PasswordPolicy
+	resetURL   string
+	emails     *worker
+	log        *slog.Logger
+}
+
+func NewPasswordUsecase(
+	log *slog.Logger,
+	identities IdentityPasswordRepo,
+	customers CustomerPasswordRepo,
+	resets ResetRepo,
+	tokens TokensRevoker,
+	mailer Mailer,
+	hasher PasswordHasher,
+	policy PasswordPolicy,
+	resetURL string,
+) *passwordUsecase {
+	return &passwordUsecase{
+		identities: identities,
+		customers:  customers,
+		resets:     resets,
+		tokens:     tokens,
+		mailer:     mailer,
+		hasher:     hasher,
+		policy:     policy,
+		resetURL:   resetURL,
+		emails:     newWorker(resetEmailWorkers, resetEmailQueue),
+		log:        log,
+	}
+}
+
+// Close waits for the reset emails already queued to be sent.
+func (u *passwordUsecase) Close() {
+	u.emails.close()
+}
+
+func (u *passwordUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	const op = "password.RequestReset"
+	log := u.log.With(slog.String("op", op), slog.String("email", email))
+
+	log.InfoContext(ctx, "requesting password reset")
+
+	// The cooldown is per address whether or not it is registered, so it
+	// does not tell registered ones apart.
+	started, err := u.resets.StartResetCooldown(ctx, email)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to start reset cooldown", "error", err)
+		return err
+	}
+	if !started {
+		log.InfoContext(ctx, "password reset requested recently")
+		return nil
+	}
+
+	// The reply must not tell whether the email is registered, neither by its
+	// result nor by how long it takes, so the email is sent in the background.
+	ctx = context.WithoutCancel(ctx)
+	if !u.emails.submit(func() { u.sendResetEmail(ctx, log, email) }) {
+		log.WarnContext(ctx, "reset email queue is full, dropping request")
+	}
+	return nil
+}
+
+func (u *passwordUsecase) sendResetEmail(ctx context.Context, log *slog.Logger, email string) {
+	customer, err := u.customers.GetCustomerByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, errs.ErrCustomerNotFound) {
+			log.InfoContext(ctx, "customer not found")
+			return
+		}
+		log.ErrorContext(ctx, "failed to get customer by email", "error", err)
+		return
+	}
+
+	token, err := u.resets.GenerateResetToken(ctx, customer.CustomerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate reset token", "error", err)
+		return
+	}
+
+	link, err := tokenLink(u.resetURL, token)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to build reset link", "error", err)
+		return
+	}
+
+	body := fmt.Sprintf("Reset your password by following the link: %s", link)
+	if err := u.mailer.Send(ctx, customer.Email, "Password reset", body); err != nil {
+		log.ErrorContext(ctx, "failed to send reset email", "error", err)
+		return
+	}
+
+	log.InfoContext(ctx, "password reset email sent")
+}
+
+func (u *passwordUsecase) ResetPassword(ctx context.Context, payload *dto.ResetPasswordDTO) error {
+	const op = "password.Reset"
+	log := u.log.With(slog.String("op", op))
+
+	customerID, err := u.resets.GetResetToken(ctx, payload.Token)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidResetToken) {
+			log.InfoContext(ctx, "invalid reset token")
+			return errs.ErrInvalidResetToken
+		}
+		log.ErrorContext(ctx, "failed to get reset token", "error", err)
+		return err
+	}
+	log = log.With(slog.String("customerId", customerID))
+
+	identity, err := u.identities.GetIdentityByEntity(ctx, constants.RoleCustomer, customerID)
+	if err != nil {
+		if errors.Is(err, errs.ErrIdentityNotFound) {
+			log.InfoContext(ctx, "identity not found")
+			return errs.ErrInvalidResetToken
+		}
+		log.ErrorContext(ctx, "failed to get identity", "error", err)
+		return err
+	}
+
+	// Checked before the token is consumed so a rejected password does not burn the link.
+	if err := u.policy.Validate(payload.Password, identity.Login); err != nil {
+		log.InfoContext(ctx, "password rejected by policy", "error", err)
+		return err
+	}
+
+	if _, err := u.resets.ConsumeResetToken(ctx, payload.Token); err != nil {
+		if errors.Is(err, errs.ErrInvalidResetToken) {
+			log.InfoContext(ctx, "reset token already used")
+			return errs.ErrInvalidResetToken
+		}
+		log.ErrorContext(ctx, "failed to consume reset token", "error", err)
+		return err
+	}
+
+	if err := u.setPassword(ctx, log, identity, payload.Password); err != nil {
+		return err
+	}
+
+	log.InfoContext(ctx, "password reset")
+	return nil
+}
+
+func (u *passwordUsecase) ChangePassword(ctx context.Context, entityID string, role string, payload *dto.ChangePasswordDTO) error {
+	const op = "password.Change"
+	log := u.log.With(slog.String("op", op), slog.String("entityId", entityID))
+
+	log.InfoContext(ctx, "changing password")
+
+	identity, err := u.identities.GetIdentityByEntity(ctx, role, entityID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get identity", "error", err)
+		return err
+	}
+
+	if err := u.hasher.Compare(identity.Password, payload.OldPassword); err != nil {
+		log.InfoContext(ctx, "invalid password")
+		return errs.ErrInvalidPassword
+	}
+
+	if err := u.policy.Validate(payload.NewPassword, identity.Login); err != nil {
+		log.InfoContext(ctx, "password rejected by policy", "error", err)
+		return err
+	}
+
+	if err := u.setPassword(ctx, log, identity, payload.NewPassword); err != nil {
+		return err
+	}
+
+	log.InfoContext(ctx, "password changed")
+	return nil
+}
+
+func (u *passwordUsecase) setPassword(ctx context.Context, log *slog.Logger, identity *entities.IdentityEntity, password string) error {
+	hashedPassword, err := u.hasher.Hash(password)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to hash password", "error", err)
+		return err
+	}
+
+	if err := u.identities.UpdatePassword(ctx, identity.IdentityID, hashedPassword); err != nil {
+		log.ErrorContext(ctx, "failed to update password", "error", err)
+		return err
+	}
+
+	roles, err := u.identities.ListRoles(ctx, identity.IdentityID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to list identity roles", "error", err)
+		return err
+	}
+	for _, role := range roles {
+		if err := u.tokens.RevokeAllRefreshTokens(ctx, role.EntityID); err != nil {
+			log.ErrorContext(ctx, "failed to revoke refresh tokens", "error", err)
+			return err
+		}
+	}
+	return nil
+}
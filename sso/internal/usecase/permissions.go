@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type PermissionsRepo interface {
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+}
+
+type RolePermissionsRepo interface {
+	PermissionsRepo
+	GrantPermission(ctx context.Context, role string, permission string) error
+	RevokePermission(ctx context.Context, role string, permission string) error
+}
+
+type permissionUsecase struct {
+	permissions RolePermissionsRepo
+	log         *slog.Logger
+}
+
+func NewPermissionUsecase(log *slog.Logger, permissions RolePermissionsRepo) *permissionUsecase {
+	return &permissionUsecase{
+		permissions: permissions,
+		log:         log,
+	}
+}
+
+func (u *permissionUsecase) ListRolePermissions(ctx context.Context, role string) ([]string, error) {
+	const op = "permissions.List"
+	log := u.log.With(slog.String("op", op), slog.String("role", role))
+
+	permissions, err := u.permissions.GetRolePermissions(ctx, role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get role permissions", "error", err)
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (u *permissionUsecase) GrantPermission(ctx context.Context, payload *dto.RolePermissionDTO) error {
+	const op = "permissions.Grant"
+	log := u.log.With(slog.String("op", op), slog.String("role", payload.Role), slog.String("permission", payload.Permission))
+
+	if err := u.permissions.GrantPermission(ctx, payload.Role, payload.Permission); err != nil {
+		if errors.Is(err, errs.ErrPermissionNotFound) {
+			log.InfoContext(ctx, "permission not found")
+			return err
+		}
+		log.ErrorContext(ctx, "failed to grant permission", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "permission granted")
+	return nil
+}
+
+func (u *permissionUsecase) RevokePermission(ctx context.Context, payload *dto.RolePermissionDTO) error {
+	const op = "permissions.Revoke"
+	log := u.log.With(slog.String("op", op), slog.String("role", payload.Role), slog.String("permission", payload.Permission))
+
+	// Admins must always be able to manage permissions, otherwise nobody could undo a mistake.
+	if payload.Role == constants.RoleAdmin && payload.Permission == constants.PermissionPermissionsManage {
+		log.InfoContext(ctx, "refusing to revoke protected permission")
+		return errs.ErrProtectedPermission
+	}
+
+	if err := u.permissions.RevokePermission(ctx, payload.Role, payload.Permission); err != nil {
+		if errors.Is(err, errs.ErrPermissionNotGranted) {
+			log.InfoContext(ctx, "permission not granted")
+			return err
+		}
+		log.ErrorContext(ctx, "failed to revoke permission", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "permission revoked")
+	return nil
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type CustomerProfileRepo interface {
+	GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error)
+}
+
+type WaiterProfileRepo interface {
+	GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error)
+}
+
+type AdminProfileRepo interface {
+	GetAdminByID(ctx context.Context, adminID string) (*entities.AdminEntity, error)
+}
+
+type CustomerUpdateRepo interface {
+	CustomerProfileRepo
+	CheckEmailExists(ctx context.Context, email string) (bool, error)
+	UpdateCustomerName(ctx context.Context, customerID string, name string) error
+}
+
+type WaiterUpdateRepo interface {
+	WaiterProfileRepo
+	UpdateWaiterName(ctx context.Context, waiterID string, firstName string, lastName string) error
+}
+
+type AdminUpdateRepo interface {
+	AdminProfileRepo
+	UpdateAdminNote(ctx context.Context, adminID string, note string) error
+}
+
+type profileUsecase struct {
+	customers CustomerUpdateRepo
+	waiters   WaiterUpdateRepo
+	admins    AdminUpdateRepo
+	verifier  EmailVerifier
+	log       *slog.Logger
+}
+
+func NewProfileUsecase(
+	log *slog.Logger,
+	customers CustomerUpdateRepo,
+	waiters WaiterUpdateRepo,
+	admins AdminUpdateRepo,
+	verifier EmailVerifier,
+) *profileUsecase {
+	return &profileUsecase{
+		customers: customers,
+		waiters:   waiters,
+		admins:    admins,
+		verifier:  verifier,
+		log:       log,
+	}
+}
+
+func (u *profileUsecase) GetProfile(ctx context.Context, entityID string, role string) (*dto.ProfileDTO, error) {
+	const op = "profile.Get"
+	log := u.log.With(slog.String("op", op), slog.String("entityId", entityID))
+
+	profile, err := u.getProfile(ctx, entityID, role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get profile", "error", err)
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (u *profileUsecase) UpdateProfile(ctx context.Context, entityID string, role string, payload *dto.UpdateProfileDTO) (*dto.ProfileDTO, error) {
+	const op = "profile.Update"
+	log := u.log.With(slog.String("op", op), slog.String("entityId", entityID))
+
+	log.InfoContext(ctx, "updating profile")
+
+	switch role {
+	case constants.RoleCustomer:
+		customer, err := u.customers.GetCustomerByID(ctx, entityID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get customer by id", "error", err)
+			return nil, err
+		}
+
+		if payload.Name != "" && payload.Name != customer.Name {
+			if err := u.customers.UpdateCustomerName(ctx, entityID, payload.Name); err != nil {
+				log.ErrorContext(ctx, "failed to update customer name", "error", err)
+				return nil, err
+			}
+		}
+
+		if payload.Email != "" && payload.Email != customer.Email {
+			isExists, err := u.customers.CheckEmailExists(ctx, payload.Email)
+			if err != nil {
+				log.ErrorContext(ctx, "failed to check email exists", "error", err)
+				return nil, err
+			}
+			if isExists {
+				log.InfoContext(ctx, "customer with this email already exists")
+				return nil, errs.ErrCustomerAlreadyExists
+			}
+			if err := u.verifier.SendVerification(ctx, entityID, payload.Email); err != nil {
+				log.ErrorContext(ctx, "failed to send verification email", "error", err)
+				return nil, err
+			}
+			log.InfoContext(ctx, "email change pending verification")
+		}
+
+	case constants.RoleWaiter:
+		waiter, err := u.waiters.GetWaiterByID(ctx, entityID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get waiter by id", "error", err)
+			return nil, err
+		}
+
+		firstName, lastName := waiter.FirstName, waiter.LastName
+		if payload.FirstName != "" {
+			firstName = payload.FirstName
+		}
+		if payload.LastName != "" {
+			lastName = payload.LastName
+		}
+		if err := u.waiters.UpdateWaiterName(ctx, entityID, firstName, lastName); err != nil {
+			log.ErrorContext(ctx, "failed to update waiter name", "error", err)
+			return nil, err
+		}
+
+	case constants.RoleAdmin:
+		if payload.Note != nil {
+			if err := u.admins.UpdateAdminNote(ctx, entityID, *payload.Note); err != nil {
+				log.ErrorContext(ctx, "failed to update admin note", "error", err)
+				return nil, err
+			}
+		}
+
+	default:
+		log.InfoContext(ctx, "unknown role", "role", role)
+		return nil, errs.ErrInvalidRole
+	}
+
+	profile, err := u.getProfile(ctx, entityID, role)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get profile", "error", err)
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "profile updated")
+	return profile, nil
+}
+
+func (u *profileUsecase) getProfile(ctx context.Context, entityID string, role string) (*dto.ProfileDTO, error) {
+	profile := &dto.ProfileDTO{Role: role}
+	var err error
+	switch role {
+	case constants.RoleCustomer:
+		profile.Customer, err = u.customers.GetCustomerByID(ctx, entityID)
+	case constants.RoleWaiter:
+		profile.Waiter, err = u.waiters.GetWaiterByID(ctx, entityID)
+	case constants.RoleAdmin:
+		profile.Admin, err = u.admins.GetAdminByID(ctx, entityID)
+	default:
+		return nil, errs.ErrInvalidRole
+	}
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
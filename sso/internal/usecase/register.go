@@ -2,50 +2,81 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
+type IdentityRegisterRepo interface {
+	GetIdentityByLogin(ctx context.Context, login string) (*entities.IdentityEntity, error)
+	GetRole(ctx context.Context, identityID string, role string) (*entities.IdentityRoleEntity, error)
+	CreateIdentity(ctx context.Context, login string, password []byte) (string, error)
+	DeleteIdentity(ctx context.Context, identityID string) error
+}
+
 type CustomerRegisterRepo interface {
-	CheckEmailExists(ctx context.Context, email string) (bool, error)
 	CreateCustomer(ctx context.Context, dto *dto.CreateCustomerDTO) (uuid.UUID, error)
 }
 
 type AdminRegisterRepo interface {
-	CheckLoginExists(ctx context.Context, login string) (bool, error)
 	CreateAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error)
+	CreateFirstAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error)
 }
 
 type WaiterRegisterRepo interface {
-	CheckLoginExists(ctx context.Context, login string) (bool, error)
 	CreateWaiter(ctx context.Context, dto *dto.CreateWaiterDTO) (uuid.UUID, error)
 }
 
+type InvitationConsumer interface {
+	ConsumeInvitation(ctx context.Context, codeHash []byte, role string) (*entities.InvitationEntity, error)
+	ReleaseInvitation(ctx context.Context, invitationID string) error
+}
+
+type EmailVerifier interface {
+	SendVerification(ctx context.Context, customerID string, email string) error
+}
+
 type registerUsecase struct {
-	customers CustomerRegisterRepo
-	admins    AdminRegisterRepo
-	waiters   WaiterRegisterRepo
-	log       *slog.Logger
-	secretKey string
+	identities  IdentityRegisterRepo
+	customers   CustomerRegisterRepo
+	admins      AdminRegisterRepo
+	waiters     WaiterRegisterRepo
+	verifier    EmailVerifier
+	invitations InvitationConsumer
+	attempts    AttemptsRepo
+	hasher      PasswordHasher
+	policy      PasswordPolicy
+	log         *slog.Logger
 }
 
 func NewRegisterUsecase(
 	log *slog.Logger,
+	identities IdentityRegisterRepo,
 	customers CustomerRegisterRepo,
 	waiters WaiterRegisterRepo,
 	admins AdminRegisterRepo,
-	secretKey string,
+	verifier EmailVerifier,
+	invitations InvitationConsumer,
+	attempts AttemptsRepo,
+	hasher PasswordHasher,
+	policy PasswordPolicy,
 ) *registerUsecase {
 	return &registerUsecase{
-		customers: customers,
-		admins:    admins,
-		waiters:   waiters,
-		log:       log,
-		secretKey: secretKey,
+		identities:  identities,
+		customers:   customers,
+		admins:      admins,
+		waiters:     waiters,
+		verifier:    verifier,
+		invitations: invitations,
+		attempts:    attempts,
+		hasher:      hasher,
+		policy:      policy,
+		log:         log,
 	}
 }
 
@@ -53,37 +84,32 @@ func (u *registerUsecase) RegisterCustomer(ctx context.Context, payload *dto.Reg
 	const op = "register.Customer"
 	log := u.log.With(slog.String("op", op), slog.String("email", payload.Email))
 
-	log.Info("registering customer")
+	log.InfoContext(ctx, "registering customer")
 
-	isExists, err := u.customers.CheckEmailExists(ctx, payload.Email)
+	identity, err := u.existingIdentity(ctx, log, payload.Email, payload.Password, payload.IP, constants.RoleCustomer, errs.ErrCustomerAlreadyExists)
 	if err != nil {
-		log.Error("failed to check email exists", "error", err)
 		return uuid.Nil, err
 	}
 
-	if isExists {
-		log.Info("customer with this email already exists")
-		return uuid.Nil, errs.ErrCustomerAlreadyExists
-	}
-
-	hashedPassword, err := u.HashPassword(payload.Password)
+	id, err := u.withIdentity(ctx, log, identity, payload.Email, payload.Password, errs.ErrCustomerAlreadyExists, func(identityID string) (uuid.UUID, error) {
+		return u.customers.CreateCustomer(ctx, &dto.CreateCustomerDTO{
+			IdentityID: identityID,
+			Email:      payload.Email,
+			Name:       payload.Name,
+			Birthdate:  &payload.Birthdate,
+		})
+	})
 	if err != nil {
-		log.Error("failed to hash password", "error", err)
+		log.ErrorContext(ctx, "failed to create customer", "error", err)
 		return uuid.Nil, err
 	}
 
-	id, err := u.customers.CreateCustomer(ctx, &dto.CreateCustomerDTO{
-		Email:     payload.Email,
-		Name:      payload.Name,
-		Birthdate: payload.Birthdate,
-		Password:  hashedPassword,
-	})
-	if err != nil {
-		log.Error("failed to create customer", "error", err)
-		return uuid.Nil, err
+	log.InfoContext(ctx, "customer registered", "customerId", id)
+
+	if err := u.verifier.SendVerification(ctx, id.String(), payload.Email); err != nil {
+		log.ErrorContext(ctx, "failed to send verification email", "error", err)
 	}
 
-	log.Info("customer registered", "customerId", id)
 	return id, nil
 }
 
@@ -91,41 +117,41 @@ func (u *registerUsecase) RegisterWaiter(ctx context.Context, payload *dto.Regis
 	const op = "register.Waiter"
 	log := u.log.With(slog.String("op", op), slog.String("login", payload.Login))
 
-	log.Info("registering waiter")
+	log.InfoContext(ctx, "registering waiter")
 
-	if payload.Token != u.secretKey {
-		log.Info("invalid secret token")
-		return uuid.Nil, errs.ErrInvalidSecretToken
-	}
-	isExists, err := u.waiters.CheckLoginExists(ctx, payload.Login)
+	invitation, err := u.consumeInvitation(ctx, log, payload.InvitationCode, constants.RoleWaiter)
 	if err != nil {
-		log.Error("failed to check login exists", "error", err)
 		return uuid.Nil, err
 	}
 
-	if isExists {
-		log.Info("waiter with this login already exists")
-		return uuid.Nil, errs.ErrWaiterAlreadyExists
-	}
-
-	hashedPassword, err := u.HashPassword(payload.Password)
+	identity, err := u.existingIdentity(ctx, log, payload.Login, payload.Password, payload.IP, constants.RoleWaiter, errs.ErrWaiterAlreadyExists)
 	if err != nil {
-		log.Error("failed to hash password", "error", err)
+		u.releaseInvitation(ctx, log, invitation.InvitationID)
 		return uuid.Nil, err
 	}
 
-	id, err := u.waiters.CreateWaiter(ctx, &dto.CreateWaiterDTO{
-		Login:     payload.Login,
-		Password:  hashedPassword,
-		FirstName: payload.FirstName,
-		LastName:  payload.LastName,
+	firstName := prefilled(payload.FirstName, invitation.FirstName)
+	lastName := prefilled(payload.LastName, invitation.LastName)
+	if firstName == "" || lastName == "" {
+		log.InfoContext(ctx, "waiter name is required")
+		u.releaseInvitation(ctx, log, invitation.InvitationID)
+		return uuid.Nil, errs.ErrNameRequired
+	}
+
+	id, err := u.withIdentity(ctx, log, identity, payload.Login, payload.Password, errs.ErrWaiterAlreadyExists, func(identityID string) (uuid.UUID, error) {
+		return u.waiters.CreateWaiter(ctx, &dto.CreateWaiterDTO{
+			IdentityID: identityID,
+			FirstName:  firstName,
+			LastName:   lastName,
+		})
 	})
 	if err != nil {
-		log.Error("failed to create waiter", "error", err)
+		log.ErrorContext(ctx, "failed to create waiter", "error", err)
+		u.releaseInvitation(ctx, log, invitation.InvitationID)
 		return uuid.Nil, err
 	}
 
-	log.Info("waiter registered", "waiterId", id)
+	log.InfoContext(ctx, "waiter registered", "waiterId", id)
 	return id, nil
 }
 
@@ -133,44 +159,165 @@ func (u *registerUsecase) RegisterAdmin(ctx context.Context, payload *dto.Regist
 	const op = "register.Admin"
 	log := u.log.With(slog.String("op", op), slog.String("login", payload.Login))
 
-	log.Info("registering admin")
+	log.InfoContext(ctx, "registering admin")
 
-	if payload.Token != u.secretKey {
-		log.Info("invalid secret token")
-		return uuid.Nil, errs.ErrInvalidSecretToken
+	invitation, err := u.consumeInvitation(ctx, log, payload.InvitationCode, constants.RoleAdmin)
+	if err != nil {
+		return uuid.Nil, err
 	}
 
-	isExists, err := u.admins.CheckLoginExists(ctx, payload.Login)
+	identity, err := u.existingIdentity(ctx, log, payload.Login, payload.Password, payload.IP, constants.RoleAdmin, errs.ErrAdminAlreadyExists)
 	if err != nil {
-		log.Error("failed to check login exists", "error", err)
+		u.releaseInvitation(ctx, log, invitation.InvitationID)
 		return uuid.Nil, err
 	}
 
-	if isExists {
-		log.Info("admin with this login already exists")
-		return uuid.Nil, errs.ErrAdminAlreadyExists
+	id, err := u.withIdentity(ctx, log, identity, payload.Login, payload.Password, errs.ErrAdminAlreadyExists, func(identityID string) (uuid.UUID, error) {
+		return u.admins.CreateAdmin(ctx, &dto.CreateAdminDTO{
+			IdentityID: identityID,
+			Note:       prefilled(payload.Note, invitation.Note),
+		})
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to create admin", "error", err)
+		u.releaseInvitation(ctx, log, invitation.InvitationID)
+		return uuid.Nil, err
 	}
 
-	hashedPassword, err := u.HashPassword(payload.Password)
+	log.InfoContext(ctx, "admin registered", "adminId", id)
+	return id, nil
+}
+
+func (u *registerUsecase) BootstrapAdmin(ctx context.Context, payload *dto.BootstrapAdminDTO) (uuid.UUID, error) {
+	const op = "register.BootstrapAdmin"
+	log := u.log.With(slog.String("op", op), slog.String("login", payload.Login))
+
+	log.InfoContext(ctx, "bootstrapping admin")
+
+	identity, err := u.existingIdentity(ctx, log, payload.Login, payload.Password, "", constants.RoleAdmin, errs.ErrAdminAlreadyExists)
 	if err != nil {
-		log.Error("failed to hash password", "error", err)
 		return uuid.Nil, err
 	}
 
-	id, err := u.admins.CreateAdmin(ctx, &dto.CreateAdminDTO{
-		Login:    payload.Login,
-		Password: hashedPassword,
-		Note:     payload.Note,
+	id, err := u.withIdentity(ctx, log, identity, payload.Login, payload.Password, errs.ErrAdminAlreadyExists, func(identityID string) (uuid.UUID, error) {
+		return u.admins.CreateFirstAdmin(ctx, &dto.CreateAdminDTO{
+			IdentityID: identityID,
+			Note:       payload.Note,
+		})
 	})
 	if err != nil {
-		log.Error("failed to create admin", "error", err)
+		if errors.Is(err, errs.ErrAlreadyBootstrapped) {
+			log.InfoContext(ctx, "admin already exists")
+			return uuid.Nil, errs.ErrAlreadyBootstrapped
+		}
+		log.ErrorContext(ctx, "failed to create admin", "error", err)
+		return uuid.Nil, err
+	}
+
+	log.InfoContext(ctx, "admin bootstrapped", "adminId", id)
+	return id, nil
+}
+
+func (u *registerUsecase) existingIdentity(ctx context.Context, log *slog.Logger, login, password, ip, role string, alreadyExists error) (*entities.IdentityEntity, error) {
+	identity, err := u.identities.GetIdentityByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, errs.ErrIdentityNotFound) {
+			if err := u.policy.Validate(password, login); err != nil {
+				log.InfoContext(ctx, "password rejected by policy", "error", err)
+				return nil, err
+			}
+			return nil, nil
+		}
+		log.ErrorContext(ctx, "failed to get identity by login", "error", err)
+		return nil, err
+	}
+
+	// Bootstrapping runs locally without a limiter.
+	limited := u.attempts != nil
+	if limited {
+		if err := registerAttempt(ctx, log, u.attempts, role, login, ip); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.hasher.Compare(identity.Password, password); err != nil {
+		log.InfoContext(ctx, "login already taken")
+		return nil, alreadyExists
+	}
+	if limited {
+		resetAttempts(ctx, log, u.attempts, role, login)
+	}
+
+	_, err = u.identities.GetRole(ctx, identity.IdentityID, role)
+	if err == nil {
+		log.InfoContext(ctx, "role already granted")
+		return nil, alreadyExists
+	}
+	if !errors.Is(err, errs.ErrRoleNotGranted) {
+		log.ErrorContext(ctx, "failed to get identity role", "error", err)
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "attaching role to existing identity", "identityId", identity.IdentityID)
+	return identity, nil
+}
+
+func (u *registerUsecase) withIdentity(
+	ctx context.Context,
+	log *slog.Logger,
+	identity *entities.IdentityEntity,
+	login, password string,
+	alreadyExists error,
+	create func(identityID string) (uuid.UUID, error),
+) (uuid.UUID, error) {
+	if identity != nil {
+		return create(identity.IdentityID)
+	}
+
+	hashedPassword, err := u.hasher.Hash(password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	identityID, err := u.identities.CreateIdentity(ctx, login, hashedPassword)
+	if err != nil {
+		if errors.Is(err, errs.ErrLoginTaken) {
+			return uuid.Nil, alreadyExists
+		}
 		return uuid.Nil, err
 	}
 
-	log.Info("admin registered", "adminId", id)
+	id, err := create(identityID)
+	if err != nil {
+		if err := u.identities.DeleteIdentity(ctx, identityID); err != nil {
+			log.ErrorContext(ctx, "failed to delete identity", "error", err, "identityId", identityID)
+		}
+		return uuid.Nil, err
+	}
 	return id, nil
 }
 
-func (u *registerUsecase) HashPassword(password string) ([]byte, error) {
-	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (u *registerUsecase) consumeInvitation(ctx context.Context, log *slog.Logger, code string, role string) (*entities.InvitationEntity, error) {
+	invitation, err := u.invitations.ConsumeInvitation(ctx, HashInvitationCode(code), role)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidInvitation) {
+			log.InfoContext(ctx, "invalid invitation")
+			return nil, errs.ErrInvalidInvitation
+		}
+		log.ErrorContext(ctx, "failed to consume invitation", "error", err)
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (u *registerUsecase) releaseInvitation(ctx context.Context, log *slog.Logger, invitationID string) {
+	if err := u.invitations.ReleaseInvitation(ctx, invitationID); err != nil {
+		log.ErrorContext(ctx, "failed to release invitation", "error", err, "invitationId", invitationID)
+	}
+}
+
+func prefilled(value string, fallback *string) string {
+	if value == "" && fallback != nil {
+		return *fallback
+	}
+	return value
 }
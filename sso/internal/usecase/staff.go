@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+const defaultPageSize = 20
+
+type StaffRepo interface {
+	GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error)
+	ListWaiters(ctx context.Context, filter *dto.WaiterFilterDTO) ([]*entities.WaiterEntity, int, error)
+	FireWaiter(ctx context.Context, waiterID string, reason string) error
+	RehireWaiter(ctx context.Context, waiterID string) error
+}
+
+type AdminDirectoryRepo interface {
+	ListAdmins(ctx context.Context, limit, offset int) ([]*entities.AdminEntity, int, error)
+}
+
+type staffUsecase struct {
+	waiters StaffRepo
+	admins  AdminDirectoryRepo
+	tokens  TokensRevoker
+	log     *slog.Logger
+}
+
+func NewStaffUsecase(log *slog.Logger, waiters StaffRepo, admins AdminDirectoryRepo, tokens TokensRevoker) *staffUsecase {
+	return &staffUsecase{
+		waiters: waiters,
+		admins:  admins,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+func (u *staffUsecase) GetWaiter(ctx context.Context, waiterID string) (*entities.WaiterEntity, error) {
+	const op = "staff.GetWaiter"
+	log := u.log.With(slog.String("op", op), slog.String("waiterId", waiterID))
+
+	waiter, err := u.waiters.GetWaiterByID(ctx, waiterID)
+	if err != nil {
+		if errors.Is(err, errs.ErrWaiterNotFound) {
+			log.InfoContext(ctx, "waiter not found")
+			return nil, errs.ErrWaiterNotFound
+		}
+		log.ErrorContext(ctx, "failed to get waiter by id", "error", err)
+		return nil, err
+	}
+	return waiter, nil
+}
+
+func (u *staffUsecase) ListWaiters(ctx context.Context, payload *dto.ListWaitersDTO) (*dto.WaitersPageDTO, error) {
+	const op = "staff.ListWaiters"
+	log := u.log.With(slog.String("op", op))
+
+	limit, offset := paginate(payload.Page, payload.PageSize)
+	waiters, total, err := u.waiters.ListWaiters(ctx, &dto.WaiterFilterDTO{
+		Status: payload.Status,
+		SortBy: payload.SortBy,
+		Desc:   payload.Desc,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to list waiters", "error", err)
+		return nil, err
+	}
+	return &dto.WaitersPageDTO{Waiters: waiters, Total: total}, nil
+}
+
+func (u *staffUsecase) ListAdmins(ctx context.Context, payload *dto.ListAdminsDTO) (*dto.AdminsPageDTO, error) {
+	const op = "staff.ListAdmins"
+	log := u.log.With(slog.String("op", op))
+
+	limit, offset := paginate(payload.Page, payload.PageSize)
+	admins, total, err := u.admins.ListAdmins(ctx, limit, offset)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to list admins", "error", err)
+		return nil, err
+	}
+	return &dto.AdminsPageDTO{Admins: admins, Total: total}, nil
+}
+
+func (u *staffUsecase) FireWaiter(ctx context.Context, payload *dto.FireWaiterDTO) error {
+	const op = "staff.FireWaiter"
+	log := u.log.With(slog.String("op", op), slog.String("waiterId", payload.WaiterID))
+
+	log.InfoContext(ctx, "firing waiter")
+
+	waiter, err := u.waiters.GetWaiterByID(ctx, payload.WaiterID)
+	if err != nil {
+		if errors.Is(err, errs.ErrWaiterNotFound) {
+			log.InfoContext(ctx, "waiter not found")
+			return errs.ErrWaiterNotFound
+		}
+		log.ErrorContext(ctx, "failed to get waiter by id", "error", err)
+		return err
+	}
+	if waiter.FiredAt != nil {
+		log.InfoContext(ctx, "waiter already fired")
+		return errs.ErrWaiterFired
+	}
+
+	if err := u.waiters.FireWaiter(ctx, payload.WaiterID, payload.Reason); err != nil {
+		if errors.Is(err, errs.ErrWaiterFired) {
+			log.InfoContext(ctx, "waiter already fired")
+			return errs.ErrWaiterFired
+		}
+		log.ErrorContext(ctx, "failed to fire waiter", "error", err)
+		return err
+	}
+
+	if err := u.tokens.RevokeAllRefreshTokens(ctx, payload.WaiterID); err != nil {
+		log.ErrorContext(ctx, "failed to revoke refresh tokens", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "waiter fired")
+	return nil
+}
+
+func (u *staffUsecase) RehireWaiter(ctx context.Context, waiterID string) error {
+	const op = "staff.RehireWaiter"
+	log := u.log.With(slog.String("op", op), slog.String("waiterId", waiterID))
+
+	log.InfoContext(ctx, "rehiring waiter")
+
+	waiter, err := u.waiters.GetWaiterByID(ctx, waiterID)
+	if err != nil {
+		if errors.Is(err, errs.ErrWaiterNotFound) {
+			log.InfoContext(ctx, "waiter not found")
+			return errs.ErrWaiterNotFound
+		}
+		log.ErrorContext(ctx, "failed to get waiter by id", "error", err)
+		return err
+	}
+	if waiter.FiredAt == nil {
+		log.InfoContext(ctx, "waiter is not fired")
+		return errs.ErrWaiterNotFired
+	}
+
+	if err := u.waiters.RehireWaiter(ctx, waiterID); err != nil {
+		log.ErrorContext(ctx, "failed to rehire waiter", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "waiter rehired")
+	return nil
+}
+
+func paginate(page, pageSize int) (limit, offset int) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return pageSize, (page - 1) * pageSize
+}
@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccountUsecase_ExportMyData(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerAccountRepo)
+	reservationRepo := new(mockReservationHistoryRepo)
+	auditRepo := new(mockAuditRepo)
+	usecase := usecase.NewAccountUsecase(NewTestLogger(), nil, customerRepo, reservationRepo, auditRepo, nil, nil, nil, testHasher, "")
+
+	t.Run("success", func(t *testing.T) {
+		customer := &entities.CustomerEntity{CustomerID: "123", Email: "test@example.com", Name: "John"}
+		reservations := []*entities.ReservationEntity{
+			{ReservationID: "r1", TableID: "t1", Status: constants.ReservationStatusClosed},
+		}
+		customerRepo.On("GetCustomerByID", ctx, "123").Return(customer, nil)
+		reservationRepo.On("GetCustomerReservations", ctx, "123").Return(reservations, nil)
+		auditRepo.On("RecordEvent", ctx, "123", constants.RoleCustomer, constants.AuditActionDataExported).Return(nil)
+
+		data, err := usecase.ExportMyData(ctx, "123")
+
+		assert.NoError(t, err)
+		var export dto.DataExportDTO
+		assert.NoError(t, json.Unmarshal(data, &export))
+		assert.Equal(t, "test@example.com", export.Customer.Email)
+		assert.Len(t, export.Reservations, 1)
+		assert.Equal(t, "r1", export.Reservations[0].ReservationID)
+		auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("deleted customer", func(t *testing.T) {
+		deletedAt := time.Now()
+		customerRepo.On("GetCustomerByID", ctx, "456").Return(&entities.CustomerEntity{CustomerID: "456", DeletedAt: &deletedAt}, nil)
+
+		data, err := usecase.ExportMyData(ctx, "456")
+
+		assert.Nil(t, data)
+		assert.ErrorIs(t, err, errs.ErrCustomerNotFound)
+		reservationRepo.AssertNotCalled(t, "GetCustomerReservations", ctx, "456")
+	})
+}
+
+func TestAccountUsecase_DeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	identityRepo := new(mockIdentityRepo)
+	customerRepo := new(mockCustomerAccountRepo)
+	tokens := new(mockTokensRevoker)
+	deletionRepo := new(mockDeletionRepo)
+	usecase := usecase.NewAccountUsecase(NewTestLogger(), identityRepo, customerRepo, nil, nil, tokens, deletionRepo, nil, testHasher, "http://localhost:3000/delete-account")
+
+	t.Run("success", func(t *testing.T) {
+		customerRepo.On("GetCustomerByID", ctx, "123").Return(&entities.CustomerEntity{CustomerID: "123"}, nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "123").Return(newIdentity("id-1", "test@example.com", "password123"), nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "123").Return(nil)
+		customerRepo.On("DeleteCustomer", ctx, "123").Return(nil)
+
+		err := usecase.DeleteAccount(ctx, "123", &dto.DeleteAccountDTO{Password: "password123"})
+
+		assert.NoError(t, err)
+		customerRepo.AssertExpectations(t)
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		customerRepo.On("GetCustomerByID", ctx, "456").Return(&entities.CustomerEntity{CustomerID: "456"}, nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "456").Return(newIdentity("id-2", "other@example.com", "password123"), nil)
+
+		err := usecase.DeleteAccount(ctx, "456", &dto.DeleteAccountDTO{Password: "wrong"})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidPassword)
+		customerRepo.AssertNotCalled(t, "DeleteCustomer", ctx, "456")
+		tokens.AssertNotCalled(t, "RevokeAllRefreshTokens", ctx, "456")
+	})
+
+	t.Run("password not set", func(t *testing.T) {
+		customerRepo.On("GetCustomerByID", ctx, "789").Return(&entities.CustomerEntity{CustomerID: "789"}, nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "789").Return(&entities.IdentityEntity{IdentityID: "id-3", Login: "oidc@example.com"}, nil)
+
+		err := usecase.DeleteAccount(ctx, "789", &dto.DeleteAccountDTO{Password: "anything"})
+
+		assert.ErrorIs(t, err, errs.ErrPasswordNotSet)
+		customerRepo.AssertNotCalled(t, "DeleteCustomer", ctx, "789")
+	})
+
+	t.Run("confirmed by email", func(t *testing.T) {
+		deletionRepo.On("ConsumeDeletionToken", ctx, "deletion-token").Return("789", nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "789").Return(nil)
+		customerRepo.On("DeleteCustomer", ctx, "789").Return(nil)
+
+		err := usecase.DeleteAccount(ctx, "789", &dto.DeleteAccountDTO{Token: "deletion-token"})
+
+		assert.NoError(t, err)
+		customerRepo.AssertCalled(t, "DeleteCustomer", ctx, "789")
+	})
+
+	t.Run("token of another customer", func(t *testing.T) {
+		deletionRepo.On("ConsumeDeletionToken", ctx, "foreign-token").Return("789", nil)
+
+		err := usecase.DeleteAccount(ctx, "456", &dto.DeleteAccountDTO{Token: "foreign-token"})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidDeletionToken)
+		customerRepo.AssertNotCalled(t, "DeleteCustomer", ctx, "456")
+	})
+
+	t.Run("revocation failure", func(t *testing.T) {
+		customerRepo.On("GetCustomerByID", ctx, "321").Return(&entities.CustomerEntity{CustomerID: "321"}, nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "321").Return(newIdentity("id-4", "revoke@example.com", "password123"), nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "321").Return(assert.AnError)
+
+		err := usecase.DeleteAccount(ctx, "321", &dto.DeleteAccountDTO{Password: "password123"})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		customerRepo.AssertNotCalled(t, "DeleteCustomer", ctx, "321")
+	})
+}
+
+func TestAccountUsecase_RequestAccountDeletion(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerAccountRepo)
+	deletionRepo := new(mockDeletionRepo)
+	mailer := new(mockMailer)
+	usecase := usecase.NewAccountUsecase(NewTestLogger(), nil, customerRepo, nil, nil, nil, deletionRepo, mailer, testHasher, "http://localhost:3000/delete-account")
+
+	customerRepo.On("GetCustomerByID", ctx, "123").Return(&entities.CustomerEntity{CustomerID: "123", Email: "test@example.com"}, nil)
+	deletionRepo.On("GenerateDeletionToken", ctx, "123").Return("deletion-token", nil)
+	mailer.On("Send", ctx, "test@example.com", "Account deletion", mock.MatchedBy(func(body string) bool {
+		return strings.Contains(body, "http://localhost:3000/delete-account?token=deletion-token")
+	})).Return(nil)
+
+	err := usecase.RequestAccountDeletion(ctx, "123")
+
+	assert.NoError(t, err)
+	mailer.AssertExpectations(t)
+}
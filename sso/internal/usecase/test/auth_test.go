@@ -3,162 +3,228 @@ package usecase_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/SergeyBogomolovv/restaurant/common/config"
 	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/metrics"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/passwords"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
 	"github.com/SergeyBogomolovv/restaurant/sso/pkg/payload"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	testPasswordConfig = config.PasswordConfig{
+		MinLength: 8,
+		Algorithm: passwords.AlgorithmArgon2id,
+		Argon2:    config.Argon2Config{Memory: 1024, Iterations: 1, Parallelism: 1},
+	}
+	testHasher, _ = passwords.NewHasher(testPasswordConfig)
+	testPolicy, _ = passwords.NewPolicy(testPasswordConfig)
+)
+
+func newIdentity(identityID, login, password string) *entities.IdentityEntity {
+	hashedPassword, _ := testHasher.Hash(password)
+	return &entities.IdentityEntity{IdentityID: identityID, Login: login, Password: hashedPassword}
+}
+
+func permissiveAttempts(ctx context.Context) *mockAttemptsRepo {
+	attempts := new(mockAttemptsRepo)
+	attempts.On("RegisterAttempt", ctx, mock.Anything, mock.Anything, mock.Anything).Return(time.Duration(0), nil)
+	attempts.On("ResetAttempts", ctx, mock.Anything, mock.Anything).Return(nil)
+	return attempts
+}
+
+func rolePermissions() *mockPermissionsRepo {
+	permissions := new(mockPermissionsRepo)
+	permissions.On("GetRolePermissions", mock.Anything, constants.RoleCustomer).Return([]string{constants.PermissionReservationsCreate}, nil)
+	permissions.On("GetRolePermissions", mock.Anything, constants.RoleWaiter).Return([]string{constants.PermissionReservationsClose}, nil)
+	permissions.On("GetRolePermissions", mock.Anything, constants.RoleAdmin).Return([]string{constants.PermissionStaffManage, constants.PermissionPermissionsManage}, nil)
+	return permissions
+}
+
+func loginCount(role, outcome string) float64 {
+	return testutil.ToFloat64(metrics.Logins.WithLabelValues(role, outcome))
+}
+
 func TestAuthUsecase_LoginCustomer(t *testing.T) {
 	ctx := context.Background()
+	mockIdentityRepo := new(mockIdentityRepo)
 	mockCustomerRepo := new(mockCustomerAuthRepo)
 	mockTokensRepo := new(mockTokensRepo)
-
-	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockCustomerRepo, nil, nil, mockTokensRepo)
+	mockMFARepo := new(mockMFARepo)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockIdentityRepo, mockCustomerRepo, nil, mockTokensRepo, rolePermissions(), mockMFARepo, permissiveAttempts(ctx), testHasher)
 
 	t.Run("success", func(t *testing.T) {
-		password := "password123"
 		email := "test@example.com"
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		customer := &entities.CustomerEntity{
-			CustomerID: "123",
-			Password:   hashedPassword,
-		}
+		identity := newIdentity("id-123", email, "password123")
 
-		mockCustomerRepo.On("GetCustomerByEmail", ctx, email).Return(customer, nil)
-		mockTokensRepo.On("SignAccessToken", customer.CustomerID, constants.RoleCustomer).Return("access-token", nil)
-		mockTokensRepo.On("GenerateRefreshToken", ctx, customer.CustomerID, constants.RoleCustomer).Return("refresh-token", nil)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, email).Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleCustomer).Return(&entities.IdentityRoleEntity{EntityID: "123"}, nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "123").Return(&entities.CustomerEntity{CustomerID: "123"}, nil)
+		mockMFARepo.On("IsMFAEnabled", ctx, "123").Return(false, nil)
+		mockTokensRepo.On("SignAccessToken", "123", constants.RoleCustomer, []string{constants.PermissionReservationsCreate}).Return("access-token", nil)
+		mockTokensRepo.On("GenerateRefreshToken", ctx, "123", constants.RoleCustomer).Return("refresh-token", nil)
+		before := loginCount(constants.RoleCustomer, metrics.OutcomeSuccess)
 
-		loginDTO := &dto.LoginCustomerDTO{Email: email, Password: password}
-
-		tokens, err := usecase.LoginCustomer(ctx, loginDTO)
+		tokens, err := usecase.LoginCustomer(ctx, &dto.LoginCustomerDTO{Email: email, Password: "password123"})
 
+		assert.Equal(t, before+1, loginCount(constants.RoleCustomer, metrics.OutcomeSuccess))
 		assert.NoError(t, err)
 		assert.Equal(t, "access-token", tokens.AccessToken)
 		assert.Equal(t, "refresh-token", tokens.RefreshToken)
-		mockCustomerRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
 		mockTokensRepo.AssertExpectations(t)
 	})
 
+	t.Run("mfa required", func(t *testing.T) {
+		email := "mfa@example.com"
+		identity := newIdentity("id-321", email, "password123")
+
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, email).Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleCustomer).Return(&entities.IdentityRoleEntity{EntityID: "321"}, nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "321").Return(&entities.CustomerEntity{CustomerID: "321"}, nil)
+		mockMFARepo.On("IsMFAEnabled", ctx, "321").Return(true, nil)
+		mockTokensRepo.On("GenerateMFAToken", ctx, "321", constants.RoleCustomer).Return("mfa-token", nil)
+		before := loginCount(constants.RoleCustomer, metrics.OutcomeMFARequired)
+
+		tokens, err := usecase.LoginCustomer(ctx, &dto.LoginCustomerDTO{Email: email, Password: "password123"})
+
+		assert.Equal(t, before+1, loginCount(constants.RoleCustomer, metrics.OutcomeMFARequired))
+		assert.NoError(t, err)
+		assert.Equal(t, "mfa-token", tokens.MFAToken)
+		assert.Empty(t, tokens.AccessToken)
+		assert.Empty(t, tokens.RefreshToken)
+		mockTokensRepo.AssertNotCalled(t, "SignAccessToken", "321", constants.RoleCustomer, mock.Anything)
+		mockMFARepo.AssertExpectations(t)
+	})
+
 	t.Run("customer not found", func(t *testing.T) {
 		email := "notfound@example.com"
-		mockCustomerRepo.On("GetCustomerByEmail", ctx, email).Return(nil, errs.ErrCustomerNotFound)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, email).Return(nil, errs.ErrIdentityNotFound)
+		before := loginCount(constants.RoleCustomer, metrics.OutcomeInvalidCredentials)
 
-		loginDTO := &dto.LoginCustomerDTO{Email: email, Password: "password123"}
-		tokens, err := usecase.LoginCustomer(ctx, loginDTO)
+		tokens, err := usecase.LoginCustomer(ctx, &dto.LoginCustomerDTO{Email: email, Password: "password123"})
 
+		assert.Equal(t, before+1, loginCount(constants.RoleCustomer, metrics.OutcomeInvalidCredentials))
 		assert.Nil(t, tokens)
 		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
-		mockCustomerRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
 	})
 
 	t.Run("invalid password", func(t *testing.T) {
 		email := "wrongpassword@example.com"
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.DefaultCost)
-		customer := &entities.CustomerEntity{
-			CustomerID: "123",
-			Password:   hashedPassword,
-		}
-		mockCustomerRepo.On("GetCustomerByEmail", ctx, email).Return(customer, nil)
+		identity := newIdentity("id-124", email, "correctpassword")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, email).Return(identity, nil)
 
-		loginDTO := &dto.LoginCustomerDTO{Email: email, Password: "wrongpassword"}
-		tokens, err := usecase.LoginCustomer(ctx, loginDTO)
+		tokens, err := usecase.LoginCustomer(ctx, &dto.LoginCustomerDTO{Email: email, Password: "wrongpassword"})
 
 		assert.Nil(t, tokens)
 		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
-		mockCustomerRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertNotCalled(t, "GetRole", ctx, identity.IdentityID, constants.RoleCustomer)
+	})
+
+	t.Run("blocked customer", func(t *testing.T) {
+		email := "blocked@example.com"
+		blockedAt := time.Now()
+		identity := newIdentity("id-654", email, "password123")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, email).Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleCustomer).Return(&entities.IdentityRoleEntity{EntityID: "654"}, nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "654").Return(&entities.CustomerEntity{CustomerID: "654", BlockedAt: &blockedAt}, nil)
+		before := loginCount(constants.RoleCustomer, metrics.OutcomeBlocked)
+
+		tokens, err := usecase.LoginCustomer(ctx, &dto.LoginCustomerDTO{Email: email, Password: "password123"})
+
+		assert.Equal(t, before+1, loginCount(constants.RoleCustomer, metrics.OutcomeBlocked))
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrCustomerBlocked)
+		mockTokensRepo.AssertNotCalled(t, "SignAccessToken", "654", constants.RoleCustomer, mock.Anything)
 	})
 }
 
 func TestAuthUsecase_LoginAdmin(t *testing.T) {
 	ctx := context.Background()
-	mockAdminRepo := new(mockAdminAuthRepo)
+	mockIdentityRepo := new(mockIdentityRepo)
 	mockTokensRepo := new(mockTokensRepo)
-
-	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, nil, mockAdminRepo, mockTokensRepo)
+	mockMFARepo := new(mockMFARepo)
+	mockMFARepo.On("IsMFAEnabled", mock.Anything, mock.Anything).Return(false, nil)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockIdentityRepo, nil, nil, mockTokensRepo, rolePermissions(), mockMFARepo, permissiveAttempts(ctx), testHasher)
 
 	t.Run("success", func(t *testing.T) {
-		password := "adminpass123"
-		login := "adminlogin"
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		admin := &entities.AdminEntity{
-			AdminID:  "456",
-			Password: hashedPassword,
-		}
-
-		mockAdminRepo.On("GetAdminByLogin", ctx, login).Return(admin, nil)
-		mockTokensRepo.On("SignAccessToken", admin.AdminID, constants.RoleAdmin).Return("admin-access-token", nil)
-		mockTokensRepo.On("GenerateRefreshToken", ctx, admin.AdminID, constants.RoleAdmin).Return("admin-refresh-token", nil)
-
-		loginDTO := &dto.LoginEmployeeDTO{Login: login, Password: password}
+		identity := newIdentity("id-456", "adminlogin", "adminpass123")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "adminlogin").Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleAdmin).Return(&entities.IdentityRoleEntity{EntityID: "456"}, nil)
+		mockTokensRepo.On("SignAccessToken", "456", constants.RoleAdmin, mock.Anything).Return("admin-access-token", nil)
+		mockTokensRepo.On("GenerateRefreshToken", ctx, "456", constants.RoleAdmin).Return("admin-refresh-token", nil)
 
-		tokens, err := usecase.LoginAdmin(ctx, loginDTO)
+		tokens, err := usecase.LoginAdmin(ctx, &dto.LoginEmployeeDTO{Login: "adminlogin", Password: "adminpass123"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, "admin-access-token", tokens.AccessToken)
 		assert.Equal(t, "admin-refresh-token", tokens.RefreshToken)
-		mockAdminRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
 		mockTokensRepo.AssertExpectations(t)
 	})
 
 	t.Run("admin not found", func(t *testing.T) {
-		login := "unknownadmin"
-		mockAdminRepo.On("GetAdminByLogin", ctx, login).Return(nil, errs.ErrAdminNotFound)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "unknownadmin").Return(nil, errs.ErrIdentityNotFound)
 
-		loginDTO := &dto.LoginEmployeeDTO{Login: login, Password: "wrongpass"}
-		tokens, err := usecase.LoginAdmin(ctx, loginDTO)
+		tokens, err := usecase.LoginAdmin(ctx, &dto.LoginEmployeeDTO{Login: "unknownadmin", Password: "wrongpass"})
 
 		assert.Nil(t, tokens)
 		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
-		mockAdminRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
 	})
 
 	t.Run("invalid password", func(t *testing.T) {
-		login := "adminlogin"
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpass"), bcrypt.DefaultCost)
-		admin := &entities.AdminEntity{
-			AdminID:  "456",
-			Password: hashedPassword,
-		}
-		mockAdminRepo.On("GetAdminByLogin", ctx, login).Return(admin, nil)
+		identity := newIdentity("id-458", "adminlogin2", "correctpass")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "adminlogin2").Return(identity, nil)
+
+		tokens, err := usecase.LoginAdmin(ctx, &dto.LoginEmployeeDTO{Login: "adminlogin2", Password: "wrongpass"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
+		mockIdentityRepo.AssertNotCalled(t, "GetRole", ctx, identity.IdentityID, constants.RoleAdmin)
+	})
+
+	t.Run("role not granted", func(t *testing.T) {
+		identity := newIdentity("id-457", "waiteronly", "password123")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "waiteronly").Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleAdmin).Return(nil, errs.ErrRoleNotGranted)
 
-		loginDTO := &dto.LoginEmployeeDTO{Login: login, Password: "wrongpass"}
-		tokens, err := usecase.LoginAdmin(ctx, loginDTO)
+		tokens, err := usecase.LoginAdmin(ctx, &dto.LoginEmployeeDTO{Login: "waiteronly", Password: "password123"})
 
 		assert.Nil(t, tokens)
 		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
-		mockAdminRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
 	})
 }
 
 func TestAuthUsecase_LoginWaiter(t *testing.T) {
 	ctx := context.Background()
+	mockIdentityRepo := new(mockIdentityRepo)
 	mockWaiterRepo := new(mockWaiterAuthRepo)
 	mockTokensRepo := new(mockTokensRepo)
-
-	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, mockWaiterRepo, nil, mockTokensRepo)
+	mockMFARepo := new(mockMFARepo)
+	mockMFARepo.On("IsMFAEnabled", mock.Anything, mock.Anything).Return(false, nil)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockIdentityRepo, nil, mockWaiterRepo, mockTokensRepo, rolePermissions(), mockMFARepo, permissiveAttempts(ctx), testHasher)
 
 	t.Run("success", func(t *testing.T) {
-		password := "waiterpass123"
-		login := "waiterlogin"
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		waiter := &entities.WaiterEntity{
-			WaiterID: "789",
-			Password: hashedPassword,
-		}
+		identity := newIdentity("id-789", "waiterlogin", "waiterpass123")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "waiterlogin").Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(&entities.IdentityRoleEntity{EntityID: "789"}, nil)
+		mockWaiterRepo.On("GetWaiterByID", ctx, "789").Return(&entities.WaiterEntity{WaiterID: "789"}, nil)
+		mockTokensRepo.On("SignAccessToken", "789", constants.RoleWaiter, mock.Anything).Return("waiter-access-token", nil)
+		mockTokensRepo.On("GenerateRefreshToken", ctx, "789", constants.RoleWaiter).Return("waiter-refresh-token", nil)
 
-		mockWaiterRepo.On("GetWaiterByLogin", ctx, login).Return(waiter, nil)
-		mockTokensRepo.On("SignAccessToken", waiter.WaiterID, constants.RoleWaiter).Return("waiter-access-token", nil)
-		mockTokensRepo.On("GenerateRefreshToken", ctx, waiter.WaiterID, constants.RoleWaiter).Return("waiter-refresh-token", nil)
-
-		loginDTO := &dto.LoginEmployeeDTO{Login: login, Password: password}
-
-		tokens, err := usecase.LoginWaiter(ctx, loginDTO)
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "waiterlogin", Password: "waiterpass123"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, "waiter-access-token", tokens.AccessToken)
@@ -168,40 +234,99 @@ func TestAuthUsecase_LoginWaiter(t *testing.T) {
 	})
 
 	t.Run("waiter not found", func(t *testing.T) {
-		login := "unknownwaiter"
-		mockWaiterRepo.On("GetWaiterByLogin", ctx, login).Return(nil, errs.ErrWaiterNotFound)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "unknownwaiter").Return(nil, errs.ErrIdentityNotFound)
 
-		loginDTO := &dto.LoginEmployeeDTO{Login: login, Password: "password"}
-		tokens, err := usecase.LoginWaiter(ctx, loginDTO)
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "unknownwaiter", Password: "password"})
 
 		assert.Nil(t, tokens)
 		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
-		mockWaiterRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
 	})
 
 	t.Run("invalid password", func(t *testing.T) {
-		login := "waiterlogin"
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpass"), bcrypt.DefaultCost)
-		waiter := &entities.WaiterEntity{
-			WaiterID: "789",
-			Password: hashedPassword,
-		}
-		mockWaiterRepo.On("GetWaiterByLogin", ctx, login).Return(waiter, nil)
+		identity := newIdentity("id-788", "wrongwaiter", "correctpass")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "wrongwaiter").Return(identity, nil)
 
-		loginDTO := &dto.LoginEmployeeDTO{Login: login, Password: "wrongpass"}
-		tokens, err := usecase.LoginWaiter(ctx, loginDTO)
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "wrongwaiter", Password: "wrongpass"})
 
 		assert.Nil(t, tokens)
 		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
-		mockWaiterRepo.AssertExpectations(t)
 	})
+
+	t.Run("fired waiter", func(t *testing.T) {
+		firedAt := time.Now()
+		identity := newIdentity("id-790", "firedwaiter", "password123")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "firedwaiter").Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(&entities.IdentityRoleEntity{EntityID: "790"}, nil)
+		mockWaiterRepo.On("GetWaiterByID", ctx, "790").Return(&entities.WaiterEntity{WaiterID: "790", FiredAt: &firedAt}, nil)
+
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "firedwaiter", Password: "password123"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrWaiterFired)
+		mockTokensRepo.AssertNotCalled(t, "SignAccessToken", "790", constants.RoleWaiter, mock.Anything)
+	})
+}
+
+func TestAuthUsecase_LoginMultipleRoles(t *testing.T) {
+	ctx := context.Background()
+	mockIdentityRepo := new(mockIdentityRepo)
+	mockWaiterRepo := new(mockWaiterAuthRepo)
+	mockTokensRepo := new(mockTokensRepo)
+	mockMFARepo := new(mockMFARepo)
+	mockMFARepo.On("IsMFAEnabled", mock.Anything, mock.Anything).Return(false, nil)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockIdentityRepo, nil, mockWaiterRepo, mockTokensRepo, rolePermissions(), mockMFARepo, permissiveAttempts(ctx), testHasher)
+
+	identity := newIdentity("id-1", "manager", "password123")
+	mockIdentityRepo.On("GetIdentityByLogin", ctx, "manager").Return(identity, nil)
+	mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleAdmin).Return(&entities.IdentityRoleEntity{EntityID: "admin-1"}, nil)
+	mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(&entities.IdentityRoleEntity{EntityID: "waiter-1"}, nil)
+	mockWaiterRepo.On("GetWaiterByID", ctx, "waiter-1").Return(&entities.WaiterEntity{WaiterID: "waiter-1"}, nil)
+	mockTokensRepo.On("SignAccessToken", mock.Anything, mock.Anything, mock.Anything).Return("access-token", nil)
+	mockTokensRepo.On("GenerateRefreshToken", ctx, mock.Anything, mock.Anything).Return("refresh-token", nil)
+
+	_, err := usecase.Login(ctx, &dto.LoginDTO{Login: "manager", Password: "password123", Role: constants.RoleAdmin})
+	assert.NoError(t, err)
+	_, err = usecase.Login(ctx, &dto.LoginDTO{Login: "manager", Password: "password123", Role: constants.RoleWaiter})
+	assert.NoError(t, err)
+
+	mockTokensRepo.AssertCalled(t, "SignAccessToken", "admin-1", constants.RoleAdmin, []string{constants.PermissionStaffManage, constants.PermissionPermissionsManage})
+	mockTokensRepo.AssertCalled(t, "SignAccessToken", "waiter-1", constants.RoleWaiter, []string{constants.PermissionReservationsClose})
+}
+
+func TestAuthUsecase_LoginRehash(t *testing.T) {
+	ctx := context.Background()
+	mockIdentityRepo := new(mockIdentityRepo)
+	mockTokensRepo := new(mockTokensRepo)
+	mockMFARepo := new(mockMFARepo)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockIdentityRepo, nil, nil, mockTokensRepo, rolePermissions(), mockMFARepo, permissiveAttempts(ctx), testHasher)
+
+	legacyHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	identity := &entities.IdentityEntity{IdentityID: "id-1", Login: "legacy", Password: legacyHash}
+
+	mockIdentityRepo.On("GetIdentityByLogin", ctx, "legacy").Return(identity, nil)
+	mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleAdmin).Return(&entities.IdentityRoleEntity{EntityID: "admin-1"}, nil)
+	mockIdentityRepo.On("UpdatePassword", ctx, identity.IdentityID, mock.MatchedBy(func(hash []byte) bool {
+		return !testHasher.NeedsRehash(hash) && testHasher.Compare(hash, "password123") == nil
+	})).Return(nil)
+	mockMFARepo.On("IsMFAEnabled", ctx, "admin-1").Return(false, nil)
+	mockTokensRepo.On("SignAccessToken", "admin-1", constants.RoleAdmin, mock.Anything).Return("access-token", nil)
+	mockTokensRepo.On("GenerateRefreshToken", ctx, "admin-1", constants.RoleAdmin).Return("refresh-token", nil)
+
+	tokens, err := usecase.LoginAdmin(ctx, &dto.LoginEmployeeDTO{Login: "legacy", Password: "password123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", tokens.AccessToken)
+	mockIdentityRepo.AssertExpectations(t)
 }
 
 func TestAuthUsecase_Refresh(t *testing.T) {
 	ctx := context.Background()
 	mockTokensRepo := new(mockTokensRepo)
+	mockWaiterRepo := new(mockWaiterAuthRepo)
+	mockCustomerRepo := new(mockCustomerAuthRepo)
 
-	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, nil, nil, mockTokensRepo)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, mockCustomerRepo, mockWaiterRepo, mockTokensRepo, rolePermissions(), nil, nil, nil)
 
 	t.Run("success", func(t *testing.T) {
 		refreshPayload := &payload.JwtPayload{
@@ -210,7 +335,8 @@ func TestAuthUsecase_Refresh(t *testing.T) {
 		}
 
 		mockTokensRepo.On("VerifyRefreshToken", ctx, "valid-token").Return(refreshPayload, nil)
-		mockTokensRepo.On("SignAccessToken", refreshPayload.EntityID, constants.RoleCustomer).Return("new-access-token", nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "123").Return(&entities.CustomerEntity{CustomerID: "123"}, nil)
+		mockTokensRepo.On("SignAccessToken", refreshPayload.EntityID, constants.RoleCustomer, mock.Anything).Return("new-access-token", nil)
 
 		newAccessToken, err := usecase.Refresh(ctx, "valid-token")
 
@@ -228,6 +354,29 @@ func TestAuthUsecase_Refresh(t *testing.T) {
 		assert.ErrorIs(t, err, errs.ErrInvalidJwtToken)
 		mockTokensRepo.AssertExpectations(t)
 	})
+
+	t.Run("fired waiter", func(t *testing.T) {
+		firedAt := time.Now()
+		mockTokensRepo.On("VerifyRefreshToken", ctx, "waiter-token").Return(&payload.JwtPayload{EntityID: "456", Role: constants.RoleWaiter}, nil)
+		mockWaiterRepo.On("GetWaiterByID", ctx, "456").Return(&entities.WaiterEntity{WaiterID: "456", FiredAt: &firedAt}, nil)
+
+		newAccessToken, err := usecase.Refresh(ctx, "waiter-token")
+
+		assert.Empty(t, newAccessToken)
+		assert.ErrorIs(t, err, errs.ErrWaiterFired)
+		mockTokensRepo.AssertNotCalled(t, "SignAccessToken", "456", constants.RoleWaiter, mock.Anything)
+	})
+	t.Run("blocked customer", func(t *testing.T) {
+		blockedAt := time.Now()
+		mockTokensRepo.On("VerifyRefreshToken", ctx, "blocked-token").Return(&payload.JwtPayload{EntityID: "654", Role: constants.RoleCustomer}, nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "654").Return(&entities.CustomerEntity{CustomerID: "654", BlockedAt: &blockedAt}, nil)
+
+		newAccessToken, err := usecase.Refresh(ctx, "blocked-token")
+
+		assert.Empty(t, newAccessToken)
+		assert.ErrorIs(t, err, errs.ErrCustomerBlocked)
+		mockTokensRepo.AssertNotCalled(t, "SignAccessToken", "654", constants.RoleCustomer, mock.Anything)
+	})
 }
 
 func TestAuthUsecase_Logout(t *testing.T) {
@@ -236,10 +385,182 @@ func TestAuthUsecase_Logout(t *testing.T) {
 
 	mockTokensRepo.On("RevokeRefreshToken", ctx, "valid-token").Return(nil)
 
-	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, nil, nil, mockTokensRepo)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, nil, nil, mockTokensRepo, rolePermissions(), nil, nil, nil)
 
 	err := usecase.Logout(ctx, "valid-token")
 
 	assert.NoError(t, err)
 	mockTokensRepo.AssertExpectations(t)
 }
+
+func TestAuthUsecase_Introspect(t *testing.T) {
+	ctx := context.Background()
+	mockWaiterRepo := new(mockWaiterAuthRepo)
+	mockCustomerRepo := new(mockCustomerAuthRepo)
+	mockTokensRepo := new(mockTokensRepo)
+
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), nil, mockCustomerRepo, mockWaiterRepo, mockTokensRepo, rolePermissions(), nil, nil, nil)
+
+	t.Run("active customer", func(t *testing.T) {
+		exp := time.Now().Add(time.Hour)
+		mockTokensRepo.On("VerifyAccessToken", "customer-token").Return(&payload.JwtPayload{
+			EntityID:    "123",
+			Role:        constants.RoleCustomer,
+			Permissions: []string{constants.PermissionReservationsCreate},
+			ExpiresAt:   exp,
+		}, nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "123").Return(&entities.CustomerEntity{CustomerID: "123"}, nil)
+
+		result, err := usecase.Introspect(ctx, "customer-token")
+
+		assert.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.Equal(t, "123", result.EntityID)
+		assert.Equal(t, constants.RoleCustomer, result.Role)
+		assert.Equal(t, exp, result.ExpiresAt)
+		assert.Equal(t, []string{constants.PermissionReservationsCreate}, result.Permissions)
+		assert.False(t, result.Fired)
+		mockTokensRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		mockTokensRepo.On("VerifyAccessToken", "invalid-token").Return(nil, errs.ErrInvalidJwtToken)
+
+		result, err := usecase.Introspect(ctx, "invalid-token")
+
+		assert.NoError(t, err)
+		assert.False(t, result.Active)
+		mockTokensRepo.AssertExpectations(t)
+	})
+
+	t.Run("fired waiter", func(t *testing.T) {
+		firedAt := time.Now()
+		mockTokensRepo.On("VerifyAccessToken", "waiter-token").Return(&payload.JwtPayload{
+			EntityID: "789",
+			Role:     constants.RoleWaiter,
+		}, nil)
+		mockWaiterRepo.On("GetWaiterByID", ctx, "789").Return(&entities.WaiterEntity{WaiterID: "789", FiredAt: &firedAt}, nil)
+
+		result, err := usecase.Introspect(ctx, "waiter-token")
+
+		assert.NoError(t, err)
+		assert.False(t, result.Active)
+		assert.True(t, result.Fired)
+		mockWaiterRepo.AssertExpectations(t)
+	})
+	t.Run("blocked customer", func(t *testing.T) {
+		blockedAt := time.Now()
+		mockTokensRepo.On("VerifyAccessToken", "blocked-token").Return(&payload.JwtPayload{
+			EntityID: "654",
+			Role:     constants.RoleCustomer,
+		}, nil)
+		mockCustomerRepo.On("GetCustomerByID", ctx, "654").Return(&entities.CustomerEntity{CustomerID: "654", BlockedAt: &blockedAt}, nil)
+
+		result, err := usecase.Introspect(ctx, "blocked-token")
+
+		assert.NoError(t, err)
+		assert.False(t, result.Active)
+		assert.True(t, result.Blocked)
+		mockCustomerRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUsecase_LoginLockout(t *testing.T) {
+	ctx := context.Background()
+	mockIdentityRepo := new(mockIdentityRepo)
+	mockAttemptsRepo := new(mockAttemptsRepo)
+	mockWaiterRepo := new(mockWaiterAuthRepo)
+	mockTokensRepo := new(mockTokensRepo)
+	mockMFARepo := new(mockMFARepo)
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), mockIdentityRepo, nil, mockWaiterRepo, mockTokensRepo, rolePermissions(), mockMFARepo, mockAttemptsRepo, testHasher)
+
+	cleanup := func() {
+		mockIdentityRepo.ExpectedCalls = nil
+		mockIdentityRepo.Calls = nil
+		mockAttemptsRepo.ExpectedCalls = nil
+		mockAttemptsRepo.Calls = nil
+	}
+
+	t.Run("locked account", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "waiter").Return(newIdentity("id-456", "waiter", "password123"), nil)
+		mockAttemptsRepo.On("RegisterAttempt", ctx, constants.RoleWaiter, "waiter", "10.0.0.1").Return(time.Minute, nil)
+
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "waiter", Password: "password123", IP: "10.0.0.1"})
+
+		var lockErr *errs.TooManyAttemptsError
+		assert.Nil(t, tokens)
+		assert.ErrorAs(t, err, &lockErr)
+		assert.Equal(t, time.Minute, lockErr.RetryAfter)
+		mockIdentityRepo.AssertNotCalled(t, "GetRole", ctx, "id-456", constants.RoleWaiter)
+	})
+
+	t.Run("unknown login counts against ip only", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "ghost").Return(nil, errs.ErrIdentityNotFound)
+		mockAttemptsRepo.On("RegisterAttempt", ctx, constants.RoleWaiter, "", "10.0.0.1").Return(time.Duration(0), nil)
+
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "ghost", Password: "password123", IP: "10.0.0.1"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
+		mockAttemptsRepo.AssertExpectations(t)
+	})
+
+	t.Run("success resets login only", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identity := newIdentity("id-456", "waiter", "password123")
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "waiter").Return(identity, nil)
+		mockIdentityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(&entities.IdentityRoleEntity{EntityID: "789"}, nil)
+		mockWaiterRepo.On("GetWaiterByID", ctx, "789").Return(&entities.WaiterEntity{WaiterID: "789"}, nil)
+		mockMFARepo.On("IsMFAEnabled", ctx, "789").Return(false, nil)
+		mockTokensRepo.On("SignAccessToken", "789", constants.RoleWaiter, mock.Anything).Return("access-token", nil)
+		mockTokensRepo.On("GenerateRefreshToken", ctx, "789", constants.RoleWaiter).Return("refresh-token", nil)
+		mockAttemptsRepo.On("RegisterAttempt", ctx, constants.RoleWaiter, "waiter", "10.0.0.1").Return(time.Duration(0), nil)
+		mockAttemptsRepo.On("ResetAttempts", ctx, constants.RoleWaiter, "waiter").Return(nil)
+
+		_, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "waiter", Password: "password123", IP: "10.0.0.1"})
+
+		assert.NoError(t, err)
+		mockAttemptsRepo.AssertExpectations(t)
+	})
+
+	t.Run("limiter unavailable", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		mockIdentityRepo.On("GetIdentityByLogin", ctx, "waiter").Return(newIdentity("id-456", "waiter", "correctpassword"), nil)
+		mockAttemptsRepo.On("RegisterAttempt", ctx, constants.RoleWaiter, "waiter", "10.0.0.1").Return(time.Duration(0), assert.AnError)
+
+		tokens, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "waiter", Password: "wrongpassword", IP: "10.0.0.1"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
+	})
+}
+
+func TestAuthUsecase_LoginKeepsIPCount(t *testing.T) {
+	ctx := context.Background()
+	identityRepo := new(mockIdentityRepo)
+	waiterRepo := new(mockWaiterAuthRepo)
+	tokensRepo := new(mockTokensRepo)
+	mfaRepo := new(mockMFARepo)
+	attempts := &countingAttempts{counts: make(map[string]int)}
+	usecase := usecase.NewAuthUsecase(NewTestLogger(), identityRepo, nil, waiterRepo, tokensRepo, rolePermissions(), mfaRepo, attempts, testHasher)
+
+	identity := newIdentity("id-456", "waiter", "password123")
+	identityRepo.On("GetIdentityByLogin", ctx, "waiter").Return(identity, nil)
+	identityRepo.On("GetIdentityByLogin", ctx, "victim").Return(newIdentity("id-789", "victim", "secret-password"), nil)
+	identityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(&entities.IdentityRoleEntity{EntityID: "789"}, nil)
+	waiterRepo.On("GetWaiterByID", ctx, "789").Return(&entities.WaiterEntity{WaiterID: "789"}, nil)
+	mfaRepo.On("IsMFAEnabled", ctx, "789").Return(false, nil)
+	tokensRepo.On("SignAccessToken", "789", constants.RoleWaiter, mock.Anything).Return("access-token", nil)
+	tokensRepo.On("GenerateRefreshToken", ctx, "789", constants.RoleWaiter).Return("refresh-token", nil)
+
+	_, err := usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "victim", Password: "guess", IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, errs.ErrInvalidCredentials)
+	_, err = usecase.LoginWaiter(ctx, &dto.LoginEmployeeDTO{Login: "waiter", Password: "password123", IP: "10.0.0.1"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, attempts.counts["ip:10.0.0.1"])
+	assert.Equal(t, 1, attempts.counts["account:waiter:victim"])
+	assert.Zero(t, attempts.counts["account:waiter:waiter"])
+}
@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomersUsecase_SearchCustomers(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerDirectoryRepo)
+	usecase := usecase.NewCustomersUsecase(NewTestLogger(), customerRepo, nil)
+
+	customers := []*entities.CustomerEntity{{CustomerID: "1"}, {CustomerID: "2"}}
+	customerRepo.On("SearchCustomers", ctx, "john", 10, 20).Return(customers, 42, nil)
+
+	page, err := usecase.SearchCustomers(ctx, &dto.SearchCustomersDTO{Query: "john", Page: 3, PageSize: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, customers, page.Customers)
+	assert.Equal(t, 42, page.Total)
+	customerRepo.AssertExpectations(t)
+}
+
+func TestCustomersUsecase_SetCustomerBlocked(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerDirectoryRepo)
+	tokens := new(mockTokensRevoker)
+	usecase := usecase.NewCustomersUsecase(NewTestLogger(), customerRepo, tokens)
+
+	cleanup := func() {
+		customerRepo.ExpectedCalls = nil
+		customerRepo.Calls = nil
+		tokens.ExpectedCalls = nil
+		tokens.Calls = nil
+	}
+
+	t.Run("block revokes sessions", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		customerRepo.On("SetCustomerBlocked", ctx, "123", true, "fraud").Return(nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "123").Return(nil)
+
+		err := usecase.SetCustomerBlocked(ctx, &dto.SetCustomerBlockedDTO{CustomerID: "123", Blocked: true, Reason: "fraud"})
+
+		assert.NoError(t, err)
+		customerRepo.AssertExpectations(t)
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("unblock", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		customerRepo.On("SetCustomerBlocked", ctx, "123", false, "").Return(nil)
+
+		err := usecase.SetCustomerBlocked(ctx, &dto.SetCustomerBlockedDTO{CustomerID: "123"})
+
+		assert.NoError(t, err)
+		tokens.AssertNotCalled(t, "RevokeAllRefreshTokens", ctx, "123")
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		customerRepo.On("SetCustomerBlocked", ctx, "404", true, "").Return(errs.ErrCustomerNotFound)
+
+		err := usecase.SetCustomerBlocked(ctx, &dto.SetCustomerBlockedDTO{CustomerID: "404", Blocked: true})
+
+		assert.ErrorIs(t, err, errs.ErrCustomerNotFound)
+		tokens.AssertNotCalled(t, "RevokeAllRefreshTokens", ctx, "404")
+	})
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInvitationUsecase_IssueInvitation(t *testing.T) {
+	ctx := context.Background()
+	invitationRepo := new(mockInvitationRepo)
+	usecase := usecase.NewInvitationUsecase(NewTestLogger(), invitationRepo, 72*time.Hour, 720*time.Hour)
+
+	t.Run("success", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		invitationRepo.On("CreateInvitation", ctx, mock.MatchedBy(func(dto *dto.CreateInvitationDTO) bool {
+			return dto.Role == constants.RoleWaiter &&
+				dto.CreatedBy == "admin-1" &&
+				*dto.FirstName == "Jane" &&
+				dto.LastName == nil &&
+				time.Until(dto.ExpiresAt) <= time.Hour
+		})).Return(&entities.InvitationEntity{InvitationID: "inv-1", ExpiresAt: expiresAt}, nil)
+
+		invitation, err := usecase.IssueInvitation(ctx, "admin-1", constants.RoleAdmin, &dto.IssueInvitationDTO{
+			Role:      constants.RoleWaiter,
+			FirstName: "Jane",
+			TTL:       time.Hour,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "inv-1", invitation.InvitationID)
+		assert.NotEmpty(t, invitation.Code)
+		invitationRepo.AssertCalled(t, "CreateInvitation", ctx, mock.MatchedBy(func(dto *dto.CreateInvitationDTO) bool {
+			return assert.ObjectsAreEqual(dto.CodeHash, hashInvitationCode(invitation.Code))
+		}))
+	})
+
+	t.Run("ttl too long", func(t *testing.T) {
+		invitation, err := usecase.IssueInvitation(ctx, "admin-1", constants.RoleAdmin, &dto.IssueInvitationDTO{
+			Role: constants.RoleWaiter,
+			TTL:  721 * time.Hour,
+		})
+
+		assert.Nil(t, invitation)
+		assert.ErrorIs(t, err, errs.ErrInvitationTTLTooLong)
+	})
+
+	t.Run("admin invited by non admin", func(t *testing.T) {
+		invitation, err := usecase.IssueInvitation(ctx, "waiter-1", constants.RoleWaiter, &dto.IssueInvitationDTO{
+			Role: constants.RoleAdmin,
+		})
+
+		assert.Nil(t, invitation)
+		assert.ErrorIs(t, err, errs.ErrInvitationNotAllowed)
+	})
+
+	t.Run("issuer is not an admin", func(t *testing.T) {
+		invitationRepo.On("CreateInvitation", ctx, mock.MatchedBy(func(dto *dto.CreateInvitationDTO) bool {
+			return dto.CreatedBy == "waiter-1"
+		})).Return(nil, errs.ErrInvitationNotAllowed)
+
+		invitation, err := usecase.IssueInvitation(ctx, "waiter-1", constants.RoleWaiter, &dto.IssueInvitationDTO{
+			Role: constants.RoleWaiter,
+		})
+
+		assert.Nil(t, invitation)
+		assert.ErrorIs(t, err, errs.ErrInvitationNotAllowed)
+	})
+}
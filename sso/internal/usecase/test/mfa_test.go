@@ -0,0 +1,217 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/SergeyBogomolovv/restaurant/sso/pkg/payload"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMFAUsecase_ConfirmMFA(t *testing.T) {
+	ctx := context.Background()
+	mfaRepo := new(mockMFARepo)
+	attemptsRepo := new(mockAttemptsRepo)
+	usecase := usecase.NewMFAUsecase(NewTestLogger(), mfaRepo, nil, nil, nil, attemptsRepo, nil, "restaurant")
+
+	key, _ := totp.Generate(totp.GenerateOpts{Issuer: "restaurant", AccountName: "test@example.com"})
+
+	cleanup := func() {
+		mfaRepo.ExpectedCalls = nil
+		mfaRepo.Calls = nil
+		attemptsRepo.ExpectedCalls = nil
+		attemptsRepo.Calls = nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		mfaRepo.On("GetMFA", ctx, "123").Return(&entities.MFAEntity{EntityID: "123", Secret: key.Secret()}, nil)
+		attemptsRepo.On("RegisterAttempt", ctx, "mfa", "123", "").Return(time.Duration(0), nil)
+		mfaRepo.On("UseTOTPStep", ctx, "123", mock.Anything).Return(true, nil)
+		attemptsRepo.On("ResetAttempts", ctx, "mfa", "123").Return(nil)
+		mfaRepo.On("ConfirmMFA", ctx, "123", mock.Anything).Return(nil)
+
+		code, _ := totp.GenerateCode(key.Secret(), time.Now())
+		recoveryCodes, err := usecase.ConfirmMFA(ctx, "123", code)
+
+		assert.NoError(t, err)
+		assert.Len(t, recoveryCodes, 10)
+		mfaRepo.AssertExpectations(t)
+		attemptsRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		mfaRepo.On("GetMFA", ctx, "123").Return(&entities.MFAEntity{EntityID: "123", Secret: key.Secret()}, nil)
+		attemptsRepo.On("RegisterAttempt", ctx, "mfa", "123", "").Return(time.Duration(0), nil)
+
+		recoveryCodes, err := usecase.ConfirmMFA(ctx, "123", "000000")
+
+		assert.Nil(t, recoveryCodes)
+		assert.ErrorIs(t, err, errs.ErrInvalidMFACode)
+		mfaRepo.AssertNotCalled(t, "ConfirmMFA", ctx, "123", mock.Anything)
+		attemptsRepo.AssertNotCalled(t, "ResetAttempts", ctx, "mfa", "123")
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		mfaRepo.On("GetMFA", ctx, "123").Return(&entities.MFAEntity{EntityID: "123", Secret: key.Secret()}, nil)
+		attemptsRepo.On("RegisterAttempt", ctx, "mfa", "123", "").Return(time.Minute, nil)
+
+		code, _ := totp.GenerateCode(key.Secret(), time.Now())
+		recoveryCodes, err := usecase.ConfirmMFA(ctx, "123", code)
+
+		assert.Nil(t, recoveryCodes)
+		assert.ErrorIs(t, err, errs.ErrTooManyAttempts)
+		mfaRepo.AssertNotCalled(t, "UseTOTPStep", ctx, "123", mock.Anything)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		confirmedAt := time.Now()
+		mfaRepo.On("GetMFA", ctx, "123").Return(&entities.MFAEntity{EntityID: "123", Secret: key.Secret(), ConfirmedAt: &confirmedAt}, nil)
+
+		code, _ := totp.GenerateCode(key.Secret(), time.Now())
+		recoveryCodes, err := usecase.ConfirmMFA(ctx, "123", code)
+
+		assert.Nil(t, recoveryCodes)
+		assert.ErrorIs(t, err, errs.ErrMFAAlreadyEnabled)
+	})
+}
+
+func TestMFAUsecase_VerifyMFA(t *testing.T) {
+	ctx := context.Background()
+	mfaRepo := new(mockMFARepo)
+	tokensRepo := new(mockTokensRepo)
+	statusChecker := new(mockAccountStatusChecker)
+	recoveryCodeHash := usecase.HashRecoveryCode("ABCDEFGH")
+	usecase := usecase.NewMFAUsecase(NewTestLogger(), mfaRepo, tokensRepo, rolePermissions(), nil, nil, statusChecker, "restaurant")
+
+	key, _ := totp.Generate(totp.GenerateOpts{Issuer: "restaurant", AccountName: "admin"})
+	confirmedAt := time.Now()
+	mfa := &entities.MFAEntity{EntityID: "456", Secret: key.Secret(), ConfirmedAt: &confirmedAt}
+	challenge := &payload.JwtPayload{EntityID: "456", Role: constants.RoleAdmin}
+
+	cleanup := func() {
+		mfaRepo.ExpectedCalls = nil
+		mfaRepo.Calls = nil
+		tokensRepo.ExpectedCalls = nil
+		tokensRepo.Calls = nil
+		statusChecker.ExpectedCalls = nil
+		statusChecker.Calls = nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "mfa-token").Return(challenge, nil)
+		mfaRepo.On("GetMFA", ctx, "456").Return(mfa, nil)
+		mfaRepo.On("UseTOTPStep", ctx, "456", mock.Anything).Return(true, nil)
+		statusChecker.On("CheckStatus", ctx, constants.RoleAdmin, "456").Return(nil)
+		tokensRepo.On("ConsumeMFAToken", ctx, "mfa-token").Return(nil)
+		tokensRepo.On("SignAccessToken", "456", constants.RoleAdmin, mock.Anything).Return("access-token", nil)
+		tokensRepo.On("GenerateRefreshToken", ctx, "456", constants.RoleAdmin).Return("refresh-token", nil)
+
+		code, _ := totp.GenerateCode(key.Secret(), time.Now())
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "mfa-token", Code: code})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", tokens.AccessToken)
+		assert.Equal(t, "refresh-token", tokens.RefreshToken)
+		tokensRepo.AssertExpectations(t)
+		mfaRepo.AssertExpectations(t)
+	})
+
+	t.Run("replayed code", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "mfa-token").Return(challenge, nil)
+		mfaRepo.On("GetMFA", ctx, "456").Return(mfa, nil)
+		mfaRepo.On("UseTOTPStep", ctx, "456", mock.Anything).Return(false, nil)
+		tokensRepo.On("FailMFAToken", ctx, "mfa-token").Return(nil)
+
+		code, _ := totp.GenerateCode(key.Secret(), time.Now())
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "mfa-token", Code: code})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidMFACode)
+		tokensRepo.AssertNotCalled(t, "ConsumeMFAToken", ctx, "mfa-token")
+	})
+
+	t.Run("token already used", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "mfa-token").Return(challenge, nil)
+		mfaRepo.On("GetMFA", ctx, "456").Return(mfa, nil)
+		mfaRepo.On("UseRecoveryCode", ctx, "456", mock.Anything).Return(true, nil)
+		statusChecker.On("CheckStatus", ctx, constants.RoleAdmin, "456").Return(nil)
+		tokensRepo.On("ConsumeMFAToken", ctx, "mfa-token").Return(errs.ErrInvalidMFAToken)
+
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "mfa-token", Code: "abcd-efgh"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidMFAToken)
+		tokensRepo.AssertNotCalled(t, "SignAccessToken", "456", constants.RoleAdmin, mock.Anything)
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "mfa-token").Return(challenge, nil)
+		mfaRepo.On("GetMFA", ctx, "456").Return(mfa, nil)
+		mfaRepo.On("UseRecoveryCode", ctx, "456", mock.Anything).Return(true, nil)
+		statusChecker.On("CheckStatus", ctx, constants.RoleAdmin, "456").Return(nil)
+		tokensRepo.On("ConsumeMFAToken", ctx, "mfa-token").Return(nil)
+		tokensRepo.On("SignAccessToken", "456", constants.RoleAdmin, mock.Anything).Return("access-token", nil)
+		tokensRepo.On("GenerateRefreshToken", ctx, "456", constants.RoleAdmin).Return("refresh-token", nil)
+
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "mfa-token", Code: "abcd-efgh"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", tokens.AccessToken)
+		mfaRepo.AssertCalled(t, "UseRecoveryCode", ctx, "456", recoveryCodeHash)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "mfa-token").Return(challenge, nil)
+		mfaRepo.On("GetMFA", ctx, "456").Return(mfa, nil)
+		mfaRepo.On("UseRecoveryCode", ctx, "456", mock.Anything).Return(false, nil)
+		tokensRepo.On("FailMFAToken", ctx, "mfa-token").Return(nil)
+
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "mfa-token", Code: "000000"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidMFACode)
+		tokensRepo.AssertExpectations(t)
+		tokensRepo.AssertNotCalled(t, "SignAccessToken", "456", constants.RoleAdmin, mock.Anything)
+	})
+
+	t.Run("account disabled", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "mfa-token").Return(challenge, nil)
+		mfaRepo.On("GetMFA", ctx, "456").Return(mfa, nil)
+		mfaRepo.On("UseRecoveryCode", ctx, "456", mock.Anything).Return(true, nil)
+		statusChecker.On("CheckStatus", ctx, constants.RoleAdmin, "456").Return(errs.ErrCustomerBlocked)
+
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "mfa-token", Code: "abcd-efgh"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrCustomerBlocked)
+		tokensRepo.AssertNotCalled(t, "SignAccessToken", "456", constants.RoleAdmin, mock.Anything)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		tokensRepo.On("VerifyMFAToken", ctx, "expired-token").Return(nil, errs.ErrInvalidMFAToken)
+
+		tokens, err := usecase.VerifyMFA(ctx, &dto.VerifyMFADTO{Token: "expired-token", Code: "123456"})
+
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, errs.ErrInvalidMFAToken)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"time"
 
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
@@ -16,36 +17,75 @@ func NewTestLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
 
-type mockCustomerAuthRepo struct {
+type mockIdentityRepo struct {
 	mock.Mock
 }
 
-func (m *mockCustomerAuthRepo) GetCustomerByEmail(ctx context.Context, email string) (*entities.CustomerEntity, error) {
-	args := m.Called(ctx, email)
+func (m *mockIdentityRepo) GetIdentityByLogin(ctx context.Context, login string) (*entities.IdentityEntity, error) {
+	args := m.Called(ctx, login)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
+	return args.Get(0).(*entities.IdentityEntity), args.Error(1)
+}
+
+func (m *mockIdentityRepo) GetIdentityByEntity(ctx context.Context, role string, entityID string) (*entities.IdentityEntity, error) {
+	args := m.Called(ctx, role, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.IdentityEntity), args.Error(1)
+}
+
+func (m *mockIdentityRepo) CreateIdentity(ctx context.Context, login string, password []byte) (string, error) {
+	args := m.Called(ctx, login, password)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockIdentityRepo) DeleteIdentity(ctx context.Context, identityID string) error {
+	args := m.Called(ctx, identityID)
+	return args.Error(0)
+}
+
+func (m *mockIdentityRepo) UpdatePassword(ctx context.Context, identityID string, password []byte) error {
+	args := m.Called(ctx, identityID, password)
+	return args.Error(0)
+}
+
+func (m *mockIdentityRepo) GetRole(ctx context.Context, identityID string, role string) (*entities.IdentityRoleEntity, error) {
+	args := m.Called(ctx, identityID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.IdentityRoleEntity), args.Error(1)
 }
 
-type mockAdminAuthRepo struct {
+func (m *mockIdentityRepo) ListRoles(ctx context.Context, identityID string) ([]*entities.IdentityRoleEntity, error) {
+	args := m.Called(ctx, identityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.IdentityRoleEntity), args.Error(1)
+}
+
+type mockCustomerAuthRepo struct {
 	mock.Mock
 }
 
-func (m *mockAdminAuthRepo) GetAdminByLogin(ctx context.Context, login string) (*entities.AdminEntity, error) {
-	args := m.Called(ctx, login)
+func (m *mockCustomerAuthRepo) GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, customerID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entities.AdminEntity), args.Error(1)
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
 }
 
 type mockWaiterAuthRepo struct {
 	mock.Mock
 }
 
-func (m *mockWaiterAuthRepo) GetWaiterByLogin(ctx context.Context, login string) (*entities.WaiterEntity, error) {
-	args := m.Called(ctx, login)
+func (m *mockWaiterAuthRepo) GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error) {
+	args := m.Called(ctx, waiterID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -73,20 +113,83 @@ func (m *mockTokensRepo) RevokeRefreshToken(ctx context.Context, token string) e
 	return m.Called(ctx, token).Error(0)
 }
 
-func (m *mockTokensRepo) SignAccessToken(entityID string, role string) (string, error) {
-	args := m.Called(entityID, role)
+func (m *mockTokensRepo) SignAccessToken(entityID string, role string, permissions []string) (string, error) {
+	args := m.Called(entityID, role, permissions)
 	return args.String(0), args.Error(1)
 }
 
-type mockCustomerRegisterRepo struct {
+func (m *mockTokensRepo) VerifyAccessToken(token string) (*payload.JwtPayload, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payload.JwtPayload), args.Error(1)
+}
+
+func (m *mockTokensRepo) GenerateMFAToken(ctx context.Context, entityID string, role string) (string, error) {
+	args := m.Called(ctx, entityID, role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockTokensRepo) VerifyMFAToken(ctx context.Context, token string) (*payload.JwtPayload, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payload.JwtPayload), args.Error(1)
+}
+
+func (m *mockTokensRepo) FailMFAToken(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+func (m *mockTokensRepo) ConsumeMFAToken(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+type mockMFARepo struct {
 	mock.Mock
 }
 
-func (m *mockCustomerRegisterRepo) CheckEmailExists(ctx context.Context, email string) (bool, error) {
-	args := m.Called(ctx, email)
+func (m *mockMFARepo) IsMFAEnabled(ctx context.Context, entityID string) (bool, error) {
+	args := m.Called(ctx, entityID)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *mockMFARepo) GetMFA(ctx context.Context, entityID string) (*entities.MFAEntity, error) {
+	args := m.Called(ctx, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.MFAEntity), args.Error(1)
+}
+
+func (m *mockMFARepo) SaveMFASecret(ctx context.Context, entityID, role, secret string) error {
+	return m.Called(ctx, entityID, role, secret).Error(0)
+}
+
+func (m *mockMFARepo) ConfirmMFA(ctx context.Context, entityID string, recoveryCodeHashes [][]byte) error {
+	return m.Called(ctx, entityID, recoveryCodeHashes).Error(0)
+}
+
+func (m *mockMFARepo) UseRecoveryCode(ctx context.Context, entityID string, codeHash []byte) (bool, error) {
+	args := m.Called(ctx, entityID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockMFARepo) UseTOTPStep(ctx context.Context, entityID string, step int64) (bool, error) {
+	args := m.Called(ctx, entityID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockMFARepo) DeleteMFA(ctx context.Context, entityID string) error {
+	return m.Called(ctx, entityID).Error(0)
+}
+
+type mockCustomerRegisterRepo struct {
+	mock.Mock
+}
+
 func (m *mockCustomerRegisterRepo) CreateCustomer(ctx context.Context, dto *dto.CreateCustomerDTO) (uuid.UUID, error) {
 	args := m.Called(ctx, dto)
 	return args.Get(0).(uuid.UUID), args.Error(1)
@@ -96,12 +199,12 @@ type mockAdminRegisterRepo struct {
 	mock.Mock
 }
 
-func (m *mockAdminRegisterRepo) CheckLoginExists(ctx context.Context, login string) (bool, error) {
-	args := m.Called(ctx, login)
-	return args.Bool(0), args.Error(1)
+func (m *mockAdminRegisterRepo) CreateAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error) {
+	args := m.Called(ctx, dto)
+	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *mockAdminRegisterRepo) CreateAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error) {
+func (m *mockAdminRegisterRepo) CreateFirstAdmin(ctx context.Context, dto *dto.CreateAdminDTO) (uuid.UUID, error) {
 	args := m.Called(ctx, dto)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
@@ -110,12 +213,390 @@ type mockWaiterRegisterRepo struct {
 	mock.Mock
 }
 
-func (m *mockWaiterRegisterRepo) CheckLoginExists(ctx context.Context, login string) (bool, error) {
-	args := m.Called(ctx, login)
+func (m *mockWaiterRegisterRepo) CreateWaiter(ctx context.Context, dto *dto.CreateWaiterDTO) (uuid.UUID, error) {
+	args := m.Called(ctx, dto)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+type mockEmailVerifier struct {
+	mock.Mock
+}
+
+func (m *mockEmailVerifier) SendVerification(ctx context.Context, customerID string, email string) error {
+	return m.Called(ctx, customerID, email).Error(0)
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) Send(ctx context.Context, to, subject, body string) error {
+	return m.Called(ctx, to, subject, body).Error(0)
+}
+
+type mockVerificationRepo struct {
+	mock.Mock
+}
+
+func (m *mockVerificationRepo) GenerateVerificationToken(ctx context.Context, customerID string, email string) (string, error) {
+	args := m.Called(ctx, customerID, email)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockVerificationRepo) ConsumeVerificationToken(ctx context.Context, token string) (*entities.EmailVerificationEntity, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EmailVerificationEntity), args.Error(1)
+}
+
+func (m *mockVerificationRepo) StartResendCooldown(ctx context.Context, customerID string) (bool, error) {
+	args := m.Called(ctx, customerID)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *mockWaiterRegisterRepo) CreateWaiter(ctx context.Context, dto *dto.CreateWaiterDTO) (uuid.UUID, error) {
+type mockCustomerVerificationRepo struct {
+	mock.Mock
+}
+
+func (m *mockCustomerVerificationRepo) GetCustomerByEmail(ctx context.Context, email string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
+}
+
+func (m *mockCustomerVerificationRepo) VerifyEmail(ctx context.Context, customerID string, email string) error {
+	return m.Called(ctx, customerID, email).Error(0)
+}
+
+type mockCustomerPasswordRepo struct {
+	mock.Mock
+}
+
+func (m *mockCustomerPasswordRepo) GetCustomerByEmail(ctx context.Context, email string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
+}
+
+type mockResetRepo struct {
+	mock.Mock
+}
+
+func (m *mockResetRepo) StartResetCooldown(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockResetRepo) GenerateResetToken(ctx context.Context, customerID string) (string, error) {
+	args := m.Called(ctx, customerID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockResetRepo) GetResetToken(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockResetRepo) ConsumeResetToken(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+type mockTokensRevoker struct {
+	mock.Mock
+}
+
+func (m *mockTokensRevoker) RevokeAllRefreshTokens(ctx context.Context, entityID string) error {
+	return m.Called(ctx, entityID).Error(0)
+}
+
+type mockDeletionRepo struct {
+	mock.Mock
+}
+
+func (m *mockDeletionRepo) GenerateDeletionToken(ctx context.Context, customerID string) (string, error) {
+	args := m.Called(ctx, customerID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockDeletionRepo) ConsumeDeletionToken(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+type mockCustomerUpdateRepo struct {
+	mock.Mock
+}
+
+func (m *mockCustomerUpdateRepo) GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
+}
+
+func (m *mockCustomerUpdateRepo) CheckEmailExists(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockCustomerUpdateRepo) UpdateCustomerName(ctx context.Context, customerID string, name string) error {
+	return m.Called(ctx, customerID, name).Error(0)
+}
+
+type mockWaiterUpdateRepo struct {
+	mock.Mock
+}
+
+func (m *mockWaiterUpdateRepo) GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error) {
+	args := m.Called(ctx, waiterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.WaiterEntity), args.Error(1)
+}
+
+func (m *mockWaiterUpdateRepo) UpdateWaiterName(ctx context.Context, waiterID string, firstName string, lastName string) error {
+	return m.Called(ctx, waiterID, firstName, lastName).Error(0)
+}
+
+type mockAttemptsRepo struct {
+	mock.Mock
+}
+
+func (m *mockAttemptsRepo) RegisterAttempt(ctx context.Context, role, login, ip string) (time.Duration, error) {
+	args := m.Called(ctx, role, login, ip)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *mockAttemptsRepo) ResetAttempts(ctx context.Context, role, login string) error {
+	return m.Called(ctx, role, login).Error(0)
+}
+
+// countingAttempts keeps the counters the way the Redis limiter does, for
+// tests about which of them a login touches.
+type countingAttempts struct {
+	counts map[string]int
+}
+
+func (a *countingAttempts) RegisterAttempt(ctx context.Context, role, login, ip string) (time.Duration, error) {
+	if login != "" {
+		a.counts["account:"+role+":"+login]++
+	}
+	if ip != "" {
+		a.counts["ip:"+ip]++
+	}
+	return 0, nil
+}
+
+func (a *countingAttempts) ResetAttempts(ctx context.Context, role, login string) error {
+	delete(a.counts, "account:"+role+":"+login)
+	return nil
+}
+
+type mockInvitationConsumer struct {
+	mock.Mock
+}
+
+func (m *mockInvitationConsumer) ConsumeInvitation(ctx context.Context, codeHash []byte, role string) (*entities.InvitationEntity, error) {
+	args := m.Called(ctx, codeHash, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.InvitationEntity), args.Error(1)
+}
+
+func (m *mockInvitationConsumer) ReleaseInvitation(ctx context.Context, invitationID string) error {
+	return m.Called(ctx, invitationID).Error(0)
+}
+
+type mockInvitationRepo struct {
+	mock.Mock
+}
+
+func (m *mockInvitationRepo) CreateInvitation(ctx context.Context, dto *dto.CreateInvitationDTO) (*entities.InvitationEntity, error) {
 	args := m.Called(ctx, dto)
-	return args.Get(0).(uuid.UUID), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.InvitationEntity), args.Error(1)
+}
+
+type mockStaffRepo struct {
+	mock.Mock
+}
+
+func (m *mockStaffRepo) GetWaiterByID(ctx context.Context, waiterID string) (*entities.WaiterEntity, error) {
+	args := m.Called(ctx, waiterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.WaiterEntity), args.Error(1)
+}
+
+func (m *mockStaffRepo) ListWaiters(ctx context.Context, filter *dto.WaiterFilterDTO) ([]*entities.WaiterEntity, int, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.WaiterEntity), args.Int(1), args.Error(2)
+}
+
+func (m *mockStaffRepo) FireWaiter(ctx context.Context, waiterID string, reason string) error {
+	return m.Called(ctx, waiterID, reason).Error(0)
+}
+
+func (m *mockStaffRepo) RehireWaiter(ctx context.Context, waiterID string) error {
+	return m.Called(ctx, waiterID).Error(0)
+}
+
+type mockCustomerDirectoryRepo struct {
+	mock.Mock
+}
+
+func (m *mockCustomerDirectoryRepo) GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
+}
+
+func (m *mockCustomerDirectoryRepo) SearchCustomers(ctx context.Context, query string, limit, offset int) ([]*entities.CustomerEntity, int, error) {
+	args := m.Called(ctx, query, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.CustomerEntity), args.Int(1), args.Error(2)
+}
+
+func (m *mockCustomerDirectoryRepo) SetCustomerBlocked(ctx context.Context, customerID string, blocked bool, reason string) error {
+	args := m.Called(ctx, customerID, blocked, reason)
+	return args.Error(0)
+}
+
+type mockCustomerAccountRepo struct {
+	mock.Mock
+}
+
+func (m *mockCustomerAccountRepo) GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
+}
+
+func (m *mockCustomerAccountRepo) DeleteCustomer(ctx context.Context, customerID string) error {
+	args := m.Called(ctx, customerID)
+	return args.Error(0)
+}
+
+type mockReservationHistoryRepo struct {
+	mock.Mock
+}
+
+func (m *mockReservationHistoryRepo) GetCustomerReservations(ctx context.Context, customerID string) ([]*entities.ReservationEntity, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.ReservationEntity), args.Error(1)
+}
+
+type mockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *mockAuditRepo) RecordEvent(ctx context.Context, entityID, role, action string) error {
+	args := m.Called(ctx, entityID, role, action)
+	return args.Error(0)
+}
+
+type mockPermissionsRepo struct {
+	mock.Mock
+}
+
+func (m *mockPermissionsRepo) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockPermissionsRepo) GrantPermission(ctx context.Context, role string, permission string) error {
+	args := m.Called(ctx, role, permission)
+	return args.Error(0)
+}
+
+func (m *mockPermissionsRepo) RevokePermission(ctx context.Context, role string, permission string) error {
+	args := m.Called(ctx, role, permission)
+	return args.Error(0)
+}
+
+func (m *mockIdentityRepo) GetIdentityByExternal(ctx context.Context, provider string, subject string) (*entities.IdentityEntity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.IdentityEntity), args.Error(1)
+}
+
+func (m *mockIdentityRepo) LinkExternalIdentity(ctx context.Context, identityID string, provider string, subject string, email string) error {
+	args := m.Called(ctx, identityID, provider, subject, email)
+	return args.Error(0)
+}
+
+type mockOIDCStateRepo struct {
+	mock.Mock
+}
+
+func (m *mockOIDCStateRepo) SaveOIDCState(ctx context.Context, state string, entity *entities.OIDCStateEntity) error {
+	args := m.Called(ctx, state, entity)
+	return args.Error(0)
+}
+
+func (m *mockOIDCStateRepo) ConsumeOIDCState(ctx context.Context, state string) (*entities.OIDCStateEntity, error) {
+	args := m.Called(ctx, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OIDCStateEntity), args.Error(1)
+}
+
+type mockSessionIssuer struct {
+	mock.Mock
+}
+
+func (m *mockSessionIssuer) StartSession(ctx context.Context, entityID string, role string) (*dto.TokensDTO, error) {
+	args := m.Called(ctx, entityID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TokensDTO), args.Error(1)
+}
+
+type mockAccountStatusChecker struct {
+	mock.Mock
+}
+
+func (m *mockAccountStatusChecker) CheckStatus(ctx context.Context, role string, entityID string) error {
+	return m.Called(ctx, role, entityID).Error(0)
+}
+
+type mockCustomerOIDCRepo struct {
+	mockCustomerRegisterRepo
+}
+
+func (m *mockCustomerOIDCRepo) GetCustomerByID(ctx context.Context, customerID string) (*entities.CustomerEntity, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CustomerEntity), args.Error(1)
 }
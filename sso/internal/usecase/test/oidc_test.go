@@ -0,0 +1,330 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/config"
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/oidc"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	stubClientID     = "restaurant"
+	stubClientSecret = "secret"
+	stubCode         = "auth-code"
+)
+
+// stubProvider is a minimal OpenID Connect provider that issues an ID token
+// with the configured claims for a single authorization code.
+type stubProvider struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	kid       string
+	claims    jwt.MapClaims
+	jwksCalls atomic.Int32
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	stub := &stubProvider{key: key, kid: "stub-key"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 stub.URL,
+			"authorization_endpoint": stub.URL + "/authorize",
+			"token_endpoint":         stub.URL + "/token",
+			"jwks_uri":               stub.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		stub.jwksCalls.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "stub-key",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != stubClientID || secret != stubClientSecret || r.FormValue("code") != stubCode {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss": stub.URL,
+			"aud": stubClientID,
+			"iat": time.Now().Unix(),
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+		for k, v := range stub.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = stub.kid
+		idToken, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": idToken})
+	})
+
+	stub.Server = httptest.NewServer(mux)
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *stubProvider) config() config.OIDCProviderConfig {
+	return config.OIDCProviderConfig{
+		Name:         "stub",
+		Issuer:       s.URL,
+		ClientID:     stubClientID,
+		ClientSecret: stubClientSecret,
+		RedirectURL:  "http://localhost:3000/oidc/callback",
+	}
+}
+
+func TestOIDCUsecase_StartOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubProvider(t)
+	stateRepo := new(mockOIDCStateRepo)
+	providers := map[string]usecase.OIDCProvider{"stub": oidc.NewProvider(NewTestLogger(), stub.config(), stub.Client())}
+	usecase := usecase.NewOIDCUsecase(NewTestLogger(), providers, stateRepo, nil, nil, nil)
+
+	t.Run("success", func(t *testing.T) {
+		var saved *entities.OIDCStateEntity
+		stateRepo.On("SaveOIDCState", ctx, mock.Anything, mock.MatchedBy(func(entity *entities.OIDCStateEntity) bool {
+			saved = entity
+			return entity.Provider == "stub"
+		})).Return(nil)
+
+		auth, err := usecase.StartOIDCLogin(ctx, "stub")
+		assert.NoError(t, err)
+
+		authURL, err := url.Parse(auth.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, stub.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+		query := authURL.Query()
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, stubClientID, query.Get("client_id"))
+		assert.Equal(t, auth.State, query.Get("state"))
+		assert.Equal(t, saved.Nonce, query.Get("nonce"))
+		assert.Equal(t, "openid email profile", query.Get("scope"))
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		auth, err := usecase.StartOIDCLogin(ctx, "unknown")
+		assert.ErrorIs(t, err, errs.ErrUnknownOIDCProvider)
+		assert.Nil(t, auth)
+	})
+}
+
+func TestOIDCUsecase_CompleteOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubProvider(t)
+	stateRepo := new(mockOIDCStateRepo)
+	identityRepo := new(mockIdentityRepo)
+	customerRepo := new(mockCustomerOIDCRepo)
+	sessions := new(mockSessionIssuer)
+	providers := map[string]usecase.OIDCProvider{"stub": oidc.NewProvider(NewTestLogger(), stub.config(), stub.Client())}
+	usecase := usecase.NewOIDCUsecase(NewTestLogger(), providers, stateRepo, identityRepo, customerRepo, sessions)
+
+	state := &entities.OIDCStateEntity{Provider: "stub", Nonce: "nonce-1"}
+	tokens := &dto.TokensDTO{AccessToken: "access-token", RefreshToken: "refresh-token"}
+	verifiedAt := time.Now()
+
+	cleanup := func() {
+		stateRepo.ExpectedCalls = nil
+		stateRepo.Calls = nil
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		customerRepo.ExpectedCalls = nil
+		customerRepo.Calls = nil
+		sessions.ExpectedCalls = nil
+		sessions.Calls = nil
+	}
+
+	t.Run("new customer", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-1", "email": "Guest@Example.com", "email_verified": true, "name": "Guest", "nonce": "nonce-1"}
+		customerID := uuid.New()
+		stateRepo.On("ConsumeOIDCState", ctx, "state-1").Return(state, nil)
+		identityRepo.On("GetIdentityByExternal", ctx, "stub", "sub-1").Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("GetIdentityByLogin", ctx, "guest@example.com").Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("CreateIdentity", ctx, "guest@example.com", []byte{}).Return("id-1", nil)
+		identityRepo.On("GetRole", ctx, "id-1", constants.RoleCustomer).Return(nil, errs.ErrRoleNotGranted).Once()
+		customerRepo.On("CreateCustomer", ctx, mock.MatchedBy(func(dto *dto.CreateCustomerDTO) bool {
+			return dto.IdentityID == "id-1" && dto.Email == "guest@example.com" && dto.Name == "Guest" && dto.EmailVerified
+		})).Return(customerID, nil)
+		identityRepo.On("LinkExternalIdentity", ctx, "id-1", "stub", "sub-1", "guest@example.com").Return(nil)
+		identityRepo.On("GetRole", ctx, "id-1", constants.RoleCustomer).Return(&entities.IdentityRoleEntity{EntityID: customerID.String()}, nil)
+		sessions.On("StartSession", ctx, customerID.String(), constants.RoleCustomer).Return(tokens, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-1", Code: stubCode})
+
+		assert.NoError(t, err)
+		assert.Equal(t, tokens, result)
+		identityRepo.AssertExpectations(t)
+		customerRepo.AssertExpectations(t)
+	})
+
+	t.Run("links existing customer by email", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-2", "email": "test@example.com", "email_verified": "true", "nonce": "nonce-1"}
+		identity := &entities.IdentityEntity{IdentityID: "id-2", Login: "test@example.com"}
+		stateRepo.On("ConsumeOIDCState", ctx, "state-2").Return(state, nil)
+		identityRepo.On("GetIdentityByExternal", ctx, "stub", "sub-2").Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("GetIdentityByLogin", ctx, "test@example.com").Return(identity, nil)
+		identityRepo.On("ListRoles", ctx, "id-2").Return([]*entities.IdentityRoleEntity{{Role: constants.RoleCustomer, EntityID: "cust-2"}}, nil)
+		customerRepo.On("GetCustomerByID", ctx, "cust-2").Return(&entities.CustomerEntity{CustomerID: "cust-2", Email: "test@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		identityRepo.On("GetRole", ctx, "id-2", constants.RoleCustomer).Return(&entities.IdentityRoleEntity{EntityID: "cust-2"}, nil)
+		identityRepo.On("LinkExternalIdentity", ctx, "id-2", "stub", "sub-2", "test@example.com").Return(nil)
+		sessions.On("StartSession", ctx, "cust-2", constants.RoleCustomer).Return(tokens, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-2", Code: stubCode})
+
+		assert.NoError(t, err)
+		assert.Equal(t, tokens, result)
+		identityRepo.AssertNotCalled(t, "CreateIdentity", ctx, mock.Anything, mock.Anything)
+		customerRepo.AssertNotCalled(t, "CreateCustomer", ctx, mock.Anything)
+	})
+
+	t.Run("customer with unverified email", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-6", "email": "victim@example.com", "email_verified": true, "nonce": "nonce-1"}
+		identity := &entities.IdentityEntity{IdentityID: "id-6", Login: "victim@example.com"}
+		stateRepo.On("ConsumeOIDCState", ctx, "state-6").Return(state, nil)
+		identityRepo.On("GetIdentityByExternal", ctx, "stub", "sub-6").Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("GetIdentityByLogin", ctx, "victim@example.com").Return(identity, nil)
+		identityRepo.On("ListRoles", ctx, "id-6").Return([]*entities.IdentityRoleEntity{{Role: constants.RoleCustomer, EntityID: "cust-6"}}, nil)
+		customerRepo.On("GetCustomerByID", ctx, "cust-6").Return(&entities.CustomerEntity{CustomerID: "cust-6", Email: "victim@example.com"}, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-6", Code: stubCode})
+
+		assert.ErrorIs(t, err, errs.ErrAccountNotLinkable)
+		assert.Nil(t, result)
+		identityRepo.AssertNotCalled(t, "LinkExternalIdentity", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("staff identity", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-7", "email": "manager@example.com", "email_verified": true, "nonce": "nonce-1"}
+		identity := &entities.IdentityEntity{IdentityID: "id-7", Login: "manager@example.com"}
+		stateRepo.On("ConsumeOIDCState", ctx, "state-7").Return(state, nil)
+		identityRepo.On("GetIdentityByExternal", ctx, "stub", "sub-7").Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("GetIdentityByLogin", ctx, "manager@example.com").Return(identity, nil)
+		identityRepo.On("ListRoles", ctx, "id-7").Return([]*entities.IdentityRoleEntity{
+			{Role: constants.RoleCustomer, EntityID: "cust-7"},
+			{Role: constants.RoleAdmin, EntityID: "admin-7"},
+		}, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-7", Code: stubCode})
+
+		assert.ErrorIs(t, err, errs.ErrAccountNotLinkable)
+		assert.Nil(t, result)
+		customerRepo.AssertNotCalled(t, "CreateCustomer", ctx, mock.Anything)
+		identityRepo.AssertNotCalled(t, "LinkExternalIdentity", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already linked", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-3", "nonce": "nonce-1"}
+		stateRepo.On("ConsumeOIDCState", ctx, "state-3").Return(state, nil)
+		identityRepo.On("GetIdentityByExternal", ctx, "stub", "sub-3").Return(&entities.IdentityEntity{IdentityID: "id-3"}, nil)
+		identityRepo.On("GetRole", ctx, "id-3", constants.RoleCustomer).Return(&entities.IdentityRoleEntity{EntityID: "cust-3"}, nil)
+		sessions.On("StartSession", ctx, "cust-3", constants.RoleCustomer).Return(tokens, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-3", Code: stubCode})
+
+		assert.NoError(t, err)
+		assert.Equal(t, tokens, result)
+		identityRepo.AssertNotCalled(t, "LinkExternalIdentity", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-4", "email": "guest@example.com", "email_verified": false, "nonce": "nonce-1"}
+		stateRepo.On("ConsumeOIDCState", ctx, "state-4").Return(state, nil)
+		identityRepo.On("GetIdentityByExternal", ctx, "stub", "sub-4").Return(nil, errs.ErrIdentityNotFound)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-4", Code: stubCode})
+
+		assert.ErrorIs(t, err, errs.ErrEmailNotVerified)
+		assert.Nil(t, result)
+		identityRepo.AssertNotCalled(t, "GetIdentityByLogin", ctx, mock.Anything)
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stub.claims = jwt.MapClaims{"sub": "sub-5", "nonce": "other-nonce"}
+		stateRepo.On("ConsumeOIDCState", ctx, "state-5").Return(state, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-5", Code: stubCode})
+
+		assert.ErrorIs(t, err, errs.ErrOIDCLoginFailed)
+		assert.Nil(t, result)
+		identityRepo.AssertNotCalled(t, "GetIdentityByExternal", ctx, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stateRepo.On("ConsumeOIDCState", ctx, "state-6").Return(state, nil)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "state-6", Code: "wrong-code"})
+
+		assert.ErrorIs(t, err, errs.ErrOIDCLoginFailed)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		stateRepo.On("ConsumeOIDCState", ctx, "expired").Return(nil, errs.ErrInvalidOIDCState)
+
+		result, err := usecase.CompleteOIDCLogin(ctx, &dto.OIDCCallbackDTO{State: "expired", Code: stubCode})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidOIDCState)
+		assert.Nil(t, result)
+	})
+}
+
+func TestOIDCProvider_UnknownKeyID(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubProvider(t)
+	stub.claims = jwt.MapClaims{"sub": "sub-1", "nonce": "nonce-1"}
+	provider := oidc.NewProvider(NewTestLogger(), stub.config(), stub.Client())
+
+	stub.kid = "forged"
+	for range 3 {
+		identity, err := provider.Exchange(ctx, stubCode, "nonce-1")
+
+		assert.Nil(t, identity)
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	}
+	assert.Equal(t, int32(1), stub.jwksCalls.Load(), "unknown key ids must not refetch the keys every time")
+
+	stub.kid = "stub-key"
+	identity, err := provider.Exchange(ctx, stubCode, "nonce-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub-1", identity.Subject)
+	assert.Equal(t, int32(1), stub.jwksCalls.Load())
+}
@@ -0,0 +1,275 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/passwords"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPasswordUsecase_RequestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerPasswordRepo)
+	resetRepo := new(mockResetRepo)
+	mailer := new(mockMailer)
+	usecase := usecase.NewPasswordUsecase(NewTestLogger(), nil, customerRepo, resetRepo, nil, mailer, testHasher, testPolicy, "http://localhost/reset")
+
+	t.Run("success", func(t *testing.T) {
+		sent := make(chan struct{})
+		customer := &entities.CustomerEntity{CustomerID: "123", Email: "test@example.com"}
+		resetRepo.On("StartResetCooldown", ctx, customer.Email).Return(true, nil)
+		customerRepo.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(customer, nil)
+		resetRepo.On("GenerateResetToken", mock.Anything, customer.CustomerID).Return("reset-token", nil)
+		mailer.On("Send", mock.Anything, customer.Email, mock.Anything, mock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "http://localhost/reset?token=reset-token")
+		})).Return(nil).Run(func(mock.Arguments) { close(sent) })
+
+		err := usecase.RequestPasswordReset(ctx, customer.Email)
+
+		assert.NoError(t, err)
+		waitFor(t, sent)
+		resetRepo.AssertExpectations(t)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		looked := make(chan struct{})
+		resetRepo.On("StartResetCooldown", ctx, "unknown@example.com").Return(true, nil)
+		customerRepo.On("GetCustomerByEmail", mock.Anything, "unknown@example.com").Return(nil, errs.ErrCustomerNotFound).Run(func(mock.Arguments) { close(looked) })
+
+		err := usecase.RequestPasswordReset(ctx, "unknown@example.com")
+
+		assert.NoError(t, err)
+		waitFor(t, looked)
+		mailer.AssertNotCalled(t, "Send", mock.Anything, "unknown@example.com", mock.Anything, mock.Anything)
+	})
+
+	t.Run("mailer failure", func(t *testing.T) {
+		sent := make(chan struct{})
+		customer := &entities.CustomerEntity{CustomerID: "456", Email: "broken@example.com"}
+		resetRepo.On("StartResetCooldown", ctx, customer.Email).Return(true, nil)
+		customerRepo.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(customer, nil)
+		resetRepo.On("GenerateResetToken", mock.Anything, customer.CustomerID).Return("reset-token", nil)
+		mailer.On("Send", mock.Anything, customer.Email, mock.Anything, mock.Anything).Return(assert.AnError).Run(func(mock.Arguments) { close(sent) })
+
+		err := usecase.RequestPasswordReset(ctx, customer.Email)
+
+		assert.NoError(t, err)
+		waitFor(t, sent)
+	})
+
+	t.Run("requested recently", func(t *testing.T) {
+		resetRepo.On("StartResetCooldown", ctx, "recent@example.com").Return(false, nil)
+
+		err := usecase.RequestPasswordReset(ctx, "recent@example.com")
+
+		assert.NoError(t, err)
+		customerRepo.AssertNotCalled(t, "GetCustomerByEmail", mock.Anything, "recent@example.com")
+	})
+
+	t.Run("cooldown failure", func(t *testing.T) {
+		resetRepo.On("StartResetCooldown", ctx, "down@example.com").Return(false, assert.AnError)
+
+		err := usecase.RequestPasswordReset(ctx, "down@example.com")
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestPasswordUsecase_CloseWaitsForEmails(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerPasswordRepo)
+	resetRepo := new(mockResetRepo)
+	mailer := new(mockMailer)
+	usecase := usecase.NewPasswordUsecase(NewTestLogger(), nil, customerRepo, resetRepo, nil, mailer, testHasher, testPolicy, "http://localhost/reset")
+
+	customer := &entities.CustomerEntity{CustomerID: "123", Email: "test@example.com"}
+	resetRepo.On("StartResetCooldown", ctx, customer.Email).Return(true, nil)
+	customerRepo.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(customer, nil)
+	resetRepo.On("GenerateResetToken", mock.Anything, customer.CustomerID).Return("reset-token", nil)
+	mailer.On("Send", mock.Anything, customer.Email, mock.Anything, mock.Anything).Return(nil).
+		Run(func(mock.Arguments) { time.Sleep(50 * time.Millisecond) })
+
+	assert.NoError(t, usecase.RequestPasswordReset(ctx, customer.Email))
+	usecase.Close()
+
+	mailer.AssertExpectations(t)
+
+	assert.NoError(t, usecase.RequestPasswordReset(ctx, customer.Email))
+	mailer.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func waitFor(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the background call")
+	}
+}
+
+func TestPasswordUsecase_ResetPassword(t *testing.T) {
+	ctx := context.Background()
+	identityRepo := new(mockIdentityRepo)
+	resetRepo := new(mockResetRepo)
+	tokens := new(mockTokensRevoker)
+	usecase := usecase.NewPasswordUsecase(NewTestLogger(), identityRepo, nil, resetRepo, tokens, nil, testHasher, testPolicy, "http://localhost/reset")
+
+	cleanup := func() {
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		resetRepo.ExpectedCalls = nil
+		resetRepo.Calls = nil
+		tokens.ExpectedCalls = nil
+		tokens.Calls = nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		resetRepo.On("GetResetToken", ctx, "reset-token").Return("123", nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "123").Return(&entities.IdentityEntity{IdentityID: "id-1", Login: "test@example.com"}, nil)
+		resetRepo.On("ConsumeResetToken", ctx, "reset-token").Return("123", nil)
+		identityRepo.On("UpdatePassword", ctx, "id-1", mock.MatchedBy(func(hash []byte) bool {
+			return testHasher.Compare(hash, "newpassword") == nil
+		})).Return(nil)
+		identityRepo.On("ListRoles", ctx, "id-1").Return([]*entities.IdentityRoleEntity{{Role: constants.RoleCustomer, EntityID: "123"}}, nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "123").Return(nil)
+
+		err := usecase.ResetPassword(ctx, &dto.ResetPasswordDTO{Token: "reset-token", Password: "newpassword"})
+
+		assert.NoError(t, err)
+		resetRepo.AssertExpectations(t)
+		identityRepo.AssertExpectations(t)
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		resetRepo.On("GetResetToken", ctx, "used-token").Return("", errs.ErrInvalidResetToken)
+
+		err := usecase.ResetPassword(ctx, &dto.ResetPasswordDTO{Token: "used-token", Password: "newpassword"})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidResetToken)
+	})
+
+	t.Run("token consumed concurrently", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		resetRepo.On("GetResetToken", ctx, "reset-token").Return("123", nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "123").Return(&entities.IdentityEntity{IdentityID: "id-1", Login: "test@example.com"}, nil)
+		resetRepo.On("ConsumeResetToken", ctx, "reset-token").Return("", errs.ErrInvalidResetToken)
+
+		err := usecase.ResetPassword(ctx, &dto.ResetPasswordDTO{Token: "reset-token", Password: "newpassword"})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidResetToken)
+		identityRepo.AssertNotCalled(t, "UpdatePassword", ctx, "id-1", mock.Anything)
+	})
+
+	t.Run("weak password keeps token", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		resetRepo.On("GetResetToken", ctx, "fresh-token").Return("123", nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "123").Return(&entities.IdentityEntity{IdentityID: "id-1", Login: "test@example.com"}, nil)
+
+		err := usecase.ResetPassword(ctx, &dto.ResetPasswordDTO{Token: "fresh-token", Password: "short"})
+
+		assert.ErrorIs(t, err, errs.ErrWeakPassword)
+		resetRepo.AssertNotCalled(t, "ConsumeResetToken", ctx, "fresh-token")
+	})
+
+	t.Run("password contains login keeps token", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		resetRepo.On("GetResetToken", ctx, "fresh-token").Return("123", nil)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "123").Return(&entities.IdentityEntity{IdentityID: "id-1", Login: "waiter"}, nil)
+
+		err := usecase.ResetPassword(ctx, &dto.ResetPasswordDTO{Token: "fresh-token", Password: "mywaiter2024"})
+
+		assert.ErrorIs(t, err, errs.ErrWeakPassword)
+		resetRepo.AssertNotCalled(t, "ConsumeResetToken", ctx, "fresh-token")
+	})
+}
+
+func TestPasswordUsecase_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	identityRepo := new(mockIdentityRepo)
+	tokens := new(mockTokensRevoker)
+	usecase := usecase.NewPasswordUsecase(NewTestLogger(), identityRepo, nil, nil, tokens, nil, testHasher, testPolicy, "")
+
+	identity := newIdentity("id-1", "waiter", "oldpassword")
+
+	cleanup := func() {
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		tokens.ExpectedCalls = nil
+		tokens.Calls = nil
+	}
+
+	t.Run("success revokes sessions of every role", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleWaiter, "123").Return(identity, nil)
+		identityRepo.On("UpdatePassword", ctx, "id-1", mock.MatchedBy(func(hash []byte) bool {
+			return testHasher.Compare(hash, "newpassword") == nil
+		})).Return(nil)
+		identityRepo.On("ListRoles", ctx, "id-1").Return([]*entities.IdentityRoleEntity{
+			{Role: constants.RoleWaiter, EntityID: "123"},
+			{Role: constants.RoleAdmin, EntityID: "456"},
+		}, nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "123").Return(nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "456").Return(nil)
+
+		err := usecase.ChangePassword(ctx, "123", constants.RoleWaiter, &dto.ChangePasswordDTO{OldPassword: "oldpassword", NewPassword: "newpassword"})
+
+		assert.NoError(t, err)
+		identityRepo.AssertExpectations(t)
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("invalid old password", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleWaiter, "123").Return(identity, nil)
+
+		err := usecase.ChangePassword(ctx, "123", constants.RoleWaiter, &dto.ChangePasswordDTO{OldPassword: "wrongpassword", NewPassword: "newpassword"})
+
+		assert.ErrorIs(t, err, errs.ErrInvalidPassword)
+		identityRepo.AssertNotCalled(t, "UpdatePassword", ctx, "id-1", mock.Anything)
+	})
+
+	t.Run("password contains login", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByEntity", ctx, constants.RoleWaiter, "123").Return(identity, nil)
+
+		err := usecase.ChangePassword(ctx, "123", constants.RoleWaiter, &dto.ChangePasswordDTO{OldPassword: "oldpassword", NewPassword: "mywaiter2024"})
+
+		assert.ErrorIs(t, err, errs.ErrWeakPassword)
+		identityRepo.AssertNotCalled(t, "UpdatePassword", ctx, "id-1", mock.Anything)
+	})
+}
+
+func TestPasswordUsecase_ChangePasswordBreached(t *testing.T) {
+	ctx := context.Background()
+	listPath := filepath.Join(t.TempDir(), "breached.txt")
+	assert.NoError(t, os.WriteFile(listPath, []byte("# common passwords\nSunshine1\n"), 0o600))
+
+	cfg := testPasswordConfig
+	cfg.BreachedListPath = listPath
+	policy, err := passwords.NewPolicy(cfg)
+	assert.NoError(t, err)
+
+	identityRepo := new(mockIdentityRepo)
+	usecase := usecase.NewPasswordUsecase(NewTestLogger(), identityRepo, nil, nil, nil, nil, testHasher, policy, "")
+
+	identityRepo.On("GetIdentityByEntity", ctx, constants.RoleCustomer, "123").Return(newIdentity("id-1", "test@example.com", "oldpassword"), nil)
+
+	err = usecase.ChangePassword(ctx, "123", constants.RoleCustomer, &dto.ChangePasswordDTO{OldPassword: "oldpassword", NewPassword: "sunshine1"})
+
+	assert.ErrorIs(t, err, errs.ErrWeakPassword)
+	identityRepo.AssertNotCalled(t, "UpdatePassword", ctx, "id-1", mock.Anything)
+}
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionUsecase_ListRolePermissions(t *testing.T) {
+	ctx := context.Background()
+	permissionRepo := new(mockPermissionsRepo)
+	usecase := usecase.NewPermissionUsecase(NewTestLogger(), permissionRepo)
+
+	permissionRepo.On("GetRolePermissions", ctx, constants.RoleWaiter).Return([]string{constants.PermissionReservationsClose}, nil)
+
+	permissions, err := usecase.ListRolePermissions(ctx, constants.RoleWaiter)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{constants.PermissionReservationsClose}, permissions)
+}
+
+func TestPermissionUsecase_GrantPermission(t *testing.T) {
+	ctx := context.Background()
+	permissionRepo := new(mockPermissionsRepo)
+	usecase := usecase.NewPermissionUsecase(NewTestLogger(), permissionRepo)
+
+	t.Run("success", func(t *testing.T) {
+		permissionRepo.On("GrantPermission", ctx, constants.RoleWaiter, constants.PermissionTablesManage).Return(nil)
+
+		err := usecase.GrantPermission(ctx, &dto.RolePermissionDTO{Role: constants.RoleWaiter, Permission: constants.PermissionTablesManage})
+		assert.NoError(t, err)
+		permissionRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown permission", func(t *testing.T) {
+		permissionRepo.On("GrantPermission", ctx, constants.RoleWaiter, "tables:fly").Return(errs.ErrPermissionNotFound)
+
+		err := usecase.GrantPermission(ctx, &dto.RolePermissionDTO{Role: constants.RoleWaiter, Permission: "tables:fly"})
+		assert.ErrorIs(t, err, errs.ErrPermissionNotFound)
+	})
+}
+
+func TestPermissionUsecase_RevokePermission(t *testing.T) {
+	ctx := context.Background()
+	permissionRepo := new(mockPermissionsRepo)
+	usecase := usecase.NewPermissionUsecase(NewTestLogger(), permissionRepo)
+
+	t.Run("success", func(t *testing.T) {
+		permissionRepo.On("RevokePermission", ctx, constants.RoleAdmin, constants.PermissionAnalyticsRead).Return(nil)
+
+		err := usecase.RevokePermission(ctx, &dto.RolePermissionDTO{Role: constants.RoleAdmin, Permission: constants.PermissionAnalyticsRead})
+		assert.NoError(t, err)
+		permissionRepo.AssertExpectations(t)
+	})
+
+	t.Run("not granted", func(t *testing.T) {
+		permissionRepo.On("RevokePermission", ctx, constants.RoleCustomer, constants.PermissionTablesManage).Return(errs.ErrPermissionNotGranted)
+
+		err := usecase.RevokePermission(ctx, &dto.RolePermissionDTO{Role: constants.RoleCustomer, Permission: constants.PermissionTablesManage})
+		assert.ErrorIs(t, err, errs.ErrPermissionNotGranted)
+	})
+
+	t.Run("protected permission", func(t *testing.T) {
+		err := usecase.RevokePermission(ctx, &dto.RolePermissionDTO{Role: constants.RoleAdmin, Permission: constants.PermissionPermissionsManage})
+		assert.ErrorIs(t, err, errs.ErrProtectedPermission)
+		permissionRepo.AssertNotCalled(t, "RevokePermission", ctx, constants.RoleAdmin, constants.PermissionPermissionsManage)
+	})
+}
@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProfileUsecase_UpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerUpdateRepo)
+	waiterRepo := new(mockWaiterUpdateRepo)
+	verifier := new(mockEmailVerifier)
+	usecase := usecase.NewProfileUsecase(NewTestLogger(), customerRepo, waiterRepo, nil, verifier)
+
+	customer := &entities.CustomerEntity{CustomerID: "123", Name: "John", Email: "old@example.com"}
+
+	cleanup := func() {
+		customerRepo.ExpectedCalls = nil
+		customerRepo.Calls = nil
+		verifier.ExpectedCalls = nil
+		verifier.Calls = nil
+	}
+
+	t.Run("customer email change", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		customerRepo.On("GetCustomerByID", ctx, "123").Return(customer, nil)
+		customerRepo.On("CheckEmailExists", ctx, "new@example.com").Return(false, nil)
+		verifier.On("SendVerification", ctx, "123", "new@example.com").Return(nil)
+
+		profile, err := usecase.UpdateProfile(ctx, "123", constants.RoleCustomer, &dto.UpdateProfileDTO{Email: "new@example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "old@example.com", profile.Customer.Email)
+		verifier.AssertExpectations(t)
+		customerRepo.AssertNotCalled(t, "UpdateCustomerName", ctx, "123", mock.Anything)
+	})
+
+	t.Run("email already taken", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		customerRepo.On("GetCustomerByID", ctx, "123").Return(customer, nil)
+		customerRepo.On("CheckEmailExists", ctx, "taken@example.com").Return(true, nil)
+
+		profile, err := usecase.UpdateProfile(ctx, "123", constants.RoleCustomer, &dto.UpdateProfileDTO{Email: "taken@example.com"})
+
+		assert.Nil(t, profile)
+		assert.ErrorIs(t, err, errs.ErrCustomerAlreadyExists)
+		verifier.AssertNotCalled(t, "SendVerification", ctx, "123", "taken@example.com")
+	})
+
+	t.Run("waiter name", func(t *testing.T) {
+		waiter := &entities.WaiterEntity{WaiterID: "456", FirstName: "Jane", LastName: "Doe"}
+		waiterRepo.On("GetWaiterByID", ctx, "456").Return(waiter, nil)
+		waiterRepo.On("UpdateWaiterName", ctx, "456", "Jane", "Smith").Return(nil)
+
+		_, err := usecase.UpdateProfile(ctx, "456", constants.RoleWaiter, &dto.UpdateProfileDTO{LastName: "Smith"})
+
+		assert.NoError(t, err)
+		waiterRepo.AssertExpectations(t)
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/SergeyBogomolovv/restaurant/common/constants"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
 	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
 	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
 	"github.com/google/uuid"
@@ -16,8 +18,10 @@ import (
 func TestRegisterUsecase_RegisterCustomer(t *testing.T) {
 	ctx := context.Background()
 	log := NewTestLogger()
+	identityRepo := new(mockIdentityRepo)
 	customerRepo := new(mockCustomerRegisterRepo)
-	usecase := usecase.NewRegisterUsecase(log, customerRepo, nil, nil, "secretKey")
+	verifier := new(mockEmailVerifier)
+	usecase := usecase.NewRegisterUsecase(log, identityRepo, customerRepo, nil, nil, verifier, nil, permissiveAttempts(ctx), testHasher, testPolicy)
 
 	payload := &dto.RegisterCustomerDTO{
 		Email:     "test@example.com",
@@ -26,58 +30,104 @@ func TestRegisterUsecase_RegisterCustomer(t *testing.T) {
 		Birthdate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
 
+	cleanup := func() {
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		customerRepo.ExpectedCalls = nil
+		customerRepo.Calls = nil
+	}
+
 	t.Run("success", func(t *testing.T) {
-		t.Cleanup(func() {
-			customerRepo.ExpectedCalls = nil
-			customerRepo.Calls = nil
-		})
-		customerRepo.On("CheckEmailExists", ctx, payload.Email).Return(false, nil)
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Email).Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("CreateIdentity", ctx, payload.Email, mock.Anything).Return("id-1", nil)
 		customerId := uuid.New()
-		customerRepo.On("CreateCustomer", ctx, mock.Anything).Return(customerId, nil)
+		customerRepo.On("CreateCustomer", ctx, mock.MatchedBy(func(dto *dto.CreateCustomerDTO) bool {
+			return dto.IdentityID == "id-1" && dto.Email == payload.Email
+		})).Return(customerId, nil)
+		verifier.On("SendVerification", ctx, customerId.String(), payload.Email).Return(nil)
 
 		id, err := usecase.RegisterCustomer(ctx, payload)
 		assert.NoError(t, err)
 		assert.Equal(t, id, customerId)
 
+		identityRepo.AssertExpectations(t)
 		customerRepo.AssertExpectations(t)
+		verifier.AssertExpectations(t)
 	})
 
 	t.Run("email exists", func(t *testing.T) {
-		t.Cleanup(func() {
-			customerRepo.ExpectedCalls = nil
-			customerRepo.Calls = nil
-		})
-		customerRepo.On("CheckEmailExists", ctx, payload.Email).Return(true, nil)
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Email).Return(newIdentity("id-1", payload.Email, "otherpassword"), nil)
 
 		id, err := usecase.RegisterCustomer(ctx, payload)
 		assert.ErrorIs(t, err, errs.ErrCustomerAlreadyExists)
 		assert.Equal(t, id, uuid.Nil)
 
-		customerRepo.AssertExpectations(t)
+		customerRepo.AssertNotCalled(t, "CreateCustomer", ctx, mock.Anything)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Email).Return(nil, errs.ErrIdentityNotFound)
+
+		id, err := usecase.RegisterCustomer(ctx, &dto.RegisterCustomerDTO{Email: payload.Email, Password: "test1234", Name: payload.Name})
+		assert.ErrorIs(t, err, errs.ErrWeakPassword)
+		assert.Equal(t, id, uuid.Nil)
+
+		identityRepo.AssertNotCalled(t, "CreateIdentity", ctx, payload.Email, mock.Anything)
 	})
 
+	t.Run("delete identity on failure", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Email).Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("CreateIdentity", ctx, payload.Email, mock.Anything).Return("id-2", nil)
+		customerRepo.On("CreateCustomer", ctx, mock.Anything).Return(uuid.Nil, assert.AnError)
+		identityRepo.On("DeleteIdentity", ctx, "id-2").Return(nil)
+
+		id, err := usecase.RegisterCustomer(ctx, payload)
+		assert.Error(t, err)
+		assert.Equal(t, id, uuid.Nil)
+		identityRepo.AssertExpectations(t)
+	})
 }
 
+var (
+	hashInvitationCode = usecase.HashInvitationCode
+	invitationCodeHash = hashInvitationCode("invitation-code")
+)
+
 func TestRegisterUsecase_RegisterWaiter(t *testing.T) {
 	ctx := context.Background()
 	log := NewTestLogger()
+	identityRepo := new(mockIdentityRepo)
 	waiterRepo := new(mockWaiterRegisterRepo)
-	usecase := usecase.NewRegisterUsecase(log, nil, waiterRepo, nil, "secretKey")
+	invitationRepo := new(mockInvitationConsumer)
+	usecase := usecase.NewRegisterUsecase(log, identityRepo, nil, waiterRepo, nil, nil, invitationRepo, permissiveAttempts(ctx), testHasher, testPolicy)
 
 	payload := &dto.RegisterWaiterDTO{
-		Login:     "waiter123",
-		Password:  "password123",
-		FirstName: "Jane",
-		LastName:  "Doe",
-		Token:     "secretKey",
+		Login:          "waiter123",
+		Password:       "password123",
+		FirstName:      "Jane",
+		LastName:       "Doe",
+		InvitationCode: "invitation-code",
+	}
+	invitation := &entities.InvitationEntity{InvitationID: "inv-1", Role: constants.RoleWaiter}
+
+	cleanup := func() {
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		waiterRepo.ExpectedCalls = nil
+		waiterRepo.Calls = nil
+		invitationRepo.ExpectedCalls = nil
+		invitationRepo.Calls = nil
 	}
 
 	t.Run("success", func(t *testing.T) {
-		t.Cleanup(func() {
-			waiterRepo.ExpectedCalls = nil
-			waiterRepo.Calls = nil
-		})
-		waiterRepo.On("CheckLoginExists", ctx, payload.Login).Return(false, nil)
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(nil, errs.ErrIdentityNotFound)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(invitation, nil)
+		identityRepo.On("CreateIdentity", ctx, payload.Login, mock.Anything).Return("id-1", nil)
 		waiterId := uuid.New()
 		waiterRepo.On("CreateWaiter", ctx, mock.Anything).Return(waiterId, nil)
 
@@ -86,56 +136,165 @@ func TestRegisterUsecase_RegisterWaiter(t *testing.T) {
 		assert.Equal(t, id, waiterId)
 
 		waiterRepo.AssertExpectations(t)
+		invitationRepo.AssertExpectations(t)
 	})
 
-	t.Run("invalid token", func(t *testing.T) {
-		t.Cleanup(func() {
-			waiterRepo.ExpectedCalls = nil
-			waiterRepo.Calls = nil
+	t.Run("prefilled name", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		firstName, lastName := "John", "Smith"
+		prefilled := &entities.InvitationEntity{InvitationID: "inv-2", Role: constants.RoleWaiter, FirstName: &firstName, LastName: &lastName}
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(nil, errs.ErrIdentityNotFound)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(prefilled, nil)
+		identityRepo.On("CreateIdentity", ctx, payload.Login, mock.Anything).Return("id-1", nil)
+		waiterRepo.On("CreateWaiter", ctx, mock.MatchedBy(func(dto *dto.CreateWaiterDTO) bool {
+			return dto.FirstName == firstName && dto.LastName == lastName
+		})).Return(uuid.New(), nil)
+
+		_, err := usecase.RegisterWaiter(ctx, &dto.RegisterWaiterDTO{
+			Login:          payload.Login,
+			Password:       payload.Password,
+			InvitationCode: payload.InvitationCode,
 		})
-		payload := &dto.RegisterWaiterDTO{
-			Token: "invalidToken",
-		}
+		assert.NoError(t, err)
+		waiterRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid invitation", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(nil, errs.ErrInvalidInvitation)
+
+		id, err := usecase.RegisterWaiter(ctx, payload)
+		assert.ErrorIs(t, err, errs.ErrInvalidInvitation)
+		assert.Equal(t, id, uuid.Nil)
+		identityRepo.AssertNotCalled(t, "GetIdentityByLogin", ctx, payload.Login)
+		identityRepo.AssertNotCalled(t, "CreateIdentity", ctx, payload.Login, mock.Anything)
+		waiterRepo.AssertNotCalled(t, "CreateWaiter", ctx, mock.Anything)
+	})
+
+	t.Run("release invitation on failure", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(nil, errs.ErrIdentityNotFound)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(invitation, nil)
+		identityRepo.On("CreateIdentity", ctx, payload.Login, mock.Anything).Return("id-1", nil)
+		waiterRepo.On("CreateWaiter", ctx, mock.Anything).Return(uuid.Nil, assert.AnError)
+		identityRepo.On("DeleteIdentity", ctx, "id-1").Return(nil)
+		invitationRepo.On("ReleaseInvitation", ctx, invitation.InvitationID).Return(nil)
 
 		id, err := usecase.RegisterWaiter(ctx, payload)
-		assert.ErrorIs(t, err, errs.ErrInvalidSecretToken)
+		assert.Error(t, err)
 		assert.Equal(t, id, uuid.Nil)
+		identityRepo.AssertExpectations(t)
+		invitationRepo.AssertExpectations(t)
 	})
 
 	t.Run("login exists", func(t *testing.T) {
-		t.Cleanup(func() {
-			waiterRepo.ExpectedCalls = nil
-			waiterRepo.Calls = nil
-		})
-		waiterRepo.On("CheckLoginExists", ctx, payload.Login).Return(true, nil)
+		t.Cleanup(cleanup)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(invitation, nil)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(newIdentity("id-1", payload.Login, "otherpassword"), nil)
+		invitationRepo.On("ReleaseInvitation", ctx, invitation.InvitationID).Return(nil)
 
 		id, err := usecase.RegisterWaiter(ctx, payload)
 		assert.ErrorIs(t, err, errs.ErrWaiterAlreadyExists)
 		assert.Equal(t, id, uuid.Nil)
+		invitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("login exists without invitation", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(nil, errs.ErrInvalidInvitation)
+
+		_, err := usecase.RegisterWaiter(ctx, payload)
+		assert.ErrorIs(t, err, errs.ErrInvalidInvitation)
+		identityRepo.AssertNotCalled(t, "GetIdentityByLogin", ctx, payload.Login)
+	})
+
+	t.Run("attach role to existing identity", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identity := newIdentity("id-admin", payload.Login, payload.Password)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(identity, nil)
+		identityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(nil, errs.ErrRoleNotGranted)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(invitation, nil)
+		waiterId := uuid.New()
+		waiterRepo.On("CreateWaiter", ctx, mock.MatchedBy(func(dto *dto.CreateWaiterDTO) bool {
+			return dto.IdentityID == identity.IdentityID
+		})).Return(waiterId, nil)
 
+		id, err := usecase.RegisterWaiter(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, waiterId, id)
+		identityRepo.AssertNotCalled(t, "CreateIdentity", ctx, payload.Login, mock.Anything)
 		waiterRepo.AssertExpectations(t)
 	})
+
+	t.Run("role already granted", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identity := newIdentity("id-waiter", payload.Login, payload.Password)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(identity, nil)
+		identityRepo.On("GetRole", ctx, identity.IdentityID, constants.RoleWaiter).Return(&entities.IdentityRoleEntity{EntityID: "123"}, nil)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(invitation, nil)
+		invitationRepo.On("ReleaseInvitation", ctx, invitation.InvitationID).Return(nil)
+
+		id, err := usecase.RegisterWaiter(ctx, payload)
+		assert.ErrorIs(t, err, errs.ErrWaiterAlreadyExists)
+		assert.Equal(t, id, uuid.Nil)
+		invitationRepo.AssertExpectations(t)
+	})
+}
+
+func TestRegisterUsecase_RegisterWaiterLockout(t *testing.T) {
+	ctx := context.Background()
+	identityRepo := new(mockIdentityRepo)
+	invitationRepo := new(mockInvitationConsumer)
+	attempts := new(mockAttemptsRepo)
+	usecase := usecase.NewRegisterUsecase(NewTestLogger(), identityRepo, nil, nil, nil, nil, invitationRepo, attempts, testHasher, testPolicy)
+
+	invitation := &entities.InvitationEntity{InvitationID: "inv-1", Role: constants.RoleWaiter}
+	invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleWaiter).Return(invitation, nil)
+	identityRepo.On("GetIdentityByLogin", ctx, "waiter123").Return(newIdentity("id-1", "waiter123", "otherpassword"), nil)
+	attempts.On("RegisterAttempt", ctx, constants.RoleWaiter, "waiter123", "10.0.0.1").Return(time.Minute, nil)
+	invitationRepo.On("ReleaseInvitation", ctx, invitation.InvitationID).Return(nil)
+
+	_, err := usecase.RegisterWaiter(ctx, &dto.RegisterWaiterDTO{
+		Login:          "waiter123",
+		Password:       "password123",
+		InvitationCode: "invitation-code",
+		IP:             "10.0.0.1",
+	})
+
+	assert.ErrorIs(t, err, errs.ErrTooManyAttempts)
+	invitationRepo.AssertExpectations(t)
+	attempts.AssertExpectations(t)
 }
 
 func TestRegisterUsecase_RegisterAdmin(t *testing.T) {
 	ctx := context.Background()
 	log := NewTestLogger()
+	identityRepo := new(mockIdentityRepo)
 	adminRepo := new(mockAdminRegisterRepo)
-	usecase := usecase.NewRegisterUsecase(log, nil, nil, adminRepo, "secretKey")
+	invitationRepo := new(mockInvitationConsumer)
+	usecase := usecase.NewRegisterUsecase(log, identityRepo, nil, nil, adminRepo, nil, invitationRepo, permissiveAttempts(ctx), testHasher, testPolicy)
 
 	payload := &dto.RegisterAdminDTO{
-		Login:    "admin123",
-		Password: "password123",
-		Note:     "Super admin",
-		Token:    "secretKey",
+		Login:          "admin123",
+		Password:       "password123",
+		Note:           "Super admin",
+		InvitationCode: "invitation-code",
+	}
+
+	cleanup := func() {
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		adminRepo.ExpectedCalls = nil
+		adminRepo.Calls = nil
+		invitationRepo.ExpectedCalls = nil
+		invitationRepo.Calls = nil
 	}
 
 	t.Run("success", func(t *testing.T) {
-		t.Cleanup(func() {
-			adminRepo.ExpectedCalls = nil
-			adminRepo.Calls = nil
-		})
-		adminRepo.On("CheckLoginExists", ctx, payload.Login).Return(false, nil)
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(nil, errs.ErrIdentityNotFound)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleAdmin).Return(&entities.InvitationEntity{InvitationID: "inv-1"}, nil)
+		identityRepo.On("CreateIdentity", ctx, payload.Login, mock.Anything).Return("id-1", nil)
 		adminId := uuid.New()
 		adminRepo.On("CreateAdmin", ctx, mock.Anything).Return(adminId, nil)
 
@@ -146,32 +305,67 @@ func TestRegisterUsecase_RegisterAdmin(t *testing.T) {
 		adminRepo.AssertExpectations(t)
 	})
 
-	t.Run("invalid token", func(t *testing.T) {
-		t.Cleanup(func() {
-			adminRepo.ExpectedCalls = nil
-			adminRepo.Calls = nil
-		})
-		payload := &dto.RegisterAdminDTO{
-			Token: "invalidToken",
-		}
+	t.Run("invalid invitation", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleAdmin).Return(nil, errs.ErrInvalidInvitation)
 
 		id, err := usecase.RegisterAdmin(ctx, payload)
-		assert.ErrorIs(t, err, errs.ErrInvalidSecretToken)
+		assert.ErrorIs(t, err, errs.ErrInvalidInvitation)
 		assert.Equal(t, id, uuid.Nil)
-		adminRepo.AssertExpectations(t)
+		identityRepo.AssertNotCalled(t, "GetIdentityByLogin", ctx, payload.Login)
+		adminRepo.AssertNotCalled(t, "CreateAdmin", ctx, mock.Anything)
 	})
 
 	t.Run("login exists", func(t *testing.T) {
-		t.Cleanup(func() {
-			adminRepo.ExpectedCalls = nil
-			adminRepo.Calls = nil
-		})
-		adminRepo.On("CheckLoginExists", ctx, payload.Login).Return(true, nil)
+		t.Cleanup(cleanup)
+		invitationRepo.On("ConsumeInvitation", ctx, invitationCodeHash, constants.RoleAdmin).Return(&entities.InvitationEntity{InvitationID: "inv-1"}, nil)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(newIdentity("id-1", payload.Login, "otherpassword"), nil)
+		invitationRepo.On("ReleaseInvitation", ctx, "inv-1").Return(nil)
 
 		id, err := usecase.RegisterAdmin(ctx, payload)
 		assert.ErrorIs(t, err, errs.ErrAdminAlreadyExists)
 		assert.Equal(t, id, uuid.Nil)
+		invitationRepo.AssertExpectations(t)
+	})
+}
 
-		adminRepo.AssertExpectations(t)
+func TestRegisterUsecase_BootstrapAdmin(t *testing.T) {
+	ctx := context.Background()
+	identityRepo := new(mockIdentityRepo)
+	adminRepo := new(mockAdminRegisterRepo)
+	usecase := usecase.NewRegisterUsecase(NewTestLogger(), identityRepo, nil, nil, adminRepo, nil, nil, nil, testHasher, testPolicy)
+
+	payload := &dto.BootstrapAdminDTO{Login: "root", Password: "password123"}
+
+	cleanup := func() {
+		identityRepo.ExpectedCalls = nil
+		identityRepo.Calls = nil
+		adminRepo.ExpectedCalls = nil
+		adminRepo.Calls = nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		adminId := uuid.New()
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("CreateIdentity", ctx, payload.Login, mock.Anything).Return("id-1", nil)
+		adminRepo.On("CreateFirstAdmin", ctx, mock.Anything).Return(adminId, nil)
+
+		id, err := usecase.BootstrapAdmin(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, adminId, id)
+	})
+
+	t.Run("already bootstrapped", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		identityRepo.On("GetIdentityByLogin", ctx, payload.Login).Return(nil, errs.ErrIdentityNotFound)
+		identityRepo.On("CreateIdentity", ctx, payload.Login, mock.Anything).Return("id-1", nil)
+		adminRepo.On("CreateFirstAdmin", ctx, mock.Anything).Return(uuid.Nil, errs.ErrAlreadyBootstrapped)
+		identityRepo.On("DeleteIdentity", ctx, "id-1").Return(nil)
+
+		id, err := usecase.BootstrapAdmin(ctx, payload)
+		assert.ErrorIs(t, err, errs.ErrAlreadyBootstrapped)
+		assert.Equal(t, uuid.Nil, id)
+		identityRepo.AssertCalled(t, "DeleteIdentity", ctx, "id-1")
 	})
 }
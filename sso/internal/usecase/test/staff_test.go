@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/dto"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaffUsecase_FireWaiter(t *testing.T) {
+	ctx := context.Background()
+	waiterRepo := new(mockStaffRepo)
+	tokens := new(mockTokensRevoker)
+	usecase := usecase.NewStaffUsecase(NewTestLogger(), waiterRepo, nil, tokens)
+
+	payload := &dto.FireWaiterDTO{WaiterID: "123", Reason: "no show"}
+
+	cleanup := func() {
+		waiterRepo.ExpectedCalls = nil
+		waiterRepo.Calls = nil
+		tokens.ExpectedCalls = nil
+		tokens.Calls = nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		waiterRepo.On("GetWaiterByID", ctx, "123").Return(&entities.WaiterEntity{WaiterID: "123"}, nil)
+		waiterRepo.On("FireWaiter", ctx, "123", "no show").Return(nil)
+		tokens.On("RevokeAllRefreshTokens", ctx, "123").Return(nil)
+
+		err := usecase.FireWaiter(ctx, payload)
+
+		assert.NoError(t, err)
+		waiterRepo.AssertExpectations(t)
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("already fired", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		firedAt := time.Now()
+		waiterRepo.On("GetWaiterByID", ctx, "123").Return(&entities.WaiterEntity{WaiterID: "123", FiredAt: &firedAt}, nil)
+
+		err := usecase.FireWaiter(ctx, payload)
+
+		assert.ErrorIs(t, err, errs.ErrWaiterFired)
+		waiterRepo.AssertNotCalled(t, "FireWaiter", ctx, "123", "no show")
+	})
+
+	t.Run("fired concurrently", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		waiterRepo.On("GetWaiterByID", ctx, "123").Return(&entities.WaiterEntity{WaiterID: "123"}, nil)
+		waiterRepo.On("FireWaiter", ctx, "123", "no show").Return(errs.ErrWaiterFired)
+
+		err := usecase.FireWaiter(ctx, payload)
+
+		assert.ErrorIs(t, err, errs.ErrWaiterFired)
+		tokens.AssertNotCalled(t, "RevokeAllRefreshTokens", ctx, "123")
+	})
+}
+
+func TestStaffUsecase_RehireWaiter(t *testing.T) {
+	ctx := context.Background()
+	waiterRepo := new(mockStaffRepo)
+	usecase := usecase.NewStaffUsecase(NewTestLogger(), waiterRepo, nil, nil)
+
+	t.Run("success", func(t *testing.T) {
+		firedAt := time.Now()
+		waiterRepo.On("GetWaiterByID", ctx, "123").Return(&entities.WaiterEntity{WaiterID: "123", FiredAt: &firedAt}, nil)
+		waiterRepo.On("RehireWaiter", ctx, "123").Return(nil)
+
+		err := usecase.RehireWaiter(ctx, "123")
+
+		assert.NoError(t, err)
+		waiterRepo.AssertExpectations(t)
+	})
+
+	t.Run("not fired", func(t *testing.T) {
+		waiterRepo.On("GetWaiterByID", ctx, "456").Return(&entities.WaiterEntity{WaiterID: "456"}, nil)
+
+		err := usecase.RehireWaiter(ctx, "456")
+
+		assert.ErrorIs(t, err, errs.ErrWaiterNotFired)
+	})
+}
+
+func TestStaffUsecase_ListWaiters(t *testing.T) {
+	ctx := context.Background()
+	waiterRepo := new(mockStaffRepo)
+	usecase := usecase.NewStaffUsecase(NewTestLogger(), waiterRepo, nil, nil)
+
+	t.Run("default pagination", func(t *testing.T) {
+		waiters := []*entities.WaiterEntity{{WaiterID: "123"}}
+		waiterRepo.On("ListWaiters", ctx, &dto.WaiterFilterDTO{Status: "active", SortBy: "rating", Desc: true, Limit: 20, Offset: 0}).Return(waiters, 1, nil)
+
+		page, err := usecase.ListWaiters(ctx, &dto.ListWaitersDTO{Status: "active", SortBy: "rating", Desc: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, page.Total)
+		assert.Equal(t, waiters, page.Waiters)
+	})
+
+	t.Run("page offset", func(t *testing.T) {
+		waiterRepo.On("ListWaiters", ctx, &dto.WaiterFilterDTO{Limit: 10, Offset: 20}).Return([]*entities.WaiterEntity{}, 25, nil)
+
+		page, err := usecase.ListWaiters(ctx, &dto.ListWaitersDTO{Page: 3, PageSize: 10})
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Waiters)
+		waiterRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,137 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVerificationUsecase_SendVerification(t *testing.T) {
+	ctx := context.Background()
+	verificationRepo := new(mockVerificationRepo)
+	mailer := new(mockMailer)
+	usecase := usecase.NewVerificationUsecase(NewTestLogger(), nil, verificationRepo, mailer, "http://localhost/verify")
+
+	verificationRepo.On("GenerateVerificationToken", ctx, "123", "test@example.com").Return("token", nil)
+	mailer.On("Send", ctx, "test@example.com", mock.Anything, mock.MatchedBy(func(body string) bool {
+		return assert.Contains(t, body, "http://localhost/verify?token=token")
+	})).Return(nil)
+
+	err := usecase.SendVerification(ctx, "123", "test@example.com")
+
+	assert.NoError(t, err)
+	verificationRepo.AssertExpectations(t)
+	mailer.AssertExpectations(t)
+}
+
+func TestVerificationUsecase_SendVerificationKeepsQuery(t *testing.T) {
+	ctx := context.Background()
+	verificationRepo := new(mockVerificationRepo)
+	mailer := new(mockMailer)
+	usecase := usecase.NewVerificationUsecase(NewTestLogger(), nil, verificationRepo, mailer, "http://localhost/verify?lang=en")
+
+	verificationRepo.On("GenerateVerificationToken", ctx, "123", "test@example.com").Return("a+b/c", nil)
+	mailer.On("Send", ctx, "test@example.com", mock.Anything, mock.MatchedBy(func(body string) bool {
+		return assert.Contains(t, body, "http://localhost/verify?lang=en&token=a%2Bb%2Fc")
+	})).Return(nil)
+
+	err := usecase.SendVerification(ctx, "123", "test@example.com")
+
+	assert.NoError(t, err)
+	mailer.AssertExpectations(t)
+}
+
+func TestVerificationUsecase_VerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerVerificationRepo)
+	verificationRepo := new(mockVerificationRepo)
+	usecase := usecase.NewVerificationUsecase(NewTestLogger(), customerRepo, verificationRepo, nil, "http://localhost/verify")
+
+	t.Run("success", func(t *testing.T) {
+		verification := &entities.EmailVerificationEntity{CustomerID: "123", Email: "test@example.com"}
+		verificationRepo.On("ConsumeVerificationToken", ctx, "valid-token").Return(verification, nil)
+		customerRepo.On("VerifyEmail", ctx, "123", "test@example.com").Return(nil)
+
+		err := usecase.VerifyEmail(ctx, "valid-token")
+
+		assert.NoError(t, err)
+		customerRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		verificationRepo.On("ConsumeVerificationToken", ctx, "invalid-token").Return(nil, errs.ErrInvalidVerifyToken)
+
+		err := usecase.VerifyEmail(ctx, "invalid-token")
+
+		assert.ErrorIs(t, err, errs.ErrInvalidVerifyToken)
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		verification := &entities.EmailVerificationEntity{CustomerID: "123", Email: "taken@example.com"}
+		verificationRepo.On("ConsumeVerificationToken", ctx, "taken-token").Return(verification, nil)
+		customerRepo.On("VerifyEmail", ctx, "123", "taken@example.com").Return(errs.ErrCustomerAlreadyExists)
+
+		err := usecase.VerifyEmail(ctx, "taken-token")
+
+		assert.ErrorIs(t, err, errs.ErrCustomerAlreadyExists)
+	})
+}
+
+func TestVerificationUsecase_ResendVerification(t *testing.T) {
+	ctx := context.Background()
+	customerRepo := new(mockCustomerVerificationRepo)
+	verificationRepo := new(mockVerificationRepo)
+	mailer := new(mockMailer)
+	usecase := usecase.NewVerificationUsecase(NewTestLogger(), customerRepo, verificationRepo, mailer, "http://localhost/verify")
+
+	t.Run("unknown email", func(t *testing.T) {
+		customerRepo.On("GetCustomerByEmail", ctx, "unknown@example.com").Return(nil, errs.ErrCustomerNotFound)
+
+		err := usecase.ResendVerification(ctx, "unknown@example.com")
+
+		assert.NoError(t, err)
+		mailer.AssertNotCalled(t, "Send", mock.Anything, "unknown@example.com", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already verified", func(t *testing.T) {
+		verifiedAt := time.Now()
+		customer := &entities.CustomerEntity{CustomerID: "123", Email: "verified@example.com", EmailVerifiedAt: &verifiedAt}
+		customerRepo.On("GetCustomerByEmail", ctx, "verified@example.com").Return(customer, nil)
+
+		err := usecase.ResendVerification(ctx, "verified@example.com")
+
+		assert.NoError(t, err)
+		verificationRepo.AssertNotCalled(t, "GenerateVerificationToken", ctx, "123", "verified@example.com")
+	})
+
+	t.Run("not verified", func(t *testing.T) {
+		customer := &entities.CustomerEntity{CustomerID: "456", Email: "pending@example.com"}
+		customerRepo.On("GetCustomerByEmail", ctx, "pending@example.com").Return(customer, nil)
+		verificationRepo.On("StartResendCooldown", ctx, "456").Return(true, nil)
+		verificationRepo.On("GenerateVerificationToken", ctx, "456", "pending@example.com").Return("token", nil)
+		mailer.On("Send", ctx, "pending@example.com", mock.Anything, mock.Anything).Return(nil)
+
+		err := usecase.ResendVerification(ctx, "pending@example.com")
+
+		assert.NoError(t, err)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("resent recently", func(t *testing.T) {
+		customer := &entities.CustomerEntity{CustomerID: "789", Email: "again@example.com"}
+		customerRepo.On("GetCustomerByEmail", ctx, "again@example.com").Return(customer, nil)
+		verificationRepo.On("StartResendCooldown", ctx, "789").Return(false, nil)
+
+		err := usecase.ResendVerification(ctx, "again@example.com")
+
+		assert.NoError(t, err)
+		verificationRepo.AssertNotCalled(t, "GenerateVerificationToken", ctx, "789", "again@example.com")
+		mailer.AssertNotCalled(t, "Send", ctx, "again@example.com", mock.Anything, mock.Anything)
+	})
+}
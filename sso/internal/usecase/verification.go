@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/SergeyBogomolovv/restaurant/sso/internal/domain/entities"
+	errs "github.com/SergeyBogomolovv/restaurant/sso/internal/domain/errors"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type VerificationRepo interface {
+	GenerateVerificationToken(ctx context.Context, customerID string, email string) (string, error)
+	ConsumeVerificationToken(ctx context.Context, token string) (*entities.EmailVerificationEntity, error)
+	StartResendCooldown(ctx context.Context, customerID string) (bool, error)
+}
+
+type CustomerVerificationRepo interface {
+	GetCustomerByEmail(ctx context.Context, email string) (*entities.CustomerEntity, error)
+	VerifyEmail(ctx context.Context, customerID string, email string) error
+}
+
+type verificationUsecase struct {
+	customers     CustomerVerificationRepo
+	verifications VerificationRepo
+	mailer        Mailer
+	verifyURL     string
+	log           *slog.Logger
+}
+
+func NewVerificationUsecase(
+	log *slog.Logger,
+	customers CustomerVerificationRepo,
+	verifications VerificationRepo,
+	mailer Mailer,
+	verifyURL string,
+) *verificationUsecase {
+	return &verificationUsecase{
+		customers:     customers,
+		verifications: verifications,
+		mailer:        mailer,
+		verifyURL:     verifyURL,
+		log:           log,
+	}
+}
+
+func (u *verificationUsecase) SendVerification(ctx context.Context, customerID string, email string) error {
+	const op = "verification.Send"
+	log := u.log.With(slog.String("op", op), slog.String("customerId", customerID))
+
+	token, err := u.verifications.GenerateVerificationToken(ctx, customerID, email)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to generate verification token", "error", err)
+		return err
+	}
+
+	link, err := tokenLink(u.verifyURL, token)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to build verification link", "error", err)
+		return err
+	}
+
+	body := fmt.Sprintf("Confirm your email by following the link: %s", link)
+	if err := u.mailer.Send(ctx, email, "Confirm your email", body); err != nil {
+		log.ErrorContext(ctx, "failed to send verification email", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "verification email sent")
+	return nil
+}
+
+func (u *verificationUsecase) VerifyEmail(ctx context.Context, token string) error {
+	const op = "verification.Verify"
+	log := u.log.With(slog.String("op", op))
+
+	verification, err := u.verifications.ConsumeVerificationToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidVerifyToken) {
+			log.InfoContext(ctx, "invalid verification token")
+			return errs.ErrInvalidVerifyToken
+		}
+		log.ErrorContext(ctx, "failed to consume verification token", "error", err)
+		return err
+	}
+	log = log.With(slog.String("customerId", verification.CustomerID))
+
+	if err := u.customers.VerifyEmail(ctx, verification.CustomerID, verification.Email); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCustomerNotFound):
+			log.InfoContext(ctx, "customer not found")
+			return errs.ErrInvalidVerifyToken
+		case errors.Is(err, errs.ErrCustomerAlreadyExists):
+			log.InfoContext(ctx, "email already taken")
+			return errs.ErrCustomerAlreadyExists
+		}
+		log.ErrorContext(ctx, "failed to verify email", "error", err)
+		return err
+	}
+
+	log.InfoContext(ctx, "email verified")
+	return nil
+}
+
+func (u *verificationUsecase) ResendVerification(ctx context.Context, email string) error {
+	const op = "verification.Resend"
+	log := u.log.With(slog.String("op", op), slog.String("email", email))
+
+	customer, err := u.customers.GetCustomerByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, errs.ErrCustomerNotFound) {
+			log.InfoContext(ctx, "customer not found")
+			return nil
+		}
+		log.ErrorContext(ctx, "failed to get customer by email", "error", err)
+		return err
+	}
+
+	if customer.EmailVerifiedAt != nil {
+		log.InfoContext(ctx, "email already verified")
+		return nil
+	}
+
+	started, err := u.verifications.StartResendCooldown(ctx, customer.CustomerID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to start resend cooldown", "error", err)
+		return err
+	}
+	if !started {
+		log.InfoContext(ctx, "verification resent recently")
+		return nil
+	}
+
+	return u.SendVerification(ctx, customer.CustomerID, customer.Email)
+}
+
+// tokenLink adds the token to the query of base, keeping any query it has.
+func tokenLink(base string, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
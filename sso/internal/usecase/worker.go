@@ -0,0 +1,52 @@
+package usecase
+
+import "sync"
+
+// worker runs background jobs on a fixed number of goroutines. Jobs that do
+// not fit in the queue are refused rather than piling up, and close waits for
+// the queued ones so they never outlive the connections they use.
+type worker struct {
+	mu     sync.RWMutex
+	closed bool
+	jobs   chan func()
+	wg     sync.WaitGroup
+}
+
+func newWorker(goroutines int, queue int) *worker {
+	w := &worker{jobs: make(chan func(), queue)}
+	w.wg.Add(goroutines)
+	for range goroutines {
+		go func() {
+			defer w.wg.Done()
+			for job := range w.jobs {
+				job()
+			}
+		}()
+	}
+	return w
+}
+
+// submit reports false when the queue is full or the worker is closed.
+func (w *worker) submit(job func()) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *worker) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.jobs)
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+}
@@ -1,6 +1,19 @@
 package payload
 
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
 type JwtPayload struct {
-	EntityID string
-	Role     string
+	EntityID    string
+	Role        string
+	Permissions []string
+	ExpiresAt   time.Time
+}
+
+type AccessClaims struct {
+	Permissions []string `json:"perms,omitempty"`
+	jwt.RegisteredClaims
 }
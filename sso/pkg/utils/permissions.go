@@ -0,0 +1,14 @@
+package utils
+
+import "slices"
+
+// HasPermissions reports whether granted, as carried by a verified access token
+// or an introspection response, contains every required permission.
+func HasPermissions(granted []string, required ...string) bool {
+	for _, permission := range required {
+		if !slices.Contains(granted, permission) {
+			return false
+		}
+	}
+	return true
+}
@@ -6,7 +6,8 @@ import (
 )
 
 func VerifyToken(token string, secret []byte) (*payload.JwtPayload, error) {
-	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+	claims := new(payload.AccessClaims)
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
@@ -28,5 +29,10 @@ func VerifyToken(token string, secret []byte) (*payload.JwtPayload, error) {
 	if err != nil {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
-	return &payload.JwtPayload{EntityID: id, Role: aud[0]}, nil
+
+	exp, err := parsed.Claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return &payload.JwtPayload{EntityID: id, Role: aud[0], Permissions: claims.Permissions, ExpiresAt: exp.Time}, nil
 }